@@ -15,6 +15,7 @@ import (
 	"github.com/username/taxfolio/backend/src/handlers"
 	"github.com/username/taxfolio/backend/src/logger"
 	_ "github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/prices"
 	"github.com/username/taxfolio/backend/src/processors"
 	"github.com/username/taxfolio/backend/src/security"
 	"github.com/username/taxfolio/backend/src/services"
@@ -68,6 +69,26 @@ func enableCORS(next http.Handler) http.Handler {
 	})
 }
 
+// runExchangeRateRefreshJob periodically reloads the historical rate file and, when it changed,
+// recomputes EUR amounts for transactions that were stored with a fallback rate.
+func runExchangeRateRefreshJob(uploadService services.UploadService, ratesPath string, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for range ticker.C {
+		reloaded, err := processors.ReloadHistoricalRatesIfChanged(ratesPath)
+		if err != nil {
+			logger.L.Error("Exchange rate refresh failed", "path", ratesPath, "error", err)
+			continue
+		}
+		if !reloaded {
+			continue
+		}
+		if _, err := uploadService.ReenrichFallbackRates(); err != nil {
+			logger.L.Error("Failed to re-enrich fallback exchange rates after refresh", "error", err)
+		}
+	}
+}
+
 func main() {
 	config.LoadConfig()
 	logger.InitLogger(config.Cfg.LogLevel)
@@ -90,6 +111,9 @@ func main() {
 	if err := utils.InitCountryData(config.Cfg.CountryDataPath); err != nil {
 		logger.L.Error("Failed to load country data", "error", err)
 	}
+	if err := processors.LoadInstrumentClasses(config.Cfg.InstrumentClassesPath); err != nil {
+		logger.L.Error("Failed to load instrument classes", "error", err)
+	}
 
 	logger.L.Info("Initializing database...", "path", config.Cfg.DatabasePath)
 	database.InitDB(config.Cfg.DatabasePath)
@@ -113,6 +137,14 @@ func main() {
 	stockProcessor := processors.NewStockProcessor()
 	optionProcessor := processors.NewOptionProcessor()
 	cashMovementProcessor := processors.NewCashMovementProcessor()
+	cashLedgerProcessor := processors.NewCashLedgerProcessor()
+	fxProcessor := processors.NewFXProcessor()
+	cryptoProcessor := processors.NewCryptoProcessor()
+	bondProcessor := processors.NewBondProcessor()
+	interestProcessor := processors.NewInterestProcessor()
+	performanceProcessor := processors.NewPerformanceProcessor()
+	costProcessor := processors.NewCostProcessor()
+	taxLossProcessor := processors.NewTaxLossProcessor()
 
 	// Inject the new transactionProcessor into the service
 	uploadService := services.NewUploadService(
@@ -121,14 +153,53 @@ func main() {
 		stockProcessor,
 		optionProcessor,
 		cashMovementProcessor,
+		fxProcessor,
+		cryptoProcessor,
+		bondProcessor,
+		interestProcessor,
+		cashLedgerProcessor,
 		reportCache,
 	)
 	// --- END OF UPDATED INSTANTIATIONS ---
 
-	uploadHandler := handlers.NewUploadHandler(uploadService)
+	// Transactions stored with a fallback rate are recomputed whenever rate data becomes available.
+	if updated, err := uploadService.ReenrichFallbackRates(); err != nil {
+		logger.L.Error("Failed to re-enrich fallback exchange rates at startup", "error", err)
+	} else if updated > 0 {
+		logger.L.Info("Re-enriched fallback exchange rates at startup", "updated", updated)
+	}
+	// Transactions stored before instrument classification existed are classified once, so every reader sees the class.
+	if classified, err := uploadService.BackfillInstrumentClasses(); err != nil {
+		logger.L.Error("Failed to backfill instrument classes at startup", "error", err)
+	} else if classified > 0 {
+		logger.L.Info("Backfilled instrument classes at startup", "classified", classified)
+	}
+	if config.Cfg.RateRefreshPeriod > 0 {
+		go runExchangeRateRefreshJob(uploadService, config.Cfg.HistoricalDataPath, config.Cfg.RateRefreshPeriod)
+	}
+
+	reconciliationService := services.NewReconciliationService(stockProcessor, optionProcessor)
+	transactionService := services.NewTransactionService(transactionProcessor, uploadService)
+	parserProfileService := services.NewParserProfileService()
+	priceService := services.NewPriceService(uploadService, prices.NewLocalProvider(config.Cfg.PricesPath))
+	performanceService := services.NewPerformanceService(performanceProcessor)
+	allocationService := services.NewAllocationService(uploadService, priceService)
+	costService := services.NewCostService(costProcessor, performanceService)
+	taxLossService := services.NewTaxLossService(taxLossProcessor, stockProcessor, priceService)
+	simulationService := services.NewSimulationService(transactionProcessor, stockProcessor)
+
+	uploadHandler := handlers.NewUploadHandler(uploadService, parserProfileService)
 	portfolioHandler := handlers.NewPortfolioHandler(uploadService)
 	dividendHandler := handlers.NewDividendHandler(uploadService)
-	txHandler := handlers.NewTransactionHandler(uploadService)
+	txHandler := handlers.NewTransactionHandler(uploadService, transactionService)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+	parserProfileHandler := handlers.NewParserProfileHandler(parserProfileService)
+	priceHandler := handlers.NewPriceHandler(priceService)
+	performanceHandler := handlers.NewPerformanceHandler(performanceService)
+	allocationHandler := handlers.NewAllocationHandler(allocationService)
+	costHandler := handlers.NewCostHandler(costService)
+	taxLossHandler := handlers.NewTaxLossHandler(taxLossService)
+	simulationHandler := handlers.NewSimulationHandler(simulationService)
 
 	// ... (Routing and server start logic remains the same) ...
 	logger.L.Info("Configuring routes...")
@@ -163,11 +234,39 @@ func main() {
 	apiRouter.Handle("GET /api/transactions/processed", applyCsrfAndAuth(txHandler.HandleGetProcessedTransactions))
 	apiRouter.Handle("GET /api/holdings/stocks", applyCsrfAndAuth(portfolioHandler.HandleGetStockHoldings))
 	apiRouter.Handle("GET /api/holdings/options", applyCsrfAndAuth(portfolioHandler.HandleGetOptionHoldings))
+	apiRouter.Handle("GET /api/holdings/valuation", applyCsrfAndAuth(priceHandler.HandleGetHoldingsValuation))
+	apiRouter.Handle("GET /api/allocation", applyCsrfAndAuth(allocationHandler.HandleGetAllocation))
+	apiRouter.Handle("POST /api/prices/import", applyCsrfAndAuth(priceHandler.HandleImportPrices))
+	apiRouter.Handle("POST /api/prices/sync", applyCsrfAndAuth(priceHandler.HandleSyncPrices))
 	apiRouter.Handle("GET /api/stock-sales", applyCsrfAndAuth(portfolioHandler.HandleGetStockSales))
+	apiRouter.Handle("GET /api/stock-sales/by-class", applyCsrfAndAuth(portfolioHandler.HandleGetStockSalesByClass))
 	apiRouter.Handle("GET /api/option-sales", applyCsrfAndAuth(portfolioHandler.HandleGetOptionSales))
+	apiRouter.Handle("GET /api/stock-matching-errors", applyCsrfAndAuth(portfolioHandler.HandleGetLotMatchingErrors))
+	apiRouter.Handle("GET /api/fx-gains", applyCsrfAndAuth(portfolioHandler.HandleGetFXGains))
+	apiRouter.Handle("GET /api/crypto-gains", applyCsrfAndAuth(portfolioHandler.HandleGetCryptoGains))
+	apiRouter.Handle("GET /api/bonds", applyCsrfAndAuth(portfolioHandler.HandleGetBondReport))
+	apiRouter.Handle("GET /api/cash-ledger", applyCsrfAndAuth(portfolioHandler.HandleGetCashLedger))
+	apiRouter.Handle("GET /api/bond-income", applyCsrfAndAuth(portfolioHandler.HandleGetBondIncome))
+	apiRouter.Handle("GET /api/performance", applyCsrfAndAuth(performanceHandler.HandleGetPerformance))
+	apiRouter.Handle("GET /api/performance/benchmark", applyCsrfAndAuth(performanceHandler.HandleGetBenchmarkComparison))
+	apiRouter.Handle("GET /api/costs", applyCsrfAndAuth(costHandler.HandleGetCostReport))
+	apiRouter.Handle("GET /api/tax-loss-harvesting", applyCsrfAndAuth(taxLossHandler.HandleGetHarvestReport))
+	apiRouter.Handle("POST /api/simulate/sale", applyCsrfAndAuth(simulationHandler.HandleSimulateSale))
 	apiRouter.Handle("GET /api/dividend-tax-summary", applyCsrfAndAuth(dividendHandler.HandleGetDividendTaxSummary))
+	apiRouter.Handle("GET /api/interest-tax-summary", applyCsrfAndAuth(dividendHandler.HandleGetInterestTaxSummary))
 	apiRouter.Handle("GET /api/dividend-transactions", applyCsrfAndAuth(dividendHandler.HandleGetDividendTransactions))
+	apiRouter.Handle("GET /api/dividend-analytics", applyCsrfAndAuth(dividendHandler.HandleGetDividendAnalytics))
+	apiRouter.Handle("GET /api/transactions/fallback-rates", applyCsrfAndAuth(txHandler.HandleGetFallbackRateTransactions))
+	apiRouter.Handle("POST /api/reconciliation/positions", applyCsrfAndAuth(reconciliationHandler.HandleUploadPositions))
+	apiRouter.Handle("GET /api/reconciliation", applyCsrfAndAuth(reconciliationHandler.HandleGetReconciliation))
+	apiRouter.Handle("POST /api/transactions", applyCsrfAndAuth(txHandler.HandleCreateManualTransaction))
+	apiRouter.Handle("PUT /api/transactions/{id}", applyCsrfAndAuth(txHandler.HandleUpdateManualTransaction))
+	apiRouter.Handle("DELETE /api/transactions/{id}", applyCsrfAndAuth(txHandler.HandleDeleteTransaction))
 	apiRouter.Handle("DELETE /api/transactions/all", applyCsrfAndAuth(txHandler.HandleDeleteAllProcessedTransactions))
+	apiRouter.Handle("GET /api/parser-profiles", applyCsrfAndAuth(parserProfileHandler.HandleListProfiles))
+	apiRouter.Handle("POST /api/parser-profiles", applyCsrfAndAuth(parserProfileHandler.HandleCreateProfile))
+	apiRouter.Handle("PUT /api/parser-profiles/{id}", applyCsrfAndAuth(parserProfileHandler.HandleUpdateProfile))
+	apiRouter.Handle("DELETE /api/parser-profiles/{id}", applyCsrfAndAuth(parserProfileHandler.HandleDeleteProfile))
 
 	// User specific protected endpoints
 	apiRouter.Handle("GET /api/user/has-data", applyCsrfAndAuth(userHandler.HandleCheckUserData))
@@ -10,16 +10,19 @@ import (
 )
 
 type AppConfig struct {
-	JWTSecret          string
-	Port               string
-	DatabasePath       string
-	LogLevel           string
-	CSRFAuthKey        []byte
-	HistoricalDataPath string
-	CountryDataPath    string
-	AccessTokenExpiry  time.Duration
-	RefreshTokenExpiry time.Duration
-	MaxUploadSizeBytes int64
+	JWTSecret             string
+	Port                  string
+	DatabasePath          string
+	LogLevel              string
+	CSRFAuthKey           []byte
+	HistoricalDataPath    string
+	CountryDataPath       string
+	InstrumentClassesPath string
+	PricesPath            string        // Directory of "<ISIN>.csv" closing price files read by the local price provider
+	RateRefreshPeriod     time.Duration // How often the rate file is checked for changes; 0 disables the job
+	AccessTokenExpiry     time.Duration
+	RefreshTokenExpiry    time.Duration
+	MaxUploadSizeBytes    int64
 
 	EmailServiceProvider string
 
@@ -103,16 +106,19 @@ func LoadConfig() {
 	}
 
 	Cfg = &AppConfig{
-		JWTSecret:          jwtSecret,
-		Port:               getEnv("PORT", "8080"),
-		DatabasePath:       getEnv("DATABASE_PATH", "./taxfolio.db"),
-		LogLevel:           getEnv("LOG_LEVEL", "info"),
-		CSRFAuthKey:        []byte(csrfAuthKeyStr),
-		HistoricalDataPath: getEnv("HISTORICAL_DATA_PATH", "data/historicalExchangeRate.json"),
-		CountryDataPath:    getEnv("COUNTRY_DATA_PATH", "data/country.json"),
-		AccessTokenExpiry:  accessTokenExpiry,
-		RefreshTokenExpiry: refreshTokenExpiry,
-		MaxUploadSizeBytes: maxUploadSizeBytes,
+		JWTSecret:             jwtSecret,
+		Port:                  getEnv("PORT", "8080"),
+		DatabasePath:          getEnv("DATABASE_PATH", "./taxfolio.db"),
+		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		CSRFAuthKey:           []byte(csrfAuthKeyStr),
+		HistoricalDataPath:    getEnv("HISTORICAL_DATA_PATH", "data/historicalExchangeRate.json"),
+		CountryDataPath:       getEnv("COUNTRY_DATA_PATH", "data/country.json"),
+		InstrumentClassesPath: getEnv("INSTRUMENT_CLASSES_PATH", "data/instrumentClasses.json"),
+		PricesPath:            getEnv("PRICES_PATH", "data/prices"),
+		RateRefreshPeriod:     getEnvAsDuration("RATE_REFRESH_PERIOD", 1*time.Hour),
+		AccessTokenExpiry:     accessTokenExpiry,
+		RefreshTokenExpiry:    refreshTokenExpiry,
+		MaxUploadSizeBytes:    maxUploadSizeBytes,
 
 		EmailServiceProvider: getEnv("EMAIL_SERVICE_PROVIDER", "mailgun"),
 
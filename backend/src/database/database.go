@@ -2,7 +2,9 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	stdlog "log"
+	"strings"
 
 	"github.com/username/taxfolio/backend/src/logger"
 	_ "modernc.org/sqlite"
@@ -25,6 +27,7 @@ func InitDB(databasePath string) {
 	}
 	migrateUserTable() // Migration for users table
 	migrateDatabase()  // Existing migration for processed_transactions
+	migrateBrokerPositionsTable()
 
 	createTableStatement := `
 	CREATE TABLE IF NOT EXISTS users (
@@ -61,8 +64,8 @@ func InitDB(databasePath string) {
 		source TEXT NOT NULL,
 		product_name TEXT NOT NULL,
 		isin TEXT,
-		quantity INTEGER,
-		original_quantity INTEGER,
+		quantity REAL,
+		original_quantity REAL,
 		price REAL,
 		transaction_type TEXT,
 		transaction_subtype TEXT,
@@ -71,15 +74,67 @@ func InitDB(databasePath string) {
 		amount REAL,
 		currency TEXT,
 		commission REAL,
+		commission_currency TEXT DEFAULT '',
 		order_id TEXT,
 		exchange_rate REAL,
 		amount_eur REAL,
+		exchange_rate_fallback BOOLEAN DEFAULT FALSE,
 		country_code TEXT,
+		instrument_class TEXT,
+		underlying TEXT DEFAULT '',
+		strike REAL DEFAULT 0,
+		expiry TEXT DEFAULT '',
+		option_right TEXT DEFAULT '',
+		multiplier REAL DEFAULT 0,
 		input_string TEXT,
 		hash_id TEXT,
 		FOREIGN KEY(user_id) REFERENCES users(id),
 		UNIQUE(user_id, hash_id)
 	);
+
+	CREATE TABLE IF NOT EXISTS broker_positions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		report_date TEXT,
+		asset_type TEXT NOT NULL,
+		isin TEXT,
+		symbol TEXT,
+		product_name TEXT,
+		quantity REAL,
+		cost_basis REAL,
+		currency TEXT,
+		underlying TEXT DEFAULT '',
+		strike REAL DEFAULT 0,
+		expiry TEXT DEFAULT '',
+		option_right TEXT DEFAULT '',
+		multiplier REAL DEFAULT 0,
+		uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS parser_profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		definition TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(user_id) REFERENCES users(id),
+		UNIQUE(user_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS price_history (
+		user_id INTEGER NOT NULL,
+		isin TEXT NOT NULL,
+		price_date TEXT NOT NULL, -- YYYY-MM-DD, so dates compare as text
+		close REAL NOT NULL,
+		currency TEXT NOT NULL,
+		source TEXT,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(user_id, isin, price_date, currency),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
 	`
 
 	_, err = DB.Exec(createTableStatement)
@@ -256,6 +311,7 @@ func migrateDatabase() {
 	defer rows.Close()
 
 	columnExists := make(map[string]bool)
+	columnTypes := make(map[string]string)
 
 	for rows.Next() {
 		var cid, pk int
@@ -273,6 +329,7 @@ func migrateDatabase() {
 			return
 		}
 		columnExists[name] = true
+		columnTypes[name] = strings.ToUpper(dataType)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -285,7 +342,7 @@ func migrateDatabase() {
 	}
 
 	if _, ok := columnExists["original_quantity"]; !ok {
-		_, err := DB.Exec("ALTER TABLE processed_transactions ADD COLUMN original_quantity INTEGER")
+		_, err := DB.Exec("ALTER TABLE processed_transactions ADD COLUMN original_quantity REAL")
 		if err != nil {
 			if logger.L != nil {
 				logger.L.Error("Error adding original_quantity column", "error", err)
@@ -309,6 +366,27 @@ func migrateDatabase() {
 		}
 	}
 
+	// Quantities were INTEGER before fractional shares were supported. SQLite cannot change a column's type, so
+	// each one is copied into a new REAL column that then takes its name.
+	for _, column := range []string{"quantity", "original_quantity"} {
+		if columnTypes[column] != "INTEGER" {
+			continue
+		}
+		if err := migrateColumnToReal("processed_transactions", column); err != nil {
+			if logger.L != nil {
+				logger.L.Error("Error converting column to REAL", "column", column, "error", err)
+			} else {
+				stdlog.Printf("Error converting %s column to REAL: %v", column, err)
+			}
+		} else {
+			if logger.L != nil {
+				logger.L.Info("Converted processed_transactions column to REAL", "column", column)
+			} else {
+				stdlog.Printf("Converted %s column of processed_transactions to REAL", column)
+			}
+		}
+	}
+
 	if _, ok := columnExists["description"]; !ok {
 		_, err := DB.Exec("ALTER TABLE processed_transactions ADD COLUMN description TEXT")
 		if err != nil {
@@ -325,4 +403,184 @@ func migrateDatabase() {
 			}
 		}
 	}
+
+	if _, ok := columnExists["exchange_rate_fallback"]; !ok {
+		_, err := DB.Exec("ALTER TABLE processed_transactions ADD COLUMN exchange_rate_fallback BOOLEAN DEFAULT FALSE")
+		if err != nil {
+			if logger.L != nil {
+				logger.L.Error("Error adding exchange_rate_fallback column", "error", err)
+			} else {
+				stdlog.Printf("Error adding exchange_rate_fallback column: %v", err)
+			}
+		} else {
+			if logger.L != nil {
+				logger.L.Info("Added exchange_rate_fallback column to processed_transactions table")
+			} else {
+				stdlog.Println("Added exchange_rate_fallback column to processed_transactions table")
+			}
+			// Rows stored before this column existed do not say whether their rate came from the fallback path.
+			// This is a heuristic: a non-EUR row with a rate of exactly 1.0 is assumed to be a fallback. No currency
+			// in the rate data is pegged to EUR at parity, but a daily rate can be exactly 1.0 (USD on 31 August
+			// 2022), so some genuine rows are flagged too. Re-enrichment recomputes them from the same rate data,
+			// which leaves their amounts unchanged; only rows whose currency has no rate at all stay flagged.
+			result, errUpdate := DB.Exec("UPDATE processed_transactions SET exchange_rate_fallback = TRUE WHERE exchange_rate = 1.0 AND currency != 'EUR' AND currency != ''")
+			if errUpdate != nil {
+				if logger.L != nil {
+					logger.L.Error("Error flagging existing fallback exchange rates", "error", errUpdate)
+				} else {
+					stdlog.Printf("Error flagging existing fallback exchange rates: %v", errUpdate)
+				}
+			} else if flagged, _ := result.RowsAffected(); flagged > 0 {
+				if logger.L != nil {
+					logger.L.Info("Flagged existing non-EUR transactions with a 1.0 exchange rate for re-enrichment", "rows", flagged)
+				} else {
+					stdlog.Printf("Flagged %d existing non-EUR transactions with a 1.0 exchange rate for re-enrichment", flagged)
+				}
+			}
+		}
+	}
+
+	if _, ok := columnExists["instrument_class"]; !ok {
+		_, err := DB.Exec("ALTER TABLE processed_transactions ADD COLUMN instrument_class TEXT")
+		if err != nil {
+			if logger.L != nil {
+				logger.L.Error("Error adding instrument_class column", "error", err)
+			} else {
+				stdlog.Printf("Error adding instrument_class column: %v", err)
+			}
+		} else {
+			if logger.L != nil {
+				logger.L.Info("Added instrument_class column to processed_transactions table")
+			} else {
+				stdlog.Println("Added instrument_class column to processed_transactions table")
+			}
+		}
+	}
+
+	if _, ok := columnExists["commission_currency"]; !ok {
+		// Older rows keep an empty value, which means the commission is in the trade currency.
+		_, err := DB.Exec("ALTER TABLE processed_transactions ADD COLUMN commission_currency TEXT DEFAULT ''")
+		if err != nil {
+			if logger.L != nil {
+				logger.L.Error("Error adding commission_currency column", "error", err)
+			} else {
+				stdlog.Printf("Error adding commission_currency column: %v", err)
+			}
+		} else {
+			if logger.L != nil {
+				logger.L.Info("Added commission_currency column to processed_transactions table")
+			} else {
+				stdlog.Println("Added commission_currency column to processed_transactions table")
+			}
+		}
+	}
+
+	// Option contract identity. Defaults keep older rows scannable; the option processor falls back to their product name.
+	optionColumns := []struct{ name, definition string }{
+		{"underlying", "TEXT DEFAULT ''"},
+		{"strike", "REAL DEFAULT 0"},
+		{"expiry", "TEXT DEFAULT ''"},
+		{"option_right", "TEXT DEFAULT ''"},
+		{"multiplier", "REAL DEFAULT 0"},
+	}
+	for _, column := range optionColumns {
+		if _, ok := columnExists[column.name]; ok {
+			continue
+		}
+		_, err := DB.Exec("ALTER TABLE processed_transactions ADD COLUMN " + column.name + " " + column.definition)
+		if err != nil {
+			if logger.L != nil {
+				logger.L.Error("Error adding option column", "column", column.name, "error", err)
+			} else {
+				stdlog.Printf("Error adding %s column: %v", column.name, err)
+			}
+		} else {
+			if logger.L != nil {
+				logger.L.Info("Added option column to processed_transactions table", "column", column.name)
+			} else {
+				stdlog.Printf("Added %s column to processed_transactions table", column.name)
+			}
+		}
+	}
+}
+
+// migrateBrokerPositionsTable adds the option contract identity to snapshots stored before it was parsed.
+// Older option rows keep empty values and are matched by their product name.
+func migrateBrokerPositionsTable() {
+	rows, err := DB.Query("PRAGMA table_info(broker_positions)")
+	if err != nil {
+		if logger.L != nil {
+			logger.L.Error("Error querying table schema for broker_positions", "error", err)
+		} else {
+			stdlog.Printf("Error querying table schema for broker_positions: %v", err)
+		}
+		return
+	}
+	columnExists := make(map[string]bool)
+	for rows.Next() {
+		var cid, notnull, pk int
+		var name, dataType string
+		var dfltValue sql.NullString
+		if err := rows.Scan(&cid, &name, &dataType, &notnull, &dfltValue, &pk); err != nil {
+			rows.Close()
+			return
+		}
+		columnExists[name] = true
+	}
+	rows.Close()
+	// A missing table has no columns and is created with them.
+	if len(columnExists) == 0 {
+		return
+	}
+
+	optionColumns := []struct{ name, definition string }{
+		{"underlying", "TEXT DEFAULT ''"},
+		{"strike", "REAL DEFAULT 0"},
+		{"expiry", "TEXT DEFAULT ''"},
+		{"option_right", "TEXT DEFAULT ''"},
+		{"multiplier", "REAL DEFAULT 0"},
+	}
+	for _, column := range optionColumns {
+		if columnExists[column.name] {
+			continue
+		}
+		_, err := DB.Exec("ALTER TABLE broker_positions ADD COLUMN " + column.name + " " + column.definition)
+		if err != nil {
+			if logger.L != nil {
+				logger.L.Error("Error adding option column to broker_positions", "column", column.name, "error", err)
+			} else {
+				stdlog.Printf("Error adding %s column to broker_positions: %v", column.name, err)
+			}
+		} else {
+			if logger.L != nil {
+				logger.L.Info("Added option column to broker_positions table", "column", column.name)
+			} else {
+				stdlog.Printf("Added %s column to broker_positions table", column.name)
+			}
+		}
+	}
+}
+
+// migrateColumnToReal replaces an INTEGER column with a REAL one holding the same values, in one transaction.
+// The column moves to the end of the table, which is harmless since every query names its columns.
+func migrateColumnToReal(table, column string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tmpColumn := column + "_real"
+	statements := []string{
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s REAL", table, tmpColumn),
+		fmt.Sprintf("UPDATE %s SET %s = %s", table, tmpColumn, column),
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column),
+		fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, tmpColumn, column),
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestInitDBMigratesIntegerQuantitiesToReal(t *testing.T) {
+	path := t.TempDir() + "/taxfolio.db"
+	legacy, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening legacy database: %v", err)
+	}
+	_, err = legacy.Exec(`
+		CREATE TABLE processed_transactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			date TEXT NOT NULL,
+			source TEXT NOT NULL,
+			product_name TEXT NOT NULL,
+			isin TEXT,
+			quantity INTEGER,
+			original_quantity INTEGER,
+			price REAL,
+			hash_id TEXT,
+			UNIQUE(user_id, hash_id)
+		);
+		INSERT INTO processed_transactions (user_id, date, source, product_name, quantity, original_quantity, hash_id)
+		VALUES (1, '01-02-2024', 'degiro', 'ACME', 10, 10, 'a'), (1, '02-02-2024', 'revolut', 'ACME', 0.5, 0.5, 'b');`)
+	if err != nil {
+		t.Fatalf("creating legacy schema: %v", err)
+	}
+	legacy.Close()
+
+	InitDB(path)
+	t.Cleanup(func() { DB.Close() })
+
+	rows, err := DB.Query("SELECT name, type FROM pragma_table_info('processed_transactions')")
+	if err != nil {
+		t.Fatalf("reading schema: %v", err)
+	}
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			t.Fatalf("scanning schema: %v", err)
+		}
+		types[name] = dataType
+	}
+	rows.Close()
+
+	tests := []struct {
+		hash         string
+		wantQuantity float64
+	}{
+		{"a", 10},
+		{"b", 0.5},
+	}
+	for _, column := range []string{"quantity", "original_quantity"} {
+		if types[column] != "REAL" {
+			t.Errorf("%s type = %q, want REAL", column, types[column])
+		}
+	}
+	for _, tt := range tests {
+		var quantity, originalQuantity float64
+		if err := DB.QueryRow("SELECT quantity, original_quantity FROM processed_transactions WHERE hash_id = ?", tt.hash).Scan(&quantity, &originalQuantity); err != nil {
+			t.Fatalf("reading row %s: %v", tt.hash, err)
+		}
+		if quantity != tt.wantQuantity || originalQuantity != tt.wantQuantity {
+			t.Errorf("row %s: quantity %v original %v, want %v", tt.hash, quantity, originalQuantity, tt.wantQuantity)
+		}
+	}
+}
+
+func TestInitDBFlagsLegacyFallbackRates(t *testing.T) {
+	path := t.TempDir() + "/taxfolio.db"
+	legacy, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening legacy database: %v", err)
+	}
+	_, err = legacy.Exec(`
+		CREATE TABLE processed_transactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			date TEXT NOT NULL,
+			source TEXT NOT NULL,
+			product_name TEXT NOT NULL,
+			quantity REAL,
+			original_quantity REAL,
+			amount REAL,
+			currency TEXT,
+			exchange_rate REAL,
+			amount_eur REAL,
+			hash_id TEXT,
+			UNIQUE(user_id, hash_id)
+		);
+		INSERT INTO processed_transactions (user_id, date, source, product_name, amount, currency, exchange_rate, amount_eur, hash_id)
+		VALUES (1, '01-02-2024', 'ibkr', 'ACME', 100, 'USD', 1.0, 100, 'usd-fallback'),
+		       (1, '01-02-2024', 'ibkr', 'ACME', 108, 'USD', 1.08, 100, 'usd-rate'),
+		       (1, '01-02-2024', 'degiro', 'ACME', 100, 'EUR', 1.0, 100, 'eur'),
+		       (1, '01-02-2024', 'degiro', 'ACME', 0, '', 1.0, 0, 'no-currency');`)
+	if err != nil {
+		t.Fatalf("creating legacy schema: %v", err)
+	}
+	legacy.Close()
+
+	InitDB(path)
+	t.Cleanup(func() { DB.Close() })
+
+	tests := []struct {
+		hash        string
+		wantFlagged bool
+	}{
+		{"usd-fallback", true},
+		{"usd-rate", false},
+		{"eur", false},
+		{"no-currency", false},
+	}
+	for _, tt := range tests {
+		var flagged bool
+		if err := DB.QueryRow("SELECT exchange_rate_fallback FROM processed_transactions WHERE hash_id = ?", tt.hash).Scan(&flagged); err != nil {
+			t.Fatalf("reading row %s: %v", tt.hash, err)
+		}
+		if flagged != tt.wantFlagged {
+			t.Errorf("row %s: exchange_rate_fallback = %v, want %v", tt.hash, flagged, tt.wantFlagged)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type AllocationHandler struct {
+	allocationService services.AllocationService
+}
+
+func NewAllocationHandler(service services.AllocationService) *AllocationHandler {
+	return &AllocationHandler{
+		allocationService: service,
+	}
+}
+
+// HandleGetAllocation returns the open positions grouped by country, currency, asset class and broker.
+func (h *AllocationHandler) HandleGetAllocation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	logger.L.Info("Handling GetAllocation", "userID", userID)
+	report, err := h.allocationService.GetAllocation(userID)
+	if err != nil {
+		logger.L.Error("Error computing allocation", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error computing allocation for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.L.Error("Error encoding allocation report to JSON", "userID", userID, "error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type CostHandler struct {
+	costService services.CostService
+}
+
+func NewCostHandler(service services.CostService) *CostHandler {
+	return &CostHandler{
+		costService: service,
+	}
+}
+
+// HandleGetCostReport returns the fees and commissions paid per year, broker and currency.
+func (h *CostHandler) HandleGetCostReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	logger.L.Info("Handling GetCostReport", "userID", userID)
+	report, err := h.costService.GetCostReport(userID)
+	if err != nil {
+		logger.L.Error("Error computing cost report", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error computing cost report for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.L.Error("Error encoding cost report to JSON", "userID", userID, "error", err)
+	}
+}
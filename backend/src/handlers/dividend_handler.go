@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/username/taxfolio/backend/src/logger" // Using slog
 	"github.com/username/taxfolio/backend/src/models"
@@ -44,6 +45,28 @@ func (h *DividendHandler) HandleGetDividendTaxSummary(w http.ResponseWriter, r *
 	}
 }
 
+func (h *DividendHandler) HandleGetInterestTaxSummary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	logger.L.Info("Handling GetInterestTaxSummary", "userID", userID)
+	interestSummary, err := h.uploadService.GetInterestTaxSummary(userID)
+	if err != nil {
+		logger.L.Error("Error retrieving interest tax summary", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving interest tax summary for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	if interestSummary == nil {
+		interestSummary = make(models.InterestTaxResult)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(interestSummary); err != nil {
+		logger.L.Error("Error encoding interest tax summary to JSON", "userID", userID, "error", err)
+	}
+}
+
 func (h *DividendHandler) HandleGetDividendTransactions(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context()) // Assumes GetUserIDFromContext is available
 	if !ok {
@@ -65,3 +88,33 @@ func (h *DividendHandler) HandleGetDividendTransactions(w http.ResponseWriter, r
 		logger.L.Error("Error encoding dividend transactions to JSON", "userID", userID, "error", err)
 	}
 }
+
+// HandleGetDividendAnalytics returns per-ISIN dividend analytics and the projected income calendar. The optional
+// "date" query parameter (YYYY-MM-DD) sets the end of the trailing twelve months and defaults to today.
+func (h *DividendHandler) HandleGetDividendAnalytics(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	date, err := parseDateQueryParam(r, "date")
+	if err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	logger.L.Info("Handling GetDividendAnalytics", "userID", userID, "date", date.Format("2006-01-02"))
+	analytics, err := h.uploadService.GetDividendAnalytics(userID, date)
+	if err != nil {
+		logger.L.Error("Error computing dividend analytics", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error computing dividend analytics for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(analytics); err != nil {
+		logger.L.Error("Error encoding dividend analytics to JSON", "userID", userID, "error", err)
+	}
+}
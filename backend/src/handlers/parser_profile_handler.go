@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/security/validation"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type ParserProfileHandler struct {
+	profileService services.ParserProfileService
+}
+
+func NewParserProfileHandler(service services.ParserProfileService) *ParserProfileHandler {
+	return &ParserProfileHandler{
+		profileService: service,
+	}
+}
+
+func (h *ParserProfileHandler) HandleListProfiles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	profiles, err := h.profileService.ListProfiles(userID)
+	if err != nil {
+		h.sendProfileServiceError(w, userID, err)
+		return
+	}
+	if profiles == nil {
+		profiles = []models.ParserProfile{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(profiles); err != nil {
+		logger.L.Error("Error encoding parser profiles to JSON", "userID", userID, "error", err)
+	}
+}
+
+func (h *ParserProfileHandler) HandleCreateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	var input models.ParserProfile
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.SendJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	profile, err := h.profileService.CreateProfile(userID, input)
+	if err != nil {
+		h.sendProfileServiceError(w, userID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(profile); err != nil {
+		logger.L.Error("Error encoding created parser profile to JSON", "userID", userID, "error", err)
+	}
+}
+
+func (h *ParserProfileHandler) HandleUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	profileID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || profileID <= 0 {
+		utils.SendJSONError(w, "invalid profile id", http.StatusBadRequest)
+		return
+	}
+	var input models.ParserProfile
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.SendJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	profile, err := h.profileService.UpdateProfile(userID, profileID, input)
+	if err != nil {
+		h.sendProfileServiceError(w, userID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(profile); err != nil {
+		logger.L.Error("Error encoding updated parser profile to JSON", "userID", userID, "error", err)
+	}
+}
+
+func (h *ParserProfileHandler) HandleDeleteProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	profileID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || profileID <= 0 {
+		utils.SendJSONError(w, "invalid profile id", http.StatusBadRequest)
+		return
+	}
+	if err := h.profileService.DeleteProfile(userID, profileID); err != nil {
+		h.sendProfileServiceError(w, userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendProfileServiceError maps ParserProfileService errors to HTTP status codes.
+func (h *ParserProfileHandler) sendProfileServiceError(w http.ResponseWriter, userID int64, err error) {
+	switch {
+	case errors.Is(err, validation.ErrValidationFailed):
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrProfileNotFound):
+		utils.SendJSONError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrDuplicateProfile), errors.Is(err, services.ErrTooManyProfiles):
+		utils.SendJSONError(w, err.Error(), http.StatusConflict)
+	default:
+		logger.L.Error("Parser profile service error", "userID", userID, "error", err)
+		utils.SendJSONError(w, "failed to process parser profile", http.StatusInternalServerError)
+	}
+}
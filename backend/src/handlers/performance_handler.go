@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/security/validation"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type PerformanceHandler struct {
+	performanceService services.PerformanceService
+}
+
+func NewPerformanceHandler(service services.PerformanceService) *PerformanceHandler {
+	return &PerformanceHandler{
+		performanceService: service,
+	}
+}
+
+// HandleGetPerformance returns the portfolio returns between the optional "from" and "to" query parameters
+// (YYYY-MM-DD), which default to the first transaction and today.
+func (h *PerformanceHandler) HandleGetPerformance(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	from, to, err := parsePeriodQueryParams(r)
+	if err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.L.Info("Handling GetPerformance", "userID", userID, "from", r.URL.Query().Get("from"), "to", r.URL.Query().Get("to"))
+	report, err := h.performanceService.GetPerformance(userID, from, to)
+	if err != nil {
+		logger.L.Error("Error computing performance", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error computing performance for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.L.Error("Error encoding performance report to JSON", "userID", userID, "error", err)
+	}
+}
+
+// HandleGetBenchmarkComparison compares the portfolio with the benchmark given by the "isin" query parameter, whose
+// closing prices must have been imported. "from" and "to" default as in HandleGetPerformance.
+func (h *PerformanceHandler) HandleGetBenchmarkComparison(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	isin := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("isin")))
+	if isin == "" {
+		utils.SendJSONError(w, "the 'isin' query parameter is required", http.StatusBadRequest)
+		return
+	}
+	if err := validation.ValidateISIN(isin); err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, to, err := parsePeriodQueryParams(r)
+	if err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.L.Info("Handling GetBenchmarkComparison", "userID", userID, "isin", isin, "from", r.URL.Query().Get("from"), "to", r.URL.Query().Get("to"))
+	comparison, err := h.performanceService.GetBenchmarkComparison(userID, isin, from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrBenchmarkPricesMissing) {
+			utils.SendJSONError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		logger.L.Error("Error comparing with benchmark", "userID", userID, "isin", isin, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error comparing with benchmark for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(comparison); err != nil {
+		logger.L.Error("Error encoding benchmark comparison to JSON", "userID", userID, "error", err)
+	}
+}
+
+// parsePeriodQueryParams reads the optional "from" and "to" query parameters, rejecting a "to" before "from".
+func parsePeriodQueryParams(r *http.Request) (time.Time, time.Time, error) {
+	from, err := parseDateQueryParam(r, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseDateQueryParam(r, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("'to' must not be before 'from'")
+	}
+	return from, to, nil
+}
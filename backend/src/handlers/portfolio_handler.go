@@ -40,6 +40,22 @@ func (h *PortfolioHandler) HandleGetStockSales(w http.ResponseWriter, r *http.Re
 	json.NewEncoder(w).Encode(stockSales)
 }
 
+func (h *PortfolioHandler) HandleGetStockSalesByClass(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Handling GetStockSalesByClass for userID: %d", userID)
+	summary, err := h.uploadService.GetStockSalesByClass(userID)
+	if err != nil {
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving stock sales by class for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
 func (h *PortfolioHandler) HandleGetOptionSales(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context()) // Assumes GetUserIDFromContext is available
 	if !ok {
@@ -66,8 +82,18 @@ func (h *PortfolioHandler) HandleGetStockHoldings(w http.ResponseWriter, r *http
 		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized) // Use utils.SendJSONError
 		return
 	}
-	log.Printf("Handling GetStockHoldings for userID: %d", userID)
-	stockHoldings, err := h.uploadService.GetStockHoldings(userID)
+	asOf, err := parseDateQueryParam(r, "as_of")
+	if err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Handling GetStockHoldings for userID: %d (as_of: %s)", userID, r.URL.Query().Get("as_of"))
+	var stockHoldings []models.PurchaseLot
+	if asOf.IsZero() {
+		stockHoldings, err = h.uploadService.GetStockHoldings(userID)
+	} else {
+		stockHoldings, err = h.uploadService.GetStockHoldingsAt(userID, asOf)
+	}
 	if err != nil {
 		utils.SendJSONError(w, fmt.Sprintf("Error retrieving stock holdings for userID %d: %v", userID, err), http.StatusInternalServerError) // Use utils.SendJSONError
 		return
@@ -85,8 +111,18 @@ func (h *PortfolioHandler) HandleGetOptionHoldings(w http.ResponseWriter, r *htt
 		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized) // Use utils.SendJSONError
 		return
 	}
-	log.Printf("Handling GetOptionHoldings for userID: %d", userID)
-	optionHoldings, err := h.uploadService.GetOptionHoldings(userID)
+	asOf, err := parseDateQueryParam(r, "as_of")
+	if err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Handling GetOptionHoldings for userID: %d (as_of: %s)", userID, r.URL.Query().Get("as_of"))
+	var optionHoldings []models.OptionHolding
+	if asOf.IsZero() {
+		optionHoldings, err = h.uploadService.GetOptionHoldings(userID)
+	} else {
+		optionHoldings, err = h.uploadService.GetOptionHoldingsAt(userID, asOf)
+	}
 	if err != nil {
 		utils.SendJSONError(w, fmt.Sprintf("Error retrieving option holdings for userID %d: %v", userID, err), http.StatusInternalServerError) // Use utils.SendJSONError
 		return
@@ -97,3 +133,103 @@ func (h *PortfolioHandler) HandleGetOptionHoldings(w http.ResponseWriter, r *htt
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(optionHoldings)
 }
+
+func (h *PortfolioHandler) HandleGetFXGains(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Handling GetFXGains for userID: %d", userID)
+	report, err := h.uploadService.GetFXGainReport(userID)
+	if err != nil {
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving FX gains for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *PortfolioHandler) HandleGetCryptoGains(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Handling GetCryptoGains for userID: %d", userID)
+	report, err := h.uploadService.GetCryptoGainReport(userID)
+	if err != nil {
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving crypto gains for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *PortfolioHandler) HandleGetBondReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Handling GetBondReport for userID: %d", userID)
+	report, err := h.uploadService.GetBondReport(userID)
+	if err != nil {
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving bond report for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleGetCashLedger returns the per-transaction cash balance history of each broker account and currency.
+func (h *PortfolioHandler) HandleGetCashLedger(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Handling GetCashLedger for userID: %d", userID)
+	report, err := h.uploadService.GetCashLedger(userID)
+	if err != nil {
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving cash ledger for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *PortfolioHandler) HandleGetBondIncome(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Handling GetBondIncome for userID: %d", userID)
+	summary, err := h.uploadService.GetBondIncomeSummary(userID)
+	if err != nil {
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving bond income for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func (h *PortfolioHandler) HandleGetLotMatchingErrors(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Handling GetLotMatchingErrors for userID: %d", userID)
+	matchingErrors, err := h.uploadService.GetLotMatchingErrors(userID)
+	if err != nil {
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving lot matching errors for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	if matchingErrors == nil {
+		matchingErrors = []models.LotMatchingError{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matchingErrors)
+}
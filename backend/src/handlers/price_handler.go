@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/config"
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/security/validation"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type PriceHandler struct {
+	priceService services.PriceService
+}
+
+func NewPriceHandler(service services.PriceService) *PriceHandler {
+	return &PriceHandler{
+		priceService: service,
+	}
+}
+
+// HandleImportPrices stores a CSV of closing prices (date,close[,isin][,currency]). The "isin" and "currency"
+// form fields apply to files without those columns.
+func (h *PriceHandler) HandleImportPrices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseMultipartForm(config.Cfg.MaxUploadSizeBytes); err != nil {
+		logger.L.Warn("Failed to parse multipart form or request too large", "userID", userID, "error", err, "limit", config.Cfg.MaxUploadSizeBytes)
+		utils.SendJSONError(w, fmt.Sprintf("Failed to parse form or request too large (max %d MB)", config.Cfg.MaxUploadSizeBytes/(1024*1024)), http.StatusBadRequest)
+		return
+	}
+
+	isin := strings.ToUpper(strings.TrimSpace(r.FormValue("isin")))
+	if isin != "" {
+		if err := validation.ValidateISIN(isin); err != nil {
+			utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	currency := strings.ToUpper(strings.TrimSpace(r.FormValue("currency")))
+	if currency != "" {
+		if err := validation.ValidateCurrencyCode(currency); err != nil {
+			utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		logger.L.Warn("Failed to retrieve price file from request", "userID", userID, "error", err)
+		utils.SendJSONError(w, "Failed to retrieve file from request. Ensure 'file' field is used.", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if err := validation.ValidateClientContentType(fileHeader.Header.Get("Content-Type")); err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := validation.ValidateFileContentByMagicBytes(file); err != nil {
+		logger.L.Warn("Server-side price file content validation failed", "userID", userID, "filename", fileHeader.Filename, "error", err)
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.priceService.ImportPrices(file, userID, isin, currency)
+	if err != nil {
+		if errors.Is(err, services.ErrParsingFailed) {
+			utils.SendJSONError(w, fmt.Sprintf("Error parsing price file: %v", err), http.StatusBadRequest)
+		} else {
+			logger.L.Error("Internal error storing prices", "userID", userID, "error", err)
+			utils.SendJSONError(w, "An internal error occurred while processing the file. Please try again later.", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"prices": count})
+}
+
+// HandleSyncPrices fetches prices for the user's traded ISINs from the configured price provider.
+func (h *PriceHandler) HandleSyncPrices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	count, err := h.priceService.SyncPrices(userID)
+	if err != nil {
+		logger.L.Error("Error synchronising prices", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error synchronising prices for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"prices": count})
+}
+
+// HandleGetHoldingsValuation values the open stock positions; the optional "date" query parameter
+// (YYYY-MM-DD) selects the prices used and defaults to today.
+func (h *PriceHandler) HandleGetHoldingsValuation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	date, err := parseDateQueryParam(r, "date")
+	if err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	logger.L.Info("Handling GetHoldingsValuation", "userID", userID, "date", date.Format("2006-01-02"))
+	valuation, err := h.priceService.GetHoldingsValuation(userID, date)
+	if err != nil {
+		logger.L.Error("Error valuing holdings", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error valuing holdings for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(valuation); err != nil {
+		logger.L.Error("Error encoding holdings valuation to JSON", "userID", userID, "error", err)
+	}
+}
+
+// parseDateQueryParam reads an optional YYYY-MM-DD query parameter, returning the zero time when it is absent.
+func parseDateQueryParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s '%s', expected YYYY-MM-DD", name, value)
+	}
+	return date, nil
+}
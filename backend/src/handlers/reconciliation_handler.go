@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/username/taxfolio/backend/src/config"
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/security/validation"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService services.ReconciliationService
+}
+
+func NewReconciliationHandler(service services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: service,
+	}
+}
+
+// HandleUploadPositions stores a broker open-positions export (IBKR Flex XML with OpenPositions, DeGiro Portfolio.csv).
+func (h *ReconciliationHandler) HandleUploadPositions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseMultipartForm(config.Cfg.MaxUploadSizeBytes); err != nil {
+		logger.L.Warn("Failed to parse multipart form or request too large", "userID", userID, "error", err, "limit", config.Cfg.MaxUploadSizeBytes)
+		utils.SendJSONError(w, fmt.Sprintf("Failed to parse form or request too large (max %d MB)", config.Cfg.MaxUploadSizeBytes/(1024*1024)), http.StatusBadRequest)
+		return
+	}
+
+	source := r.FormValue("source")
+	if source == "" {
+		utils.SendJSONError(w, "Broker source is required.", http.StatusBadRequest)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		logger.L.Warn("Failed to retrieve positions file from request", "userID", userID, "error", err)
+		utils.SendJSONError(w, "Failed to retrieve file from request. Ensure 'file' field is used.", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if err := validation.ValidateClientContentType(fileHeader.Header.Get("Content-Type")); err != nil {
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := validation.ValidateFileContentByMagicBytes(file); err != nil {
+		logger.L.Warn("Server-side positions file content validation failed", "userID", userID, "filename", fileHeader.Filename, "error", err)
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.reconciliationService.StorePositions(file, userID, source)
+	if err != nil {
+		if errors.Is(err, services.ErrParsingFailed) {
+			utils.SendJSONError(w, fmt.Sprintf("Error parsing %s positions file: %v", source, err), http.StatusBadRequest)
+		} else {
+			logger.L.Error("Internal error storing positions", "userID", userID, "source", source, "error", err)
+			utils.SendJSONError(w, "An internal error occurred while processing the file. Please try again later.", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"source": source, "positions": count})
+}
+
+func (h *ReconciliationHandler) HandleGetReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	logger.L.Info("Handling GetReconciliation", "userID", userID)
+	report, err := h.reconciliationService.Reconcile(userID)
+	if err != nil {
+		logger.L.Error("Error reconciling holdings", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error reconciling holdings for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.L.Error("Error encoding reconciliation report to JSON", "userID", userID, "error", err)
+	}
+}
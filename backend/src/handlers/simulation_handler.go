@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/security/validation"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type SimulationHandler struct {
+	simulationService services.SimulationService
+}
+
+func NewSimulationHandler(service services.SimulationService) *SimulationHandler {
+	return &SimulationHandler{
+		simulationService: service,
+	}
+}
+
+// HandleSimulateSale returns the lots, gain and estimated tax of a hypothetical stock sale.
+func (h *SimulationHandler) HandleSimulateSale(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	logger.L.Info("Handling SimulateSale", "userID", userID)
+
+	var input services.SaleSimulationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.SendJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	simulation, err := h.simulationService.SimulateSale(userID, input)
+	if err != nil {
+		if errors.Is(err, validation.ErrValidationFailed) {
+			utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			logger.L.Error("Error simulating sale", "userID", userID, "error", err)
+			utils.SendJSONError(w, "failed to simulate sale", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(simulation); err != nil {
+		logger.L.Error("Error encoding sale simulation to JSON", "userID", userID, "error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/services"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type TaxLossHandler struct {
+	taxLossService services.TaxLossService
+}
+
+func NewTaxLossHandler(service services.TaxLossService) *TaxLossHandler {
+	return &TaxLossHandler{
+		taxLossService: service,
+	}
+}
+
+// HandleGetHarvestReport returns the open stock lots at a loss and the tax effect of selling them this year.
+func (h *TaxLossHandler) HandleGetHarvestReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	logger.L.Info("Handling GetHarvestReport", "userID", userID)
+	report, err := h.taxLossService.GetHarvestReport(userID)
+	if err != nil {
+		logger.L.Error("Error computing tax-loss harvesting report", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error computing tax-loss harvesting report for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.L.Error("Error encoding tax-loss harvesting report to JSON", "userID", userID, "error", err)
+	}
+}
@@ -1,25 +1,31 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/username/taxfolio/backend/src/database"
 	"github.com/username/taxfolio/backend/src/logger"
 	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/security/validation"
 	"github.com/username/taxfolio/backend/src/services"
 	"github.com/username/taxfolio/backend/src/utils"
 )
 
 type TransactionHandler struct {
-	uploadService services.UploadService
+	uploadService      services.UploadService
+	transactionService services.TransactionService
 }
 
-func NewTransactionHandler(uploadService services.UploadService) *TransactionHandler {
+func NewTransactionHandler(uploadService services.UploadService, transactionService services.TransactionService) *TransactionHandler {
 	return &TransactionHandler{
-		uploadService: uploadService,
+		uploadService:      uploadService,
+		transactionService: transactionService,
 	}
 }
 
@@ -33,8 +39,9 @@ func (h *TransactionHandler) HandleGetProcessedTransactions(w http.ResponseWrite
 
 	rows, err := database.DB.Query(`
 		SELECT id, date, source, product_name, isin, quantity, original_quantity, price, 
-		       transaction_type, transaction_subtype, buy_sell, description, amount, currency, commission, 
-		       order_id, exchange_rate, amount_eur, country_code, input_string, hash_id
+		       transaction_type, transaction_subtype, buy_sell, description, amount, currency, commission, commission_currency,
+		       order_id, exchange_rate, amount_eur, exchange_rate_fallback, country_code, instrument_class,
+		       underlying, strike, expiry, option_right, multiplier, input_string, hash_id
 		FROM processed_transactions
 		WHERE user_id = ?
 		ORDER BY date DESC, id DESC`, userID)
@@ -48,14 +55,17 @@ func (h *TransactionHandler) HandleGetProcessedTransactions(w http.ResponseWrite
 	var processedTransactions []models.ProcessedTransaction
 	for rows.Next() {
 		var tx models.ProcessedTransaction
+		var instrumentClass sql.NullString
 		scanErr := rows.Scan(
 			&tx.ID, &tx.Date, &tx.Source, &tx.ProductName, &tx.ISIN, &tx.Quantity, &tx.OriginalQuantity, &tx.Price,
 			&tx.TransactionType, &tx.TransactionSubType, &tx.BuySell, &tx.Description, &tx.Amount, &tx.Currency,
-			&tx.Commission, &tx.OrderID, &tx.ExchangeRate, &tx.AmountEUR, &tx.CountryCode, &tx.InputString, &tx.HashId)
+			&tx.Commission, &tx.CommissionCurrency, &tx.OrderID, &tx.ExchangeRate, &tx.AmountEUR, &tx.RateFallback, &tx.CountryCode, &instrumentClass,
+			&tx.Underlying, &tx.Strike, &tx.Expiry, &tx.OptionRight, &tx.Multiplier, &tx.InputString, &tx.HashId)
 		if scanErr != nil {
 			utils.SendJSONError(w, fmt.Sprintf("Error scanning transaction for userID %d: %v", userID, scanErr), http.StatusInternalServerError)
 			return
 		}
+		tx.InstrumentClass = instrumentClass.String
 		processedTransactions = append(processedTransactions, tx)
 	}
 	if err = rows.Err(); err != nil {
@@ -71,6 +81,28 @@ func (h *TransactionHandler) HandleGetProcessedTransactions(w http.ResponseWrite
 	}
 }
 
+func (h *TransactionHandler) HandleGetFallbackRateTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	logger.L.Info("Handling GetFallbackRateTransactions", "userID", userID)
+	fallbackTxns, err := h.uploadService.GetFallbackRateTransactions(userID)
+	if err != nil {
+		logger.L.Error("Error retrieving fallback-rate transactions", "userID", userID, "error", err)
+		utils.SendJSONError(w, fmt.Sprintf("Error retrieving fallback-rate transactions for userID %d: %v", userID, err), http.StatusInternalServerError)
+		return
+	}
+	if fallbackTxns == nil {
+		fallbackTxns = []models.ProcessedTransaction{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(fallbackTxns); err != nil {
+		logger.L.Error("Error encoding fallback-rate transactions to JSON", "userID", userID, "error", err)
+	}
+}
+
 func (h *TransactionHandler) HandleDeleteAllProcessedTransactions(w http.ResponseWriter, r *http.Request) {
 	userID, ok := GetUserIDFromContext(r.Context())
 	if !ok {
@@ -98,3 +130,96 @@ func (h *TransactionHandler) HandleDeleteAllProcessedTransactions(w http.Respons
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *TransactionHandler) HandleCreateManualTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	logger.L.Info("Handling CreateManualTransaction", "userID", userID)
+
+	var input services.ManualTransactionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.SendJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.transactionService.CreateManualTransaction(userID, input)
+	if err != nil {
+		h.sendTransactionServiceError(w, userID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(tx); err != nil {
+		logger.L.Error("Error encoding created transaction to JSON", "userID", userID, "error", err)
+	}
+}
+
+func (h *TransactionHandler) HandleUpdateManualTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || transactionID <= 0 {
+		utils.SendJSONError(w, "invalid transaction id", http.StatusBadRequest)
+		return
+	}
+	logger.L.Info("Handling UpdateManualTransaction", "userID", userID, "transactionID", transactionID)
+
+	var input services.ManualTransactionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		utils.SendJSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.transactionService.UpdateManualTransaction(userID, transactionID, input)
+	if err != nil {
+		h.sendTransactionServiceError(w, userID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tx); err != nil {
+		logger.L.Error("Error encoding updated transaction to JSON", "userID", userID, "error", err)
+	}
+}
+
+func (h *TransactionHandler) HandleDeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.SendJSONError(w, "authentication required or user ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || transactionID <= 0 {
+		utils.SendJSONError(w, "invalid transaction id", http.StatusBadRequest)
+		return
+	}
+	logger.L.Info("Handling DeleteTransaction", "userID", userID, "transactionID", transactionID)
+
+	if err := h.transactionService.DeleteTransaction(userID, transactionID); err != nil {
+		h.sendTransactionServiceError(w, userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendTransactionServiceError maps TransactionService errors to HTTP status codes.
+func (h *TransactionHandler) sendTransactionServiceError(w http.ResponseWriter, userID int64, err error) {
+	switch {
+	case errors.Is(err, validation.ErrValidationFailed):
+		utils.SendJSONError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTransactionNotFound):
+		utils.SendJSONError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrNotManualTransaction):
+		utils.SendJSONError(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrDuplicateTransaction):
+		utils.SendJSONError(w, err.Error(), http.StatusConflict)
+	default:
+		logger.L.Error("Transaction service error", "userID", userID, "error", err)
+		utils.SendJSONError(w, "failed to process transaction", http.StatusInternalServerError)
+	}
+}
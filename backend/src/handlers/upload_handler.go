@@ -6,23 +6,27 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/username/taxfolio/backend/src/config"
 	"github.com/username/taxfolio/backend/src/logger"
 	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers"
 	"github.com/username/taxfolio/backend/src/security/validation"
 	"github.com/username/taxfolio/backend/src/services"
 	"github.com/username/taxfolio/backend/src/utils" // Import utils package
 )
 
 type UploadHandler struct {
-	uploadService services.UploadService
+	uploadService  services.UploadService
+	profileService services.ParserProfileService
 }
 
-func NewUploadHandler(service services.UploadService) *UploadHandler {
+func NewUploadHandler(service services.UploadService, profileService services.ParserProfileService) *UploadHandler {
 	return &UploadHandler{
-		uploadService: service,
+		uploadService:  service,
+		profileService: profileService,
 	}
 }
 
@@ -49,6 +53,31 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.L.Info("Received upload for source", "source", source, "userID", userID)
 
+	// Currency conversions are only ingested when the user opts in.
+	parserOpts := parsers.Options{
+		IncludeFXConversions: r.FormValue("include_fx") == "true",
+	}
+
+	// The custom source parses with one of the user's stored column mappings.
+	if source == "custom" {
+		profileID, err := strconv.ParseInt(r.FormValue("profile_id"), 10, 64)
+		if err != nil || profileID <= 0 {
+			utils.SendJSONError(w, "A valid profile_id is required for the custom source.", http.StatusBadRequest)
+			return
+		}
+		profile, err := h.profileService.GetProfile(userID, profileID)
+		if err != nil {
+			if errors.Is(err, services.ErrProfileNotFound) {
+				utils.SendJSONError(w, err.Error(), http.StatusNotFound)
+			} else {
+				logger.L.Error("Failed to load parser profile for upload", "userID", userID, "profileID", profileID, "error", err)
+				utils.SendJSONError(w, "Failed to load parser profile.", http.StatusInternalServerError)
+			}
+			return
+		}
+		parserOpts.Profile = profile
+	}
+
 	file, fileHeader, err := r.FormFile("file")
 	if err != nil {
 		logger.L.Warn("Failed to retrieve file from request", "userID", userID, "error", err)
@@ -82,7 +111,7 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	logger.L.Info("Processing upload request", "userID", userID, "filename", fileHeader.Filename)
 
 	// --- Pass the 'source' to the service ---
-	result, err := h.uploadService.ProcessUpload(file, userID, source)
+	result, err := h.uploadService.ProcessUpload(file, userID, source, parserOpts)
 	if err != nil {
 		if errors.Is(err, validation.ErrValidationFailed) {
 			logger.L.Warn("Upload processing failed due to data validation errors", "userID", userID, "filename", fileHeader.Filename, "error", err)
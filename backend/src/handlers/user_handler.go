@@ -495,14 +495,35 @@ func (h *UserHandler) DeleteAccountHandler(w http.ResponseWriter, r *http.Reques
 		return // err is set, defer will rollback
 	}
 
-	// 2. Delete sessions
+	// 2. Delete broker position snapshots
+	if _, err = txDB.Exec("DELETE FROM broker_positions WHERE user_id = ?", userID); err != nil {
+		logger.L.Error("Failed to delete broker positions for user", "userID", userID, "error", err)
+		sendJSONError(w, "Failed to delete account data (positions)", http.StatusInternalServerError)
+		return // err is set, defer will rollback
+	}
+
+	// 3. Delete custom parser profiles
+	if _, err = txDB.Exec("DELETE FROM parser_profiles WHERE user_id = ?", userID); err != nil {
+		logger.L.Error("Failed to delete parser profiles for user", "userID", userID, "error", err)
+		sendJSONError(w, "Failed to delete account data (parser profiles)", http.StatusInternalServerError)
+		return // err is set, defer will rollback
+	}
+
+	// 4. Delete imported price history
+	if _, err = txDB.Exec("DELETE FROM price_history WHERE user_id = ?", userID); err != nil {
+		logger.L.Error("Failed to delete price history for user", "userID", userID, "error", err)
+		sendJSONError(w, "Failed to delete account data (prices)", http.StatusInternalServerError)
+		return // err is set, defer will rollback
+	}
+
+	// 5. Delete sessions
 	if _, err = txDB.Exec("DELETE FROM sessions WHERE user_id = ?", userID); err != nil {
 		logger.L.Error("Failed to delete sessions for user", "userID", userID, "error", err)
 		sendJSONError(w, "Failed to delete account data (sessions)", http.StatusInternalServerError)
 		return // err is set, defer will rollback
 	}
 
-	// 3. Delete user
+	// 6. Delete user
 	if _, err = txDB.Exec("DELETE FROM users WHERE id = ?", userID); err != nil {
 		logger.L.Error("Failed to delete user from users table", "userID", userID, "error", err)
 		sendJSONError(w, "Failed to delete user account", http.StatusInternalServerError)
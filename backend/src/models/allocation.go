@@ -0,0 +1,26 @@
+package models
+
+// AllocationBucket is the share of the open positions in one group (a country, currency, asset class or broker).
+type AllocationBucket struct {
+	Key            string  `json:"key"`
+	CostEUR        float64 `json:"cost_eur"`
+	MarketValueEUR float64 `json:"market_value_eur"` // The cost for lots without a price
+	CostPct        float64 `json:"cost_pct"`         // Percentage of the total cost
+	MarketValuePct float64 `json:"market_value_pct"` // Percentage of the total market value
+	Lots           int     `json:"lots"`             // Open lots in the group
+	LotsAtCost     int     `json:"lots_at_cost"`     // Lots whose market value is their cost: bonds, crypto and unpriced stocks
+}
+
+// AllocationReport is the response of the allocation endpoint. Stock lots are valued at their latest stored
+// closing price; bond and crypto lots at cost. Open option positions are not included.
+type AllocationReport struct {
+	Date                string             `json:"date"` // DD-MM-YYYY valuation date
+	TotalCostEUR        float64            `json:"total_cost_eur"`
+	TotalMarketValueEUR float64            `json:"total_market_value_eur"`
+	ByCountry           []AllocationBucket `json:"by_country"`     // Country of the ISIN
+	ByCurrency          []AllocationBucket `json:"by_currency"`    // Trading currency; the asset for crypto
+	ByAssetClass        []AllocationBucket `json:"by_asset_class"` // Instrument class
+	ByBroker            []AllocationBucket `json:"by_broker"`      // Transaction source
+	MissingPrices       int                `json:"missing_prices"` // Stock positions valued at cost for lack of a price
+	LotsAtCost          int                `json:"lots_at_cost"`   // Bond and crypto lots, which have no price source, and lots of unpriced stocks
+}
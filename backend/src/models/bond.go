@@ -0,0 +1,63 @@
+package models
+
+// BondSaleDetail is the sale or redemption of (part of) a bond lot, matched FIFO per ISIN.
+// Quantities are nominal (face) amounts; amounts are clean prices, without accrued interest.
+type BondSaleDetail struct {
+	Source        string  `json:"source"`
+	SaleDate      string  `json:"sale_date"`
+	BuyDate       string  `json:"buy_date"`
+	ProductName   string  `json:"product_name"`
+	ISIN          string  `json:"isin"`
+	Quantity      float64 `json:"quantity"`
+	BuyAmountEUR  float64 `json:"buy_amount_eur"`  // Negative, like stock purchases
+	SaleAmountEUR float64 `json:"sale_amount_eur"` // Sale proceeds or redemption value
+	Commission    float64 `json:"commission"`      // Buy and sell commissions allocated to this match
+	Delta         float64 `json:"delta"`           // Capital gain (SaleAmountEUR + BuyAmountEUR)
+	Redemption    bool    `json:"redemption"`      // Repaid by the issuer at maturity
+	CountryCode   string  `json:"country_code"`
+}
+
+// BondHolding is an open bond lot.
+type BondHolding struct {
+	Source       string  `json:"source"`
+	BuyDate      string  `json:"buy_date"`
+	ProductName  string  `json:"product_name"`
+	ISIN         string  `json:"isin"`
+	Quantity     float64 `json:"quantity"`
+	BuyAmountEUR float64 `json:"buy_amount_eur"`
+	Currency     string  `json:"currency"`
+	CountryCode  string  `json:"country_code"`
+}
+
+// BondInterestDetail is one interest cash flow of a bond: a coupon, or accrued interest paid on a purchase
+// or received on a sale.
+type BondInterestDetail struct {
+	Source      string  `json:"source"`
+	Date        string  `json:"date"`
+	ProductName string  `json:"product_name"`
+	ISIN        string  `json:"isin"`
+	Type        string  `json:"type"`   // COUPON, ACCRUED_PAID or ACCRUED_RECEIVED
+	Amount      float64 `json:"amount"` // Signed, in Currency
+	Currency    string  `json:"currency"`
+	AmountEUR   float64 `json:"amount_eur"`
+	CountryCode string  `json:"country_code"`
+}
+
+// BondYearSummary is a year's bond result. Interest income is category E; the capital gain is reported with
+// other securities in category G.
+type BondYearSummary struct {
+	CouponsEUR                 float64 `json:"coupons_eur"`
+	AccruedInterestReceivedEUR float64 `json:"accrued_interest_received_eur"`
+	AccruedInterestPaidEUR     float64 `json:"accrued_interest_paid_eur"` // Positive; deducted from the interest income
+	InterestIncomeEUR          float64 `json:"interest_income_eur"`
+	CapitalGainEUR             float64 `json:"capital_gain_eur"`
+}
+
+// BondReport is the result of the bond processor. Summary is keyed by year.
+type BondReport struct {
+	Sales          []BondSaleDetail           `json:"sales"`
+	Holdings       []BondHolding              `json:"holdings"`
+	Interest       []BondInterestDetail       `json:"interest"`
+	Summary        map[string]BondYearSummary `json:"summary"`
+	MatchingErrors []LotMatchingError         `json:"matching_errors"`
+}
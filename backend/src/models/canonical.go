@@ -15,6 +15,7 @@ type CanonicalTransaction struct {
 	Quantity           float64   `json:"quantity"`
 	Price              float64   `json:"price"`
 	Commission         float64   `json:"commission"`
+	CommissionCurrency string    `json:"commission_currency"` // Set when the broker charges the commission in another currency than Currency
 	Currency           string    `json:"currency"`
 	OrderID            string    `json:"order_id"`
 	RawText            string    `json:"raw_text"`
@@ -23,10 +24,19 @@ type CanonicalTransaction struct {
 	TransactionType    string    `json:"transaction_type"`     // e.g., "STOCK", "OPTION", "DIVIDEND", "FEE", "CASH"
 	TransactionSubType string    `json:"transaction_sub_type"` // e.g., "CALL", "PUT", "TAX", "DEPOSIT"
 	BuySell            string    `json:"buy_sell"`             // e.g., "BUY", "SELL"
+	InstrumentClass    string    `json:"instrument_class"`     // Optional broker hint (e.g. IBKR subCategory "ETF"); final class is set by the processor
+
+	// --- Option contract identity, populated by the Parser for OPTION transactions ---
+	Underlying  string    `json:"underlying"`
+	Strike      float64   `json:"strike"`
+	Expiry      time.Time `json:"expiry"`
+	OptionRight string    `json:"option_right"` // "CALL" or "PUT"
+	Multiplier  float64   `json:"multiplier"`   // Shares per contract; the processor defaults it to 100
 
 	// --- Fields to be filled by the Enricher/Processor ---
-	ExchangeRate float64 `json:"exchange_rate"` // Exchange rate to EUR
-	AmountEUR    float64 `json:"amount_eur"`    // Final amount in EUR
+	ExchangeRate float64 `json:"exchange_rate"`          // Exchange rate to EUR
+	RateFallback bool    `json:"exchange_rate_fallback"` // Set when no rate was available and 1.0 was used
+	AmountEUR    float64 `json:"amount_eur"`             // Final amount in EUR
 	CountryCode  string  `json:"country_code"`
 	HashId       string  `json:"hash_id"`
 }
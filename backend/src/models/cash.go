@@ -0,0 +1,33 @@
+package models
+
+// CashLedgerEntry is one transaction's effect on the cash of a broker account in one currency.
+type CashLedgerEntry struct {
+	TransactionID      int64   `json:"transaction_id"`
+	Date               string  `json:"date"` // DD-MM-YYYY
+	Source             string  `json:"source"`
+	Currency           string  `json:"currency"`
+	TransactionType    string  `json:"transaction_type"`
+	TransactionSubType string  `json:"transaction_subtype"`
+	BuySell            string  `json:"buy_sell"`
+	ProductName        string  `json:"product_name"`
+	Amount             float64 `json:"amount"`  // Signed cash effect, including any commission not booked as a fee row
+	Balance            float64 `json:"balance"` // Running balance after this entry
+}
+
+// CashBalance is the current balance of a broker account in one currency, with the lowest balance it reached.
+// A lowest balance below zero usually means a missing transaction or currency conversion, or a parsing error,
+// unless the account is a margin account.
+type CashBalance struct {
+	Source            string  `json:"source"`
+	Currency          string  `json:"currency"`
+	Balance           float64 `json:"balance"`
+	LowestBalance     float64 `json:"lowest_balance"`
+	LowestBalanceDate string  `json:"lowest_balance_date"` // DD-MM-YYYY
+	Negative          bool    `json:"negative"`            // The balance went below zero at some point
+}
+
+// CashLedgerReport is the response of the cash ledger endpoint.
+type CashLedgerReport struct {
+	Entries  []CashLedgerEntry `json:"entries"` // Oldest first
+	Balances []CashBalance     `json:"balances"`
+}
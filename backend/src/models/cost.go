@@ -0,0 +1,42 @@
+package models
+
+// CostItem is one cost: a fee row, or a trade commission the broker did not also book as a fee row.
+type CostItem struct {
+	Date        string  `json:"date"` // DD-MM-YYYY
+	Source      string  `json:"source"`
+	Category    string  `json:"category"` // COMMISSION, CONNECTIVITY, FX, CUSTODY or OTHER
+	ProductName string  `json:"product_name"`
+	OrderID     string  `json:"order_id"`
+	Amount      float64 `json:"amount"` // Positive, in Currency
+	Currency    string  `json:"currency"`
+	AmountEUR   float64 `json:"amount_eur"` // Positive
+}
+
+// CostBreakdown totals costs in EUR by category.
+type CostBreakdown struct {
+	CommissionsEUR  float64 `json:"commissions_eur"`
+	ConnectivityEUR float64 `json:"connectivity_eur"` // Exchange connectivity fees
+	FXCostsEUR      float64 `json:"fx_costs_eur"`     // Commissions on currency conversions
+	CustodyEUR      float64 `json:"custody_eur"`
+	OtherEUR        float64 `json:"other_eur"`
+	TotalEUR        float64 `json:"total_eur"`
+}
+
+// CostYearSummary is a year's costs, split by broker and by currency.
+type CostYearSummary struct {
+	CostBreakdown
+	ByBroker                 map[string]CostBreakdown `json:"by_broker"`
+	ByCurrency               map[string]CostBreakdown `json:"by_currency"`
+	TradedVolumeEUR          float64                  `json:"traded_volume_eur"` // Purchases and sales of securities and crypto
+	CostOfVolumePct          float64                  `json:"cost_of_volume_pct"`
+	AveragePortfolioValueEUR float64                  `json:"average_portfolio_value_eur"` // Mean of the daily values
+	CostOfPortfolioPct       float64                  `json:"cost_of_portfolio_pct"`
+}
+
+// CostReport is the response of the cost analysis endpoint. Years is keyed by year; Total covers all years, with
+// its portfolio percentage against the average value over the whole history.
+type CostReport struct {
+	Items []CostItem                 `json:"items"`
+	Years map[string]CostYearSummary `json:"years"`
+	Total CostYearSummary            `json:"total"`
+}
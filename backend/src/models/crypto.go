@@ -0,0 +1,44 @@
+package models
+
+// CryptoDisposalDetail is the disposal of (part of) a crypto lot, matched FIFO per asset across exchanges.
+// Crypto-to-crypto swaps are disposals of the asset given up, valued at the EUR value of the swap.
+type CryptoDisposalDetail struct {
+	Source            string  `json:"source"`
+	Asset             string  `json:"asset"`
+	AcquisitionDate   string  `json:"acquisition_date"`
+	DisposalDate      string  `json:"disposal_date"`
+	Quantity          float64 `json:"quantity"`
+	CostEUR           float64 `json:"cost_eur"`           // Acquisition cost of the quantity, including buy fees
+	ProceedsEUR       float64 `json:"proceeds_eur"`       // Disposal value of the quantity, net of sell fees
+	Delta             float64 `json:"delta"`              // ProceedsEUR - CostEUR
+	HoldingDays       int     `json:"holding_days"`       // Days between acquisition and disposal
+	Exempt            bool    `json:"exempt"`             // Held for 365 days or more
+	DisposalType      string  `json:"disposal_type"`      // "SELL" for sales against fiat, "SWAP" for crypto-to-crypto trades
+	ValuationFallback bool    `json:"valuation_fallback"` // Swap with no EUR price available; valued at its cost (no gain)
+}
+
+// CryptoLot is an open (unsold) crypto acquisition.
+type CryptoLot struct {
+	Source          string  `json:"source"`
+	Asset           string  `json:"asset"`
+	AcquisitionDate string  `json:"acquisition_date"`
+	Quantity        float64 `json:"quantity"`
+	CostEUR         float64 `json:"cost_eur"`
+}
+
+// CryptoYearSummary splits a year's realised crypto result into the exempt (held 365+ days) and taxable parts.
+type CryptoYearSummary struct {
+	ProceedsEUR     float64 `json:"proceeds_eur"`
+	CostEUR         float64 `json:"cost_eur"`
+	ExemptGainEUR   float64 `json:"exempt_gain_eur"`
+	TaxableGainEUR  float64 `json:"taxable_gain_eur"`
+	EstimatedTaxEUR float64 `json:"estimated_tax_eur"` // Autonomous rate on a positive taxable gain
+}
+
+// CryptoGainReport is the response of the crypto gains endpoint. Summary is keyed by year.
+type CryptoGainReport struct {
+	Details        []CryptoDisposalDetail       `json:"details"`
+	Holdings       []CryptoLot                  `json:"holdings"`
+	Summary        map[string]CryptoYearSummary `json:"summary"`
+	MatchingErrors []LotMatchingError           `json:"matching_errors"`
+}
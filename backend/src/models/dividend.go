@@ -9,3 +9,36 @@ type DividendCountrySummary struct {
 // DividendTaxResult represents the final structure for the dividend tax summary endpoint.
 // map[Year]map[Country]DividendCountrySummary
 type DividendTaxResult map[string]map[string]DividendCountrySummary
+
+// DividendHoldingAnalytics summarises the dividends of one ISIN (the product name when there is no ISIN).
+// Amounts are gross, before withholding tax.
+type DividendHoldingAnalytics struct {
+	ISIN                    string             `json:"isin"`
+	ProductName             string             `json:"product_name"`
+	TrailingTwelveMonthsEUR float64            `json:"trailing_twelve_months_eur"`
+	OpenCostEUR             float64            `json:"open_cost_eur"`         // Cost of the open long lots
+	YieldOnCost             float64            `json:"yield_on_cost"`         // TrailingTwelveMonthsEUR as a percentage of OpenCostEUR
+	PaymentsPerYear         int                `json:"payments_per_year"`     // Payment dates in the trailing twelve months
+	LastPaymentDate         string             `json:"last_payment_date"`     // DD-MM-YYYY
+	YearlyEUR               map[string]float64 `json:"yearly_eur"`            // Keyed by year
+	YearOverYearGrowth      map[string]float64 `json:"year_over_year_growth"` // Completed years: percentage change from the previous year, when it had dividends
+	MonthlyEUR              [12]float64        `json:"monthly_eur"`           // All years, by payment month (January first)
+}
+
+// ProjectedDividend is an expected payment: a payment of the trailing twelve months repeated one year later.
+type ProjectedDividend struct {
+	Date        string  `json:"date"` // DD-MM-YYYY
+	ISIN        string  `json:"isin"`
+	ProductName string  `json:"product_name"`
+	AmountEUR   float64 `json:"amount_eur"`
+}
+
+// DividendAnalytics is the response of the dividend analytics endpoint.
+type DividendAnalytics struct {
+	Date                     string                     `json:"date"` // DD-MM-YYYY the trailing and projected windows are measured from
+	Holdings                 []DividendHoldingAnalytics `json:"holdings"`
+	TrailingTwelveMonthsEUR  float64                    `json:"trailing_twelve_months_eur"`
+	MonthlyEUR               [12]float64                `json:"monthly_eur"`
+	Calendar                 []ProjectedDividend        `json:"calendar"` // Next twelve months, for ISINs still held
+	ProjectedTwelveMonthsEUR float64                    `json:"projected_twelve_months_eur"`
+}
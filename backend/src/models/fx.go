@@ -0,0 +1,34 @@
+package models
+
+// FXGainDetail represents the disposal of (part of) a foreign-currency cash lot, matched FIFO.
+type FXGainDetail struct {
+	Source          string  `json:"source"`
+	Currency        string  `json:"currency"`
+	AcquisitionDate string  `json:"acquisition_date"`
+	DisposalDate    string  `json:"disposal_date"`
+	Amount          float64 `json:"amount"`        // Amount of foreign currency disposed
+	CostEUR         float64 `json:"cost_eur"`      // EUR value of the amount when it was acquired
+	ProceedsEUR     float64 `json:"proceeds_eur"`  // EUR value of the amount when it was disposed
+	Delta           float64 `json:"delta"`         // Realised FX gain/loss (ProceedsEUR - CostEUR)
+	DisposalType    string  `json:"disposal_type"` // TransactionType of the outflow, e.g. "FX", "STOCK", "FEE"
+}
+
+// FXCurrencySummary holds the realised FX result for one currency in a year.
+type FXCurrencySummary struct {
+	ProceedsEUR     float64 `json:"proceeds_eur"`
+	CostEUR         float64 `json:"cost_eur"`
+	GainEUR         float64 `json:"gain_eur"`
+	UnmatchedAmount float64 `json:"unmatched_amount"` // Outflows with no acquired lot left (missing history)
+}
+
+// FXGainResult aggregates realised FX gains: map[Year]map[Currency]FXCurrencySummary
+type FXGainResult map[string]map[string]FXCurrencySummary
+
+// FXGainReport is the response of the FX gain/loss endpoint.
+type FXGainReport struct {
+	Details []FXGainDetail `json:"details"`
+	Summary FXGainResult   `json:"summary"`
+	// UntrackedAccounts lists the "source|currency" accounts holding foreign cash whose currency
+	// conversions were not imported, so their cash lots are unknown and no gain is reported for them.
+	UntrackedAccounts []string `json:"untracked_accounts"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// HarvestCandidate is an open stock lot trading below its cost. Sales are matched FIFO, so realising its loss
+// means selling the older lots of the same ISIN first; the FIFO fields describe that whole sale.
+type HarvestCandidate struct {
+	Source                string  `json:"source"`
+	BuyDate               string  `json:"buy_date"` // DD-MM-YYYY
+	ProductName           string  `json:"product_name"`
+	ISIN                  string  `json:"isin"`
+	Quantity              float64 `json:"quantity"`
+	CostEUR               float64 `json:"cost_eur"`
+	MarketValueEUR        float64 `json:"market_value_eur"`
+	UnrealisedLossEUR     float64 `json:"unrealised_loss_eur"` // Negative
+	HoldingDays           int     `json:"holding_days"`
+	FIFOQuantity          float64 `json:"fifo_quantity"`            // Shares to sell, this lot and all older ones
+	FIFOResultEUR         float64 `json:"fifo_result_eur"`          // Gain or loss that sale would realise
+	EstimatedTaxEffectEUR float64 `json:"estimated_tax_effect_eur"` // Change in the year's estimated tax; negative is a saving
+}
+
+// HarvestReport is the response of the tax-loss harvesting endpoint. Commissions are not included.
+type HarvestReport struct {
+	Date              string             `json:"date"` // DD-MM-YYYY
+	Year              string             `json:"year"`
+	RealisedGainEUR   float64            `json:"realised_gain_eur"` // Stock, option and bond sales of the year so far
+	TaxRate           float64            `json:"tax_rate"`
+	EstimatedTaxEUR   float64            `json:"estimated_tax_eur"`  // On a positive RealisedGainEUR
+	Candidates        []HarvestCandidate `json:"candidates"`         // Largest loss first
+	UnpricedPositions []string           `json:"unpriced_positions"` // ISINs without a stored price, not assessed
+}
@@ -0,0 +1,32 @@
+package models
+
+// Instrument classes tagged on transactions by the transaction processor. Portuguese reporting puts
+// shares, fund units and bonds under different Anexo J codes, so they are kept apart in sale details.
+const (
+	InstrumentClassShare   = "SHARE"
+	InstrumentClassETF     = "ETF"
+	InstrumentClassFund    = "FUND"
+	InstrumentClassBond    = "BOND"
+	InstrumentClassWarrant = "WARRANT"
+	InstrumentClassOption  = "OPTION"
+	InstrumentClassCrypto  = "CRYPTO"
+)
+
+// IsInstrumentClass reports whether class is one of the known instrument classes.
+func IsInstrumentClass(class string) bool {
+	switch class {
+	case InstrumentClassShare, InstrumentClassETF, InstrumentClassFund, InstrumentClassBond,
+		InstrumentClassWarrant, InstrumentClassOption, InstrumentClassCrypto:
+		return true
+	}
+	return false
+}
+
+// InstrumentClassSaleSummary totals a year's stock sales of one instrument class, the figures entered per
+// Anexo J code.
+type InstrumentClassSaleSummary struct {
+	Sales         int     `json:"sales"`
+	SaleAmountEUR float64 `json:"sale_amount_eur"`
+	CostEUR       float64 `json:"cost_eur"`
+	GainEUR       float64 `json:"gain_eur"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// InterestSummary holds the aggregated interest amounts (in EUR) for one currency of a country in a year.
+type InterestSummary struct {
+	GrossAmt         float64 `json:"gross_amt"`          // Interest received, securities lending income included
+	TaxedAmt         float64 `json:"taxed_amt"`          // Tax withheld at source (negative)
+	LendingAmt       float64 `json:"lending_amt"`        // Part of GrossAmt from securities lending (e.g. IBKR SYEP)
+	PaidAmt          float64 `json:"paid_amt"`           // Debit interest charged by the broker (negative); not income
+	GrossAmtOriginal float64 `json:"gross_amt_original"` // GrossAmt in the original currency
+}
+
+// InterestTaxResult is the interest income summary for category E reporting.
+// map[Year]map[Country]map[Currency]InterestSummary
+type InterestTaxResult map[string]map[string]map[string]InterestSummary
@@ -0,0 +1,21 @@
+package models
+
+// ParserProfile is a user-defined mapping that lets the "custom" source import any broker CSV.
+// Columns map canonical fields ("date", "product_name", "isin", "quantity", "price", "amount",
+// "currency", "commission", "order_id", "type", "description") to a header name or a 0-based column index.
+// Patterns map a transaction kind ("BUY", "SELL", "DIVIDEND", "TAX", "FEE", "DEPOSIT", "WITHDRAWAL",
+// "INTEREST") to a regular expression matched against the row's type and description text.
+type ParserProfile struct {
+	ID               int64             `json:"id"`
+	Name             string            `json:"name"`
+	HasHeader        bool              `json:"has_header"`
+	Delimiter        string            `json:"delimiter"`         // Single character; "," when empty
+	DateFormat       string            `json:"date_format"`       // e.g. "DD-MM-YYYY", "YYYY-MM-DD HH:mm:ss" or a Go layout
+	DecimalSeparator string            `json:"decimal_separator"` // "." (default) or ","
+	SignConvention   string            `json:"sign_convention"`   // "as_is" (default), "inverted" or "by_type"
+	DefaultCurrency  string            `json:"default_currency"`  // Used when no currency column is mapped or the cell is empty
+	Columns          map[string]string `json:"columns"`
+	Patterns         map[string]string `json:"patterns"`
+	CreatedAt        string            `json:"created_at,omitempty"`
+	UpdatedAt        string            `json:"updated_at,omitempty"`
+}
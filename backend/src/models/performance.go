@@ -0,0 +1,50 @@
+package models
+
+// PerformancePeriod holds the returns of the portfolio over a period. Values include cash, reconstructed from the
+// EUR amounts of all transactions; external flows are deposits, withdrawals and securities transferred in or out.
+type PerformancePeriod struct {
+	From                string   `json:"from"` // DD-MM-YYYY
+	To                  string   `json:"to"`   // DD-MM-YYYY
+	StartValueEUR       float64  `json:"start_value_eur"`
+	EndValueEUR         float64  `json:"end_value_eur"`
+	NetContributionsEUR float64  `json:"net_contributions_eur"` // External inflows minus outflows within the period
+	GainEUR             float64  `json:"gain_eur"`              // EndValueEUR - StartValueEUR - NetContributionsEUR
+	TimeWeightedReturn  float64  `json:"time_weighted_return"`  // Percentage over the period, chained from daily valuations
+	MoneyWeightedReturn *float64 `json:"money_weighted_return"` // Annualised XIRR percentage; null when it cannot be solved
+}
+
+// PerformancePoint is the portfolio value at the end of a day.
+type PerformancePoint struct {
+	Date                string  `json:"date"` // DD-MM-YYYY
+	ValueEUR            float64 `json:"value_eur"`
+	NetContributionsEUR float64 `json:"net_contributions_eur"` // Cumulative since the start of the report
+}
+
+// PerformanceReport is the response of the performance endpoint. Years covers each calendar year within the period.
+type PerformanceReport struct {
+	PerformancePeriod
+	AnnualisedTWR     float64             `json:"annualised_twr"` // Equal to TimeWeightedReturn for periods shorter than a year
+	Years             []PerformancePeriod `json:"years"`
+	Series            []PerformancePoint  `json:"series"`
+	UnpricedPositions []string            `json:"unpriced_positions"` // Open at the end and valued at their last trade price
+}
+
+// BenchmarkPoint is the value of the portfolio and of the benchmark replay at the end of a day.
+type BenchmarkPoint struct {
+	Date                string  `json:"date"` // DD-MM-YYYY
+	PortfolioValueEUR   float64 `json:"portfolio_value_eur"`
+	BenchmarkValueEUR   float64 `json:"benchmark_value_eur"`
+	NetContributionsEUR float64 `json:"net_contributions_eur"`
+}
+
+// BenchmarkComparison is the response of the benchmark endpoint. The benchmark replay invests the portfolio's
+// starting value and every external flow in the benchmark on the same day; flows before its first price wait as cash.
+type BenchmarkComparison struct {
+	BenchmarkISIN             string            `json:"benchmark_isin"`
+	Portfolio                 PerformancePeriod `json:"portfolio"`
+	Benchmark                 PerformancePeriod `json:"benchmark"`
+	ExcessTimeWeightedReturn  float64           `json:"excess_time_weighted_return"`  // Percentage points, portfolio minus benchmark
+	ExcessMoneyWeightedReturn *float64          `json:"excess_money_weighted_return"` // Null when either XIRR has no solution
+	ExcessValueEUR            float64           `json:"excess_value_eur"`             // Portfolio minus benchmark end value
+	Series                    []BenchmarkPoint  `json:"series"`
+}
@@ -6,7 +6,7 @@ type SaleDetail struct {
 	BuyDate          string
 	ProductName      string
 	ISIN             string
-	Quantity         int
+	Quantity         float64
 	SalePrice        float64
 	SaleAmount       float64 // Sale amount in original currency
 	SaleCurrency     string
@@ -19,19 +19,37 @@ type SaleDetail struct {
 	BuyAmountEUR     float64 // Purchase amount in EUR
 	SaleExchangeRate float64 // Exchange rate used for the sale transaction
 	Delta            float64 // Profit/Loss (SaleAmountEUR - BuyAmountEUR)
-	CountryCode      string  `json:"country_code"` // Country code derived from ISIN (e.g., "840 - United States of America (the)")
+	CountryCode      string  `json:"country_code"`     // Country code derived from ISIN (e.g., "840 - United States of America (the)")
+	InstrumentClass  string  `json:"instrument_class"` // SHARE, ETF, FUND, BOND or WARRANT
+	Short            bool    `json:"short"`            // Short round trip: BuyDate opened the position with a sale, SaleDate is the covering buy
 }
 
 // PurchaseLot represents remaining unsold purchase lots for stocks.
+// Open short positions are included with a negative Quantity; their buy fields describe the opening sale.
 type PurchaseLot struct {
-	BuyDate      string  `json:"buy_date"`
-	ProductName  string  `json:"product_name"`
-	ISIN         string  `json:"isin"`
-	Quantity     int     `json:"quantity"`
-	BuyPrice     float64 `json:"buyPrice"`
-	BuyAmount    float64 `json:"buy_amount"`     // Purchase amount in original currency
-	BuyCurrency  string  `json:"buy_currency"`   // Original purchase currency
-	BuyAmountEUR float64 `json:"buy_amount_eur"` // Purchase amount in EUR
+	Source          string  `json:"source"`
+	BuyDate         string  `json:"buy_date"`
+	ProductName     string  `json:"product_name"`
+	ISIN            string  `json:"isin"`
+	Quantity        float64 `json:"quantity"`
+	BuyPrice        float64 `json:"buyPrice"`
+	BuyAmount       float64 `json:"buy_amount"`     // Purchase amount in original currency
+	BuyCurrency     string  `json:"buy_currency"`   // Original purchase currency
+	BuyAmountEUR    float64 `json:"buy_amount_eur"` // Purchase amount in EUR
+	InstrumentClass string  `json:"instrument_class"`
+	CountryCode     string  `json:"country_code"`
+}
+
+// LotMatchingError reports a stock sale or transfer that could not be fully matched against open purchase lots.
+type LotMatchingError struct {
+	Date              string  `json:"date"`
+	Source            string  `json:"source"`
+	ISIN              string  `json:"isin"`
+	ProductName       string  `json:"product_name"`
+	Event             string  `json:"event"` // SELL, TRANSFER_IN or TRANSFER_OUT
+	Quantity          float64 `json:"quantity"`
+	UnmatchedQuantity float64 `json:"unmatched_quantity"`
+	Message           string  `json:"message"`
 }
 
 // OptionSaleDetail represents the details of a closed option position (buy/sell pair).
@@ -39,7 +57,7 @@ type OptionSaleDetail struct {
 	OpenDate       string  `json:"open_date"`
 	CloseDate      string  `json:"close_date"`
 	ProductName    string  `json:"product_name"` // e.g., "FLW P31.00 18MAR22"
-	Quantity       int     `json:"quantity"`
+	Quantity       float64 `json:"quantity"`
 	OpenPrice      float64 `json:"open_price"`
 	OpenAmount     float64 `json:"open_amount"` // Open amount in original currency
 	OpenCurrency   string  `json:"open_currency"`
@@ -53,16 +71,27 @@ type OptionSaleDetail struct {
 	OpenOrderID    string  `json:"open_order_id"`    // Optional: Order ID of the opening transaction
 	CloseOrderID   string  `json:"close_order_id"`   // Optional: Order ID of the closing transaction
 	CountryCode    string  `json:"country_code"`     // Country code derived from ISIN (e.g., "840 - United States of America (the)")
+	Underlying     string  `json:"underlying"`
+	Strike         float64 `json:"strike"`
+	Expiry         string  `json:"expiry"`
+	OptionRight    string  `json:"option_right"`
+	Multiplier     float64 `json:"multiplier"`
+	Expired        bool    `json:"expired"` // Closed at zero on the expiry date because no closing trade was found
 }
 
 // OptionHolding represents an open option position (either long or short).
 type OptionHolding struct {
 	OpenDate      string  `json:"open_date"`
 	ProductName   string  `json:"product_name"`
-	Quantity      int     `json:"quantity"` // Positive for long positions, negative for short positions
+	Quantity      float64 `json:"quantity"` // Positive for long positions, negative for short positions
 	OpenPrice     float64 `json:"open_price"`
 	OpenAmount    float64 `json:"open_amount"` // Open amount in original currency
 	OpenCurrency  string  `json:"open_currency"`
 	OpenAmountEUR float64 `json:"open_amount_eur"` // Open amount in EUR
 	OpenOrderID   string  `json:"open_order_id"`   // Optional: Order ID of the opening transaction
+	Underlying    string  `json:"underlying"`
+	Strike        float64 `json:"strike"`
+	Expiry        string  `json:"expiry"`
+	OptionRight   string  `json:"option_right"`
+	Multiplier    float64 `json:"multiplier"`
 }
@@ -0,0 +1,43 @@
+package models
+
+// BrokerPosition is an open position as reported by the broker itself
+// (IBKR Flex "OpenPositions" section, DeGiro Portfolio.csv).
+type BrokerPosition struct {
+	Source      string  `json:"source"`
+	ReportDate  string  `json:"report_date"` // DD-MM-YYYY, empty when the export carries no date
+	AssetType   string  `json:"asset_type"`  // "STOCK" or "OPTION"
+	ISIN        string  `json:"isin"`
+	Symbol      string  `json:"symbol"`
+	ProductName string  `json:"product_name"`
+	Quantity    float64 `json:"quantity"`   // Negative for short positions
+	CostBasis   float64 `json:"cost_basis"` // Total cost in Currency; 0 when the broker does not report it
+	Currency    string  `json:"currency"`
+	Underlying  string  `json:"underlying,omitempty"` // Option contract identity, when the export reports it
+	Strike      float64 `json:"strike,omitempty"`
+	Expiry      string  `json:"expiry,omitempty"` // DD-MM-YYYY
+	OptionRight string  `json:"option_right,omitempty"`
+	Multiplier  float64 `json:"multiplier,omitempty"`
+}
+
+// ReconciliationItem compares the computed holding for one ISIN/contract with the broker's figure.
+type ReconciliationItem struct {
+	Source           string  `json:"source"`
+	AssetType        string  `json:"asset_type"`
+	Key              string  `json:"key"` // ISIN for stocks, contract identity (underlying|right|strike|expiry|multiplier) for options
+	ProductName      string  `json:"product_name"`
+	Currency         string  `json:"currency"`
+	ComputedQuantity float64 `json:"computed_quantity"`
+	BrokerQuantity   float64 `json:"broker_quantity"`
+	QuantityDiff     float64 `json:"quantity_diff"` // ComputedQuantity - BrokerQuantity
+	ComputedCost     float64 `json:"computed_cost"`
+	BrokerCost       float64 `json:"broker_cost"`
+	CostDiff         float64 `json:"cost_diff"` // ComputedCost - BrokerCost, only when the broker reports cost
+	Status           string  `json:"status"`    // "OK", "QUANTITY_MISMATCH", "COST_MISMATCH", "MISSING_IN_BROKER", "MISSING_IN_HISTORY"
+}
+
+// ReconciliationReport is the result of reconciling every uploaded position snapshot.
+type ReconciliationReport struct {
+	ReportDates map[string]string    `json:"report_dates"` // map[Source]ReportDate of the snapshot used
+	Items       []ReconciliationItem `json:"items"`
+	Mismatches  int                  `json:"mismatches"`
+}
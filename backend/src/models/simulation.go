@@ -0,0 +1,26 @@
+package models
+
+// SimulatedSaleLot is a lot a hypothetical sale would consume.
+type SimulatedSaleLot struct {
+	SaleDetail
+	HoldingDays int `json:"holding_days"`
+}
+
+// SaleSimulation is the response of the sale simulator. Nothing is stored.
+type SaleSimulation struct {
+	Date                string             `json:"date"` // DD-MM-YYYY
+	ISIN                string             `json:"isin"`
+	ProductName         string             `json:"product_name"`
+	Quantity            float64            `json:"quantity"`
+	Price               float64            `json:"price"`
+	Currency            string             `json:"currency"`
+	ExchangeRate        float64            `json:"exchange_rate"`
+	SaleAmountEUR       float64            `json:"sale_amount_eur"`
+	CommissionEUR       float64            `json:"commission_eur"`
+	Lots                []SimulatedSaleLot `json:"lots"`                   // In FIFO order
+	RealisedGainEUR     float64            `json:"realised_gain_eur"`      // Net of CommissionEUR
+	UnmatchedQuantity   float64            `json:"unmatched_quantity"`     // Beyond the open lots; it would open a short position
+	YearRealisedGainEUR float64            `json:"year_realised_gain_eur"` // Stock, option and bond sales of the sale's year up to its date, without it
+	TaxRate             float64            `json:"tax_rate"`
+	EstimatedTaxEUR     float64            `json:"estimated_tax_eur"` // Additional tax on the year's gains; negative when the sale lowers it
+}
@@ -21,8 +21,8 @@ type ProcessedTransaction struct {
 	Source             string  `json:"source"` // e.g., DEGIRO, IBKR
 	ProductName        string  `json:"product_name"`
 	ISIN               string  `json:"isin"`
-	Quantity           int     `json:"quantity"`
-	OriginalQuantity   int     `json:"original_quantity"` // Original quantity of the purchase lot before any sales
+	Quantity           float64 `json:"quantity"`          // May be fractional (e.g. Revolut); signed share delta for SPLIT rows
+	OriginalQuantity   float64 `json:"original_quantity"` // Original quantity of the purchase lot before any sales
 	Price              float64 `json:"price"`
 	TransactionType    string  `json:"transaction_type"`    // e.g., "STOCK", "OPTION", "DIVIDEND", "FEE", "CASH"
 	TransactionSubType string  `json:"transaction_subtype"` // e.g., "CALL", "PUT", "TAX", "DEPOSIT"
@@ -31,18 +31,26 @@ type ProcessedTransaction struct {
 	Amount             float64 `json:"amount"`              // Transaction amount in original currency
 	Currency           string  `json:"currency"`            // Original currency (e.g., "USD", "EUR")
 	Commission         float64 `json:"commission"`          // Commission/fees
+	CommissionCurrency string  `json:"commission_currency"` // Currency of Commission; empty when it is Currency
 	OrderID            string  `json:"order_id"`
 	ExchangeRate       float64 `json:"exchange_rate"`          // Exchange rate to EUR (if applicable)
 	AmountEUR          float64 `json:"amount_eur"`             // Transaction amount in EUR (calculated)
+	RateFallback       bool    `json:"exchange_rate_fallback"` // True when no rate was found and 1.0 was used instead
 	CountryCode        string  `json:"country_code,omitempty"` // Country code derived from ISIN
-	InputString        string  `json:"input_string"`           // The full description string for reference
-	HashId             string  `json:"hash_id"`                // Generated hash for potential duplicate checking
+	InstrumentClass    string  `json:"instrument_class"`       // e.g., "SHARE", "ETF", "FUND", "BOND"; empty for cash movements
+	Underlying         string  `json:"underlying,omitempty"`   // Option contract identity (OPTION rows only)
+	Strike             float64 `json:"strike,omitempty"`
+	Expiry             string  `json:"expiry,omitempty"` // DD-MM-YYYY
+	OptionRight        string  `json:"option_right,omitempty"`
+	Multiplier         float64 `json:"multiplier,omitempty"`
+	InputString        string  `json:"input_string"` // The full description string for reference
+	HashId             string  `json:"hash_id"`      // Generated hash for potential duplicate checking
 }
 
 // CashMovement represents a cash deposit or withdrawal
 type CashMovement struct {
 	Date     string  `json:"date"`     // Date of the movement
 	Type     string  `json:"type"`     // "deposit" or "withdrawal"
-	Amount   float64 `json:"amount"`   // Amount in original currency
+	Amount   float64 `json:"amount"`   // Amount in original currency, negative for withdrawals
 	Currency string  `json:"currency"` // Original currency
 }
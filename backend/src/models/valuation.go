@@ -0,0 +1,28 @@
+package models
+
+// PositionValuation is an open stock position (all lots of one ISIN) valued at its latest stored closing price.
+type PositionValuation struct {
+	ISIN              string  `json:"isin"`
+	ProductName       string  `json:"product_name"`
+	InstrumentClass   string  `json:"instrument_class"`
+	Quantity          float64 `json:"quantity"`            // Negative for short positions
+	CostEUR           float64 `json:"cost_eur"`            // Cost of the open lots; for short positions, minus the opening proceeds
+	Price             float64 `json:"price"`               // Closing price in PriceCurrency
+	PriceCurrency     string  `json:"price_currency"`      // Currency the price was stored in
+	PriceDate         string  `json:"price_date"`          // DD-MM-YYYY of the price used
+	MarketValueEUR    float64 `json:"market_value_eur"`    // Quantity * price in EUR; the cost when no price is stored
+	UnrealisedGainEUR float64 `json:"unrealised_gain_eur"` // MarketValueEUR - CostEUR
+	UnrealisedGainPct float64 `json:"unrealised_gain_pct"` // UnrealisedGainEUR as a percentage of |CostEUR|
+	Weight            float64 `json:"weight"`              // Percentage of the portfolio's total market value
+	PriceMissing      bool    `json:"price_missing"`       // No price stored on or before the valuation date
+}
+
+// HoldingsValuation is the response of the holdings valuation endpoint.
+type HoldingsValuation struct {
+	Date                   string              `json:"date"` // DD-MM-YYYY valuation date
+	Positions              []PositionValuation `json:"positions"`
+	TotalCostEUR           float64             `json:"total_cost_eur"`
+	TotalMarketValueEUR    float64             `json:"total_market_value_eur"`
+	TotalUnrealisedGainEUR float64             `json:"total_unrealised_gain_eur"`
+	MissingPrices          int                 `json:"missing_prices"` // Positions valued at cost for lack of a price
+}
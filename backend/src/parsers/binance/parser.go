@@ -0,0 +1,147 @@
+// backend/src/parsers/binance/parser.go
+package binance
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers/cryptotrade"
+)
+
+// quoteAssets are tried in order to split pairs of the older export, which has no asset suffixes on its values.
+var quoteAssets = []string{"FDUSD", "USDT", "BUSD", "USDC", "TUSD", "EUR", "BTC", "ETH", "BNB", "TRY", "GBP", "BRL", "DAI"}
+
+// BinanceParser implements the parsers.Parser interface for Binance spot trade history CSV exports.
+// Two layouts are accepted:
+//   - current: Date(UTC),Pair,Side,Price,Executed,Amount,Fee with the asset appended to each value ("0.5BTC");
+//   - older:   Date(UTC),Market,Type,Price,Amount,Total,Fee,Fee Coin with plain numbers.
+type BinanceParser struct{}
+
+// NewParser creates a new instance of the BinanceParser.
+func NewParser() *BinanceParser {
+	return &BinanceParser{}
+}
+
+// Parse reads a Binance trade history CSV and converts its rows into a slice of CanonicalTransaction.
+func (p *BinanceParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("binance parser: failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	_, hasExecuted := columns["executed"]
+	required := []string{"date(utc)", "market", "type", "price", "amount", "total", "fee", "fee coin"}
+	if hasExecuted {
+		required = []string{"date(utc)", "pair", "side", "price", "executed", "amount", "fee"}
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("binance parser: missing column '%s' in CSV header", name)
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("binance parser: failed to read all CSV records: %w", err)
+	}
+
+	var canonicalTxs []models.CanonicalTransaction
+	for _, record := range records {
+		if len(record) < len(header) {
+			continue
+		}
+		field := func(name string) string { return strings.TrimSpace(record[columns[name]]) }
+
+		date, err := time.Parse("2006-01-02 15:04:05", field("date(utc)"))
+		if err != nil {
+			log.Printf("Binance Parser: Skipping row due to invalid date: %s", field("date(utc)"))
+			continue
+		}
+
+		trade := cryptotrade.Trade{
+			Source:  "binance",
+			Time:    date,
+			Price:   parseNumber(field("price")),
+			RawText: "Binance|" + strings.Join(record, "|"),
+		}
+		var ok bool
+		if hasExecuted {
+			trade.Side = strings.ToUpper(field("side"))
+			ok = fillFromSuffixedValues(&trade, field("pair"), field("executed"), field("amount"), field("fee"))
+		} else {
+			trade.Side = strings.ToUpper(field("type"))
+			trade.Base, trade.Quote, ok = cryptotrade.SplitPair(field("market"), quoteAssets)
+			trade.Quantity = parseNumber(field("amount"))
+			trade.Total = parseNumber(field("total"))
+			trade.Fee = parseNumber(field("fee"))
+			trade.FeeAsset = strings.ToUpper(field("fee coin"))
+		}
+		if !ok {
+			log.Printf("Binance Parser: Skipping row with unrecognised pair: %s", strings.Join(record, ","))
+			continue
+		}
+		if trade.Side != "BUY" && trade.Side != "SELL" {
+			log.Printf("Binance Parser: Skipping row with unknown side: '%s'", trade.Side)
+			continue
+		}
+		canonicalTxs = append(canonicalTxs, cryptotrade.ToCanonical(trade)...)
+	}
+
+	cryptotrade.SortChronologically(canonicalTxs)
+	return canonicalTxs, nil
+}
+
+// fillFromSuffixedValues reads the current export, where the quote asset is the suffix of the Amount value
+// and the base asset is the rest of the pair.
+func fillFromSuffixedValues(trade *cryptotrade.Trade, pair, executed, amount, fee string) bool {
+	pair = strings.ToUpper(pair)
+	total, quote := splitValueAsset(amount)
+	if quote == "" || !strings.HasSuffix(pair, quote) || len(pair) == len(quote) {
+		return false
+	}
+	trade.Quote = quote
+	trade.Base = strings.TrimSuffix(pair, quote)
+	trade.Total = total
+	trade.Quantity = parseNumber(strings.TrimSuffix(strings.ToUpper(executed), trade.Base))
+
+	switch upperFee := strings.ToUpper(fee); {
+	case strings.HasSuffix(upperFee, trade.Base):
+		trade.Fee, trade.FeeAsset = parseNumber(strings.TrimSuffix(upperFee, trade.Base)), trade.Base
+	case strings.HasSuffix(upperFee, trade.Quote):
+		trade.Fee, trade.FeeAsset = parseNumber(strings.TrimSuffix(upperFee, trade.Quote)), trade.Quote
+	default:
+		trade.Fee, trade.FeeAsset = splitValueAsset(fee)
+	}
+	return true
+}
+
+// splitValueAsset splits "30.12345USDT" into 30.12345 and "USDT".
+func splitValueAsset(value string) (float64, string) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	i := 0
+	for i < len(value) && strings.ContainsRune("0123456789.,-", rune(value[i])) {
+		i++
+	}
+	return parseNumber(value[:i]), value[i:]
+}
+
+// parseNumber reads values such as "1,234.5", returning 0 for empty cells.
+func parseNumber(value string) float64 {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,72 @@
+package binance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []struct {
+			subType, buySell, product, currency string
+			quantity, amount, commission        float64
+		}
+	}{
+		{
+			name: "current layout, crypto quote and fee in BNB",
+			csv: "Date(UTC),Pair,Side,Price,Executed,Amount,Fee\n" +
+				"2024-03-01 10:00:00,BTCUSDT,BUY,\"60,000\",0.5BTC,\"30,000.00USDT\",0.001BNB\n",
+			want: []struct {
+				subType, buySell, product, currency string
+				quantity, amount, commission        float64
+			}{
+				{subType: "SWAP", buySell: "SELL", product: "USDT", currency: "USDT", quantity: 30000, amount: 30000},
+				{subType: "SWAP", buySell: "BUY", product: "BTC", currency: "USDT", quantity: 0.5, amount: -30000},
+				{subType: "FEE", buySell: "FEE", product: "BNB", currency: "BNB", quantity: 0.001},
+			},
+		},
+		{
+			name: "older layout, fiat quote with fee in the quote currency",
+			csv: "Date(UTC),Market,Type,Price,Amount,Total,Fee,Fee Coin\n" +
+				"2024-03-02 10:00:00,ETHEUR,SELL,3000,2,6000,6,EUR\n" +
+				"2024-03-01 10:00:00,ETHEUR,BUY,2900,2,5800,0.002,ETH\n",
+			want: []struct {
+				subType, buySell, product, currency string
+				quantity, amount, commission        float64
+			}{
+				{buySell: "BUY", product: "ETH", currency: "EUR", quantity: 2, amount: -5800},
+				{subType: "FEE", buySell: "FEE", product: "ETH", currency: "ETH", quantity: 0.002},
+				{buySell: "SELL", product: "ETH", currency: "EUR", quantity: 2, amount: 6000, commission: 6},
+			},
+		},
+		{
+			name: "unknown pair and side are skipped",
+			csv: "Date(UTC),Market,Type,Price,Amount,Total,Fee,Fee Coin\n" +
+				"2024-03-01 10:00:00,XYZ,BUY,1,1,1,0,EUR\n" +
+				"2024-03-01 10:00:00,ETHEUR,CONVERT,1,1,1,0,EUR\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, err := NewParser().Parse(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(txs) != len(tt.want) {
+				t.Fatalf("got %d transactions, want %d: %+v", len(txs), len(tt.want), txs)
+			}
+			for i, want := range tt.want {
+				tx := txs[i]
+				if tx.TransactionType != "CRYPTO" || tx.TransactionSubType != want.subType || tx.BuySell != want.buySell || tx.ProductName != want.product || tx.Currency != want.currency {
+					t.Errorf("row %d: got %s/%s/%s %s in %s, want %s/%s %s in %s", i, tx.TransactionType, tx.TransactionSubType, tx.BuySell, tx.ProductName, tx.Currency, want.subType, want.buySell, want.product, want.currency)
+				}
+				if tx.Quantity != want.quantity || tx.Amount != want.amount || tx.Commission != want.commission {
+					t.Errorf("row %d: quantity %v amount %v commission %v, want %v %v %v", i, tx.Quantity, tx.Amount, tx.Commission, want.quantity, want.amount, want.commission)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// backend/src/parsers/cryptotrade/trade.go
+package cryptotrade
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+// Sub types of "CRYPTO" transactions. Trades against fiat have no sub type.
+const (
+	SubTypeSwap = "SWAP" // One leg of a crypto-to-crypto trade; both legs share the OrderID
+	SubTypeFee  = "FEE"  // A fee paid in a crypto asset
+)
+
+// fiatCurrencies are the quote currencies treated as cash. Any other asset, stablecoins included, is crypto.
+var fiatCurrencies = map[string]bool{
+	"EUR": true, "USD": true, "GBP": true, "CHF": true, "JPY": true, "CAD": true, "AUD": true,
+	"PLN": true, "SEK": true, "NOK": true, "DKK": true, "CZK": true, "HUF": true, "TRY": true, "BRL": true,
+}
+
+// IsFiat reports whether an asset code is a fiat currency.
+func IsFiat(asset string) bool {
+	return fiatCurrencies[strings.ToUpper(asset)]
+}
+
+// Trade is one executed exchange trade of Base against Quote, as read from an exchange export.
+type Trade struct {
+	Source   string
+	Time     time.Time
+	OrderID  string // Derived from RawText when the export carries no trade id
+	Base     string
+	Quote    string
+	Side     string  // "BUY" (Base acquired) or "SELL" (Base disposed)
+	Quantity float64 // Base quantity
+	Price    float64 // Quote per Base
+	Total    float64 // Quote quantity
+	Fee      float64
+	FeeAsset string
+	RawText  string
+}
+
+// ToCanonical converts a trade into CRYPTO canonical transactions:
+//   - against fiat, one BUY/SELL row with the fiat amount signed like a stock trade and a fee in that fiat as Commission;
+//   - against another crypto asset, two SWAP rows sharing the OrderID, one disposing of the asset given up and one
+//     acquiring the asset received. Amount is the quote quantity, in the quote asset; the crypto processor values them.
+//
+// Fees charged in a crypto asset become a separate FEE row that consumes that asset.
+func ToCanonical(t Trade) []models.CanonicalTransaction {
+	if t.OrderID == "" {
+		sum := sha256.Sum256([]byte(t.RawText))
+		t.OrderID = hex.EncodeToString(sum[:8])
+	}
+	base := models.CanonicalTransaction{
+		Source:          t.Source,
+		TransactionDate: t.Time,
+		OrderID:         t.OrderID,
+		TransactionType: "CRYPTO",
+	}
+
+	var txs []models.CanonicalTransaction
+	feeInQuoteFiat := IsFiat(t.Quote) && strings.EqualFold(t.FeeAsset, t.Quote)
+
+	if IsFiat(t.Quote) {
+		tx := base
+		tx.ProductName = t.Base
+		tx.Quantity = t.Quantity
+		tx.Price = t.Price
+		tx.Currency = t.Quote
+		tx.BuySell = t.Side
+		tx.SourceAmount = t.Total
+		tx.Amount = t.Total
+		if t.Side == "BUY" {
+			tx.Amount = -t.Total
+		}
+		if feeInQuoteFiat {
+			tx.Commission = t.Fee
+		}
+		tx.RawText = t.RawText
+		txs = append(txs, tx)
+	} else {
+		received, given := t.Base, t.Quote
+		receivedQty, givenQty := t.Quantity, t.Total
+		if t.Side == "SELL" {
+			received, given = t.Quote, t.Base
+			receivedQty, givenQty = t.Total, t.Quantity
+		}
+
+		out := base
+		out.TransactionSubType = SubTypeSwap
+		out.BuySell = "SELL"
+		out.ProductName = given
+		out.Quantity = givenQty
+		out.Currency = t.Quote
+		out.SourceAmount = t.Total
+		out.Amount = t.Total
+		out.RawText = t.RawText + "|" + given
+
+		in := base
+		in.TransactionSubType = SubTypeSwap
+		in.BuySell = "BUY"
+		in.ProductName = received
+		in.Quantity = receivedQty
+		in.Currency = t.Quote
+		in.SourceAmount = t.Total
+		in.Amount = -t.Total
+		in.RawText = t.RawText + "|" + received
+
+		// Prices are in the quote asset, so the quote leg is priced at 1.
+		out.Price, in.Price = 1, 1
+		if given == t.Base {
+			out.Price = t.Price
+		} else {
+			in.Price = t.Price
+		}
+		txs = append(txs, out, in)
+	}
+
+	if t.Fee > 0 && t.FeeAsset != "" && !feeInQuoteFiat && !IsFiat(t.FeeAsset) {
+		fee := base
+		fee.TransactionSubType = SubTypeFee
+		fee.BuySell = "FEE"
+		fee.ProductName = strings.ToUpper(t.FeeAsset)
+		fee.Quantity = t.Fee
+		fee.Currency = strings.ToUpper(t.FeeAsset)
+		fee.RawText = t.RawText + "|FEE"
+		txs = append(txs, fee)
+	}
+	return txs
+}
+
+// SortChronologically orders transactions by time, keeping the export's order for equal timestamps.
+// Exchanges often export newest first, while lots must be built oldest first.
+func SortChronologically(txs []models.CanonicalTransaction) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].TransactionDate.Before(txs[j].TransactionDate)
+	})
+}
+
+// SplitPair splits a concatenated trading pair ("BTCUSDT", "XXBTZEUR") into base and quote using the first
+// of the candidate quote codes that leaves a non-empty base. A "/" separated pair is split directly.
+func SplitPair(pair string, quotes []string) (string, string, bool) {
+	pair = strings.ToUpper(strings.TrimSpace(pair))
+	if base, quote, ok := strings.Cut(pair, "/"); ok {
+		return base, quote, base != "" && quote != ""
+	}
+	for _, quote := range quotes {
+		if strings.HasSuffix(pair, quote) && len(pair) > len(quote) {
+			return strings.TrimSuffix(pair, quote), quote, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,337 @@
+// backend/src/parsers/custom/parser.go
+package custom
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+// SourcePrefix is prepended to the profile name to form the source of imported rows ("custom:My Broker").
+const SourcePrefix = "custom:"
+
+// Sign conventions for the amount column.
+const (
+	SignAsIs     = "as_is"    // Amounts are already signed from the account's point of view
+	SignInverted = "inverted" // Amounts are signed from the broker's point of view (buys positive)
+	SignByType   = "by_type"  // Amounts are unsigned; the sign follows the matched transaction kind
+)
+
+// MaxPatternLength limits user-supplied regular expressions.
+const MaxPatternLength = 256
+
+// knownColumns are the canonical fields a profile may map.
+var knownColumns = map[string]bool{
+	"date": true, "product_name": true, "isin": true, "quantity": true, "price": true, "amount": true,
+	"currency": true, "commission": true, "order_id": true, "type": true, "description": true,
+}
+
+// rowKind describes how a matched pattern is classified and which sign its amount carries.
+type rowKind struct {
+	transactionType string
+	subType         string
+	buySell         string
+	sign            float64
+}
+
+var rowKinds = map[string]rowKind{
+	"BUY":        {transactionType: "STOCK", buySell: "BUY", sign: -1},
+	"SELL":       {transactionType: "STOCK", buySell: "SELL", sign: 1},
+	"DIVIDEND":   {transactionType: "DIVIDEND", sign: 1},
+	"TAX":        {transactionType: "DIVIDEND", subType: "TAX", sign: -1},
+	"FEE":        {transactionType: "FEE", sign: -1},
+	"DEPOSIT":    {transactionType: "CASH", subType: "DEPOSIT", sign: 1},
+	"WITHDRAWAL": {transactionType: "CASH", subType: "WITHDRAWAL", sign: -1},
+	"INTEREST":   {transactionType: "INTEREST", sign: 1},
+}
+
+// patternOrder is the order in which patterns are tried, so that "dividend tax" is a TAX row before it is a DIVIDEND.
+var patternOrder = []string{"TAX", "FEE", "DIVIDEND", "INTEREST", "BUY", "SELL", "DEPOSIT", "WITHDRAWAL"}
+
+// dateTokens converts the user-friendly date format tokens into a Go layout.
+var dateTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02", "HH", "15", "mm", "04", "ss", "05")
+
+type compiledPattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// CustomParser implements the parsers.Parser interface for any CSV described by a models.ParserProfile.
+type CustomParser struct {
+	profile    models.ParserProfile
+	source     string
+	delimiter  rune
+	dateLayout string
+	patterns   []compiledPattern
+}
+
+// NewParser validates the profile and builds a parser from it.
+func NewParser(profile models.ParserProfile) (*CustomParser, error) {
+	if err := ValidateProfile(profile); err != nil {
+		return nil, err
+	}
+	p := &CustomParser{
+		profile:    profile,
+		source:     SourcePrefix + strings.TrimSpace(profile.Name),
+		delimiter:  ',',
+		dateLayout: dateTokens.Replace(profile.DateFormat),
+	}
+	if profile.Delimiter != "" {
+		p.delimiter, _ = utf8.DecodeRuneInString(profile.Delimiter)
+	}
+	for _, kind := range patternOrder {
+		if expr, ok := profile.Patterns[kind]; ok && expr != "" {
+			p.patterns = append(p.patterns, compiledPattern{kind: kind, re: regexp.MustCompile("(?i)" + expr)})
+		}
+	}
+	return p, nil
+}
+
+// ValidateProfile checks that a profile is complete enough to parse a file.
+func ValidateProfile(profile models.ParserProfile) error {
+	name := strings.TrimSpace(profile.Name)
+	if err := validation.ValidateStringNotEmpty(name, "Profile name"); err != nil {
+		return err
+	}
+	if err := validation.ValidateStringMaxLength(name, 100, "Profile name"); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(profile.Delimiter) > 1 {
+		return fmt.Errorf("%w: delimiter must be a single character", validation.ErrValidationFailed)
+	}
+	if profile.Delimiter == "\"" || profile.Delimiter == "\n" || profile.Delimiter == "\r" {
+		return fmt.Errorf("%w: delimiter '%s' is not allowed", validation.ErrValidationFailed, profile.Delimiter)
+	}
+	if profile.DecimalSeparator != "" && profile.DecimalSeparator != "." && profile.DecimalSeparator != "," {
+		return fmt.Errorf("%w: decimal separator must be '.' or ','", validation.ErrValidationFailed)
+	}
+	switch profile.SignConvention {
+	case "", SignAsIs, SignInverted, SignByType:
+	default:
+		return fmt.Errorf("%w: sign convention must be '%s', '%s' or '%s'", validation.ErrValidationFailed, SignAsIs, SignInverted, SignByType)
+	}
+	if err := validation.ValidateStringNotEmpty(profile.DateFormat, "Date format"); err != nil {
+		return err
+	}
+	if profile.DefaultCurrency != "" {
+		if err := validation.ValidateCurrencyCode(strings.ToUpper(profile.DefaultCurrency)); err != nil {
+			return err
+		}
+	}
+
+	for field, column := range profile.Columns {
+		if !knownColumns[field] {
+			return fmt.Errorf("%w: unknown column mapping '%s'", validation.ErrValidationFailed, field)
+		}
+		column = strings.TrimSpace(column)
+		if column == "" {
+			return fmt.Errorf("%w: column mapping for '%s' is empty", validation.ErrValidationFailed, field)
+		}
+		if idx, err := strconv.Atoi(column); err == nil {
+			if idx < 0 {
+				return fmt.Errorf("%w: column index for '%s' cannot be negative", validation.ErrValidationFailed, field)
+			}
+		} else if !profile.HasHeader {
+			return fmt.Errorf("%w: column '%s' is mapped by name but the profile has no header row", validation.ErrValidationFailed, field)
+		}
+	}
+	if _, ok := profile.Columns["date"]; !ok {
+		return fmt.Errorf("%w: a 'date' column mapping is required", validation.ErrValidationFailed)
+	}
+	_, hasAmount := profile.Columns["amount"]
+	_, hasQuantity := profile.Columns["quantity"]
+	_, hasPrice := profile.Columns["price"]
+	if !hasAmount && !(hasQuantity && hasPrice) {
+		return fmt.Errorf("%w: an 'amount' column, or both 'quantity' and 'price', must be mapped", validation.ErrValidationFailed)
+	}
+	_, hasType := profile.Columns["type"]
+	_, hasDescription := profile.Columns["description"]
+	if !hasType && !hasDescription {
+		return fmt.Errorf("%w: a 'type' or 'description' column is required to classify rows", validation.ErrValidationFailed)
+	}
+
+	if len(profile.Patterns) == 0 {
+		return fmt.Errorf("%w: at least one transaction pattern is required", validation.ErrValidationFailed)
+	}
+	for kind, expr := range profile.Patterns {
+		if _, ok := rowKinds[kind]; !ok {
+			return fmt.Errorf("%w: unknown pattern '%s'", validation.ErrValidationFailed, kind)
+		}
+		if err := validation.ValidateStringMaxLength(expr, MaxPatternLength, "Pattern "+kind); err != nil {
+			return err
+		}
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("%w: pattern '%s' is not a valid regular expression: %v", validation.ErrValidationFailed, kind, err)
+		}
+	}
+	return nil
+}
+
+// Parse reads a CSV described by the profile and converts its rows into a slice of CanonicalTransaction.
+// Rows whose type/description text matches none of the patterns are skipped.
+func (p *CustomParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("custom parser: failed to read file: %w", err)
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.Comma = p.delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("custom parser: failed to read CSV: %w", err)
+	}
+
+	var header []string
+	if p.profile.HasHeader {
+		if len(records) == 0 {
+			return nil, fmt.Errorf("custom parser: file has no header row")
+		}
+		header, records = records[0], records[1:]
+	}
+	columns, err := p.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var canonicalTxs []models.CanonicalTransaction
+	for lineNo, record := range records {
+		field := func(name string) string {
+			idx, ok := columns[name]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		text := strings.TrimSpace(field("type") + " " + field("description"))
+		kind, ok := p.classify(text)
+		if !ok {
+			log.Printf("Custom Parser (%s): Skipping unmatched row %d: '%s'", p.profile.Name, lineNo+1, text)
+			continue
+		}
+		date, err := time.Parse(p.dateLayout, field("date"))
+		if err != nil {
+			log.Printf("Custom Parser (%s): Skipping row %d due to invalid date: %s", p.profile.Name, lineNo+1, field("date"))
+			continue
+		}
+
+		quantity := math.Abs(p.parseNumber(field("quantity")))
+		price := math.Abs(p.parseNumber(field("price")))
+		sourceAmount := p.parseNumber(field("amount"))
+		amount := p.signedAmount(kind, sourceAmount)
+		if amount == 0 && kind.transactionType == "STOCK" {
+			amount = kind.sign * quantity * price
+		}
+
+		currency := strings.ToUpper(field("currency"))
+		if currency == "" {
+			currency = strings.ToUpper(p.profile.DefaultCurrency)
+		}
+		productName := field("product_name")
+		if productName == "" {
+			productName = field("description")
+		}
+
+		canonicalTxs = append(canonicalTxs, models.CanonicalTransaction{
+			Source:             p.source,
+			TransactionDate:    date,
+			ProductName:        productName,
+			ISIN:               strings.ToUpper(field("isin")),
+			Quantity:           quantity,
+			Price:              price,
+			Commission:         math.Abs(p.parseNumber(field("commission"))),
+			Currency:           currency,
+			OrderID:            field("order_id"),
+			RawText:            p.source + "|" + strings.Join(record, "|"),
+			SourceAmount:       sourceAmount,
+			Amount:             amount,
+			TransactionType:    kind.transactionType,
+			TransactionSubType: kind.subType,
+			BuySell:            kind.buySell,
+		})
+	}
+	return canonicalTxs, nil
+}
+
+// resolveColumns turns the profile's column mapping into indices, looking header names up case-insensitively.
+func (p *CustomParser) resolveColumns(header []string) (map[string]int, error) {
+	byName := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, seen := byName[name]; !seen {
+			byName[name] = i
+		}
+	}
+	columns := make(map[string]int, len(p.profile.Columns))
+	for field, column := range p.profile.Columns {
+		column = strings.TrimSpace(column)
+		if idx, err := strconv.Atoi(column); err == nil {
+			columns[field] = idx
+			continue
+		}
+		idx, ok := byName[strings.ToLower(column)]
+		if !ok {
+			return nil, fmt.Errorf("custom parser: column '%s' (mapped to %s) not found in header", column, field)
+		}
+		columns[field] = idx
+	}
+	return columns, nil
+}
+
+func (p *CustomParser) classify(text string) (rowKind, bool) {
+	for _, pattern := range p.patterns {
+		if pattern.re.MatchString(text) {
+			return rowKinds[pattern.kind], true
+		}
+	}
+	return rowKind{}, false
+}
+
+func (p *CustomParser) signedAmount(kind rowKind, amount float64) float64 {
+	switch p.profile.SignConvention {
+	case SignInverted:
+		return -amount
+	case SignByType:
+		return kind.sign * math.Abs(amount)
+	default:
+		return amount
+	}
+}
+
+// parseNumber reads a numeric cell using the profile's decimal separator, ignoring thousands separators,
+// spaces and currency symbols. Empty or non-numeric cells return 0.
+func (p *CustomParser) parseNumber(value string) float64 {
+	thousands, decimal := ",", "."
+	if p.profile.DecimalSeparator == "," {
+		thousands, decimal = ".", ","
+	}
+	value = strings.ReplaceAll(value, thousands, "")
+	value = strings.ReplaceAll(value, decimal, ".")
+	var b strings.Builder
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	v, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
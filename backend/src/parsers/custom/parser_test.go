@@ -0,0 +1,215 @@
+package custom
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+func baseProfile() models.ParserProfile {
+	return models.ParserProfile{
+		Name:       "My Broker",
+		HasHeader:  true,
+		DateFormat: "DD-MM-YYYY",
+		Columns: map[string]string{
+			"date": "Date", "type": "Type", "product_name": "Product", "amount": "Amount", "currency": "Currency",
+		},
+		Patterns: map[string]string{"BUY": "^buy", "SELL": "^sell", "DIVIDEND": "dividend", "TAX": "tax"},
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*models.ParserProfile)
+		wantErr bool
+	}{
+		{name: "valid", mutate: func(*models.ParserProfile) {}},
+		{name: "index columns without header", mutate: func(p *models.ParserProfile) {
+			p.HasHeader = false
+			p.Columns = map[string]string{"date": "0", "type": "1", "amount": "2"}
+		}},
+		{name: "quantity and price instead of amount", mutate: func(p *models.ParserProfile) {
+			delete(p.Columns, "amount")
+			p.Columns["quantity"], p.Columns["price"] = "Qty", "Price"
+		}},
+		{name: "empty name", mutate: func(p *models.ParserProfile) { p.Name = "  " }, wantErr: true},
+		{name: "name too long", mutate: func(p *models.ParserProfile) { p.Name = strings.Repeat("x", 101) }, wantErr: true},
+		{name: "multi-character delimiter", mutate: func(p *models.ParserProfile) { p.Delimiter = ";;" }, wantErr: true},
+		{name: "quote delimiter", mutate: func(p *models.ParserProfile) { p.Delimiter = "\"" }, wantErr: true},
+		{name: "unknown decimal separator", mutate: func(p *models.ParserProfile) { p.DecimalSeparator = "'" }, wantErr: true},
+		{name: "unknown sign convention", mutate: func(p *models.ParserProfile) { p.SignConvention = "flipped" }, wantErr: true},
+		{name: "missing date format", mutate: func(p *models.ParserProfile) { p.DateFormat = "" }, wantErr: true},
+		{name: "invalid default currency", mutate: func(p *models.ParserProfile) { p.DefaultCurrency = "EURO" }, wantErr: true},
+		{name: "unknown column", mutate: func(p *models.ParserProfile) { p.Columns["broker"] = "Broker" }, wantErr: true},
+		{name: "empty column mapping", mutate: func(p *models.ParserProfile) { p.Columns["product_name"] = " " }, wantErr: true},
+		{name: "negative column index", mutate: func(p *models.ParserProfile) { p.Columns["product_name"] = "-1" }, wantErr: true},
+		{name: "column name without header", mutate: func(p *models.ParserProfile) { p.HasHeader = false }, wantErr: true},
+		{name: "missing date column", mutate: func(p *models.ParserProfile) { delete(p.Columns, "date") }, wantErr: true},
+		{name: "quantity without price or amount", mutate: func(p *models.ParserProfile) {
+			delete(p.Columns, "amount")
+			p.Columns["quantity"] = "Qty"
+		}, wantErr: true},
+		{name: "nothing to classify rows", mutate: func(p *models.ParserProfile) { delete(p.Columns, "type") }, wantErr: true},
+		{name: "no patterns", mutate: func(p *models.ParserProfile) { p.Patterns = nil }, wantErr: true},
+		{name: "unknown pattern kind", mutate: func(p *models.ParserProfile) { p.Patterns["SPLIT"] = "split" }, wantErr: true},
+		{name: "invalid regular expression", mutate: func(p *models.ParserProfile) { p.Patterns["FEE"] = "fee(" }, wantErr: true},
+		{name: "pattern too long", mutate: func(p *models.ParserProfile) { p.Patterns["FEE"] = strings.Repeat("a", MaxPatternLength+1) }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := baseProfile()
+			tt.mutate(&profile)
+			err := ValidateProfile(profile)
+			if tt.wantErr {
+				if !errors.Is(err, validation.ErrValidationFailed) {
+					t.Fatalf("expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseDateTokens(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		want   time.Time
+	}{
+		{format: "DD-MM-YYYY", value: "15-03-2024", want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{format: "YYYY-MM-DD HH:mm:ss", value: "2024-03-15 14:05:09", want: time.Date(2024, 3, 15, 14, 5, 9, 0, time.UTC)},
+		{format: "MM/DD/YY", value: "03/15/24", want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{format: "02.01.2006", value: "15.03.2024", want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			profile := baseProfile()
+			profile.DateFormat = tt.format
+			parser, err := NewParser(profile)
+			if err != nil {
+				t.Fatalf("NewParser: %v", err)
+			}
+			txs, err := parser.Parse(strings.NewReader("Date,Type,Product,Amount,Currency\n" + tt.value + ",Dividend,ACME,1,EUR\n"))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(txs) != 1 || !txs[0].TransactionDate.Equal(tt.want) {
+				t.Errorf("got %+v, want date %v", txs, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSignConventions(t *testing.T) {
+	report := "Date;Type;Product;Qty;Price;Amount\n" +
+		"15-03-2024;Buy;ACME;10;12,50;%s\n" +
+		"16-03-2024;Dividend tax;ACME;;;%s\n"
+
+	tests := []struct {
+		convention string
+		buyCell    string
+		taxCell    string
+		wantBuy    float64
+		wantTax    float64
+	}{
+		{convention: SignAsIs, buyCell: "-1.250,00", taxCell: "-0,75", wantBuy: -1250, wantTax: -0.75},
+		{convention: "", buyCell: "-1.250,00", taxCell: "-0,75", wantBuy: -1250, wantTax: -0.75},
+		{convention: SignInverted, buyCell: "1.250,00", taxCell: "0,75", wantBuy: -1250, wantTax: -0.75},
+		{convention: SignByType, buyCell: "1.250,00", taxCell: "0,75", wantBuy: -1250, wantTax: -0.75},
+		{convention: SignByType, buyCell: "", taxCell: "0,75", wantBuy: -125, wantTax: -0.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.convention+"/"+tt.buyCell, func(t *testing.T) {
+			profile := baseProfile()
+			profile.Delimiter = ";"
+			profile.DecimalSeparator = ","
+			profile.SignConvention = tt.convention
+			profile.DefaultCurrency = "eur"
+			delete(profile.Columns, "currency")
+			profile.Columns["quantity"], profile.Columns["price"] = "Qty", "Price"
+			parser, err := NewParser(profile)
+			if err != nil {
+				t.Fatalf("NewParser: %v", err)
+			}
+			txs, err := parser.Parse(strings.NewReader(strings.Replace(strings.Replace(report, "%s", tt.buyCell, 1), "%s", tt.taxCell, 1)))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(txs) != 2 {
+				t.Fatalf("got %d transactions, want 2: %+v", len(txs), txs)
+			}
+			if math.Abs(txs[0].Amount-tt.wantBuy) > 1e-9 || math.Abs(txs[1].Amount-tt.wantTax) > 1e-9 {
+				t.Errorf("amounts %v / %v, want %v / %v", txs[0].Amount, txs[1].Amount, tt.wantBuy, tt.wantTax)
+			}
+			if txs[0].Currency != "EUR" || txs[0].Source != "custom:My Broker" {
+				t.Errorf("buy currency %s source %s, want the default currency and the profile source", txs[0].Currency, txs[0].Source)
+			}
+		})
+	}
+}
+
+func TestParsePatternOrder(t *testing.T) {
+	profile := baseProfile()
+	profile.Patterns = map[string]string{
+		"DIVIDEND": "dividend",
+		"TAX":      "withholding|dividend tax",
+		"FEE":      "fee",
+		"INTEREST": "interest",
+		"DEPOSIT":  "transfer",
+	}
+	report := "Date,Type,Product,Amount,Currency\n" +
+		"15-03-2024,Dividend,ACME,10,USD\n" +
+		"15-03-2024,Dividend tax,ACME,-1.5,USD\n" +
+		"16-03-2024,Dividend fee,ACME,-0.5,USD\n" +
+		"17-03-2024,Interest,,0.2,EUR\n" +
+		"18-03-2024,Transfer,,100,EUR\n" +
+		"19-03-2024,Stock split,ACME,0,USD\n" +
+		"\n"
+
+	parser, err := NewParser(profile)
+	if err != nil {
+		t.Fatalf("NewParser: %v", err)
+	}
+	txs, err := parser.Parse(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := []struct{ txType, subType string }{
+		{"DIVIDEND", ""},
+		{"DIVIDEND", "TAX"},
+		{"FEE", ""},
+		{"INTEREST", ""},
+		{"CASH", "DEPOSIT"},
+	}
+	if len(txs) != len(want) {
+		t.Fatalf("got %d transactions, want %d (unmatched and blank rows skipped): %+v", len(txs), len(want), txs)
+	}
+	for i, w := range want {
+		if txs[i].TransactionType != w.txType || txs[i].TransactionSubType != w.subType {
+			t.Errorf("row %d (%s): got %s/%s, want %s/%s", i, txs[i].RawText, txs[i].TransactionType, txs[i].TransactionSubType, w.txType, w.subType)
+		}
+	}
+}
+
+func TestParseMissingHeaderColumn(t *testing.T) {
+	parser, err := NewParser(baseProfile())
+	if err != nil {
+		t.Fatalf("NewParser: %v", err)
+	}
+	if _, err := parser.Parse(strings.NewReader("Date,Kind,Amount\n15-03-2024,Buy,-10\n")); err == nil {
+		t.Fatal("expected an error when a mapped column is missing from the header")
+	}
+}
@@ -13,8 +13,12 @@ import (
 	"time"
 
 	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
 )
 
+// interestCountryPrefix is the country of flatex Bank, which pays the interest on DeGiro cash balances.
+const interestCountryPrefix = "DE"
+
 // RawTransaction holds the direct string values from a single row of a DeGiro CSV.
 // Added RawLine to store the full, unprocessed line.
 type RawTransaction struct {
@@ -23,7 +27,10 @@ type RawTransaction struct {
 }
 
 // DeGiroParser implements the parsers.Parser interface for DeGiro files.
-type DeGiroParser struct{}
+type DeGiroParser struct {
+	// IncludeFXConversions keeps the "Levantamento de divisa"/"Crédito de divisa" rows as FX transactions.
+	IncludeFXConversions bool
+}
 
 // NewParser creates a new instance of the DeGiroParser.
 func NewParser() *DeGiroParser {
@@ -76,10 +83,20 @@ func (p *DeGiroParser) Parse(file io.Reader) ([]models.CanonicalTransaction, err
 			log.Printf("DeGiro Parser: Skipping unknown transaction type for description: '%s'", raw.Description)
 			continue
 		}
+		if txType == "FX" && !p.IncludeFXConversions {
+			continue
+		}
 
 		sourceAmt, _ := strconv.ParseFloat(raw.Amount, 64)
 		finalAmount := sourceAmt // For DeGiro, the sign is authoritative
 
+		// Each currency conversion leg is a row of its own; the moved amount is the quantity
+		// and the rate column (when present) is kept as the price.
+		if txType == "FX" {
+			quantity = math.Abs(sourceAmt)
+			price, _ = strconv.ParseFloat(raw.ExchangeRate, 64)
+		}
+
 		// Enforce sign for specific types to be safe
 		if txType == "FEE" || (txType == "DIVIDEND" && subType == "TAX") {
 			finalAmount = -math.Abs(sourceAmt)
@@ -105,12 +122,34 @@ func (p *DeGiroParser) Parse(file io.Reader) ([]models.CanonicalTransaction, err
 			BuySell:            buySell,
 			Commission:         commission,
 		}
+		if txType == "INTEREST" {
+			tx.CountryCode = utils.GetCountryCodeString(interestCountryPrefix)
+		}
+		// DeGiro reports no contract size; the transaction processor applies the standard multiplier.
+		if contract, ok := utils.ParseOptionProductName(productName); ok && txType == "OPTION" {
+			tx.Underlying = contract.Underlying
+			tx.OptionRight = contract.Right
+			tx.Strike = contract.Strike
+			tx.Expiry = contract.Expiry
+		}
 		canonicalTxs = append(canonicalTxs, tx)
 	}
 
 	return canonicalTxs, nil
 }
 
+// optionProductRe matches DeGiro option product names such as "FLW P31.00 18MAR22".
+var optionProductRe = regexp.MustCompile(`\s+[CP]\d+(\.\d+)?\s+\d{2}[A-Z]{3}\d{2}$`)
+
+// degiroInterestDescriptions are the lower-cased descriptions DeGiro gives interest paid or charged on the
+// flatex cash account. Other rows merely mentioning interest, such as bond coupons, are not cash interest.
+var degiroInterestDescriptions = map[string]bool{
+	"flatex interest":        true,
+	"flatex interest income": true,
+	"juros":                  true,
+	"juros flatex":           true,
+}
+
 // classifyDeGiroTransaction remains the same as before.
 func classifyDeGiroTransaction(raw RawTransaction) (txType, subType, buySell, productName string, quantity, price float64) {
 	desc := strings.TrimSpace(strings.ReplaceAll(raw.Description, "\u00A0", " "))
@@ -127,8 +166,26 @@ func classifyDeGiroTransaction(raw RawTransaction) (txType, subType, buySell, pr
 	if strings.EqualFold(lowerDesc, "depósito") || strings.Contains(lowerDesc, "flatex deposit") {
 		return "CASH", "DEPOSIT", "", "Cash Deposit", 0, 0
 	}
-	if strings.Contains(lowerDesc, "comissões de transação") || strings.Contains(lowerDesc, "custo de conectividade") {
-		return "FEE", "", "", "Brokerage Fee", 0, 0
+	if strings.Contains(lowerDesc, "comissões de transação") {
+		return "FEE", "COMMISSION", "", "Brokerage Fee", 0, 0
+	}
+	if strings.Contains(lowerDesc, "custo de conectividade") {
+		return "FEE", "CONNECTIVITY", "", "Brokerage Fee", 0, 0
+	}
+	if strings.Contains(lowerDesc, "autofx") || strings.Contains(lowerDesc, "auto fx") {
+		return "FEE", "FX", "", "AutoFX Fee", 0, 0
+	}
+	if strings.Contains(lowerDesc, "custódia") || strings.Contains(lowerDesc, "custody") {
+		return "FEE", "CUSTODY", "", "Custody Fee", 0, 0
+	}
+	if strings.Contains(lowerDesc, "crédito de divisa") {
+		return "FX", "CONVERSION", "BUY", "Currency Conversion", 0, 0
+	}
+	if strings.Contains(lowerDesc, "levantamento de divisa") {
+		return "FX", "CONVERSION", "SELL", "Currency Conversion", 0, 0
+	}
+	if degiroInterestDescriptions[lowerDesc] {
+		return "INTEREST", "", "", "Flatex Interest", 0, 0
 	}
 	if strings.Contains(lowerDesc, "mudança de produto") {
 		return "PRODUCT_CHANGE", "", "", "Product Change", 0, 0
@@ -159,8 +216,7 @@ func classifyDeGiroTransaction(raw RawTransaction) (txType, subType, buySell, pr
 	price, _ = strconv.ParseFloat(priceStr, 64)
 
 	// Differentiate between Stock and Option
-	optionPatternRe := regexp.MustCompile(`\s+[CP]\d+(\.\d+)?\s+\d{2}[A-Z]{3}\d{2}$`)
-	if optionPatternRe.MatchString(productName) {
+	if optionProductRe.MatchString(productName) {
 		txType = "OPTION"
 		if strings.Contains(productName, " C") {
 			subType = "CALL"
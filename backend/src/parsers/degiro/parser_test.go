@@ -0,0 +1,46 @@
+package degiro
+
+import "testing"
+
+func TestClassifyDeGiroFees(t *testing.T) {
+	tests := []struct {
+		description string
+		wantSubType string
+	}{
+		{"Comissões de transação DEGIRO e/ou taxas de terceiros", "COMMISSION"},
+		{"Custo de Conectividade DEGIRO 2024 (Euronext Amsterdam)", "CONNECTIVITY"},
+		{"Custo de câmbio AutoFX", "FX"},
+		{"DEGIRO AutoFX Fee", "FX"},
+		{"Comissão de custódia", "CUSTODY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			txType, subType, _, _, _, _ := classifyDeGiroTransaction(RawTransaction{Description: tt.description})
+			if txType != "FEE" || subType != tt.wantSubType {
+				t.Errorf("classifyDeGiroTransaction(%q) = %s/%s, want FEE/%s", tt.description, txType, subType, tt.wantSubType)
+			}
+		})
+	}
+}
+
+func TestClassifyDeGiroInterest(t *testing.T) {
+	tests := []struct {
+		description string
+		wantType    string
+	}{
+		{"Flatex Interest", "INTEREST"},
+		{"Flatex Interest Income", "INTEREST"},
+		{"Juros", "INTEREST"},
+		{"Flatex Interest Income Adjustment", "UNKNOWN"},
+		{"Juros de obrigação US912828XX00", "UNKNOWN"},
+		{"Dividendo", "DIVIDEND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			txType, _, _, _, _, _ := classifyDeGiroTransaction(RawTransaction{Description: tt.description})
+			if txType != tt.wantType {
+				t.Errorf("classifyDeGiroTransaction(%q) type = %s, want %s", tt.description, txType, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package degiro
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+// ParsePositions reads a DeGiro Portfolio.csv export.
+// Expected columns: Produto, Símbolo/ISIN, Quantidade, Preço de fecho, Valor local (currency, value), Valor em EUR.
+// DeGiro does not report a cost basis in this export, so CostBasis is left at zero.
+func (p *DeGiroParser) ParsePositions(file io.Reader) ([]models.BrokerPosition, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("degiro parser: failed to read portfolio header: %w", err)
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("degiro parser: failed to read portfolio records: %w", err)
+	}
+
+	var positions []models.BrokerPosition
+	for _, record := range records {
+		if len(record) < 5 {
+			continue
+		}
+		productName := strings.TrimSpace(record[0])
+		isin := strings.TrimSpace(record[1])
+		quantityStr := strings.TrimSpace(record[2])
+		// Cash and money-market lines have no ISIN and no quantity.
+		if quantityStr == "" {
+			continue
+		}
+		quantity, err := parseLocalizedFloat(quantityStr)
+		if err != nil {
+			log.Printf("DeGiro Parser: Skipping portfolio row with invalid quantity '%s' (%s)", quantityStr, productName)
+			continue
+		}
+
+		assetType := "STOCK"
+		if isin == "" {
+			if !optionProductRe.MatchString(productName) {
+				log.Printf("DeGiro Parser: Skipping portfolio row without ISIN: '%s'", productName)
+				continue
+			}
+			assetType = "OPTION"
+		}
+
+		positions = append(positions, models.BrokerPosition{
+			Source:      "degiro",
+			AssetType:   assetType,
+			ISIN:        isin,
+			ProductName: productName,
+			Quantity:    quantity,
+			Currency:    strings.TrimSpace(record[4]),
+		})
+	}
+	return positions, nil
+}
+
+// parseLocalizedFloat accepts both "1234.56" and the Portuguese "1.234,56" notation.
+func parseLocalizedFloat(s string) (float64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	if strings.Contains(s, ",") {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,34 @@
+package degiro
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+func TestParsePositions(t *testing.T) {
+	csv := strings.Join([]string{
+		"Produto,Símbolo/ISIN,Quantidade,Preço de fecho,Valor local,,Valor em EUR",
+		"CASH & CASH FUND & FTX CASH (EUR),,,,EUR,\"12,50\",\"12,50\"",
+		"APPLE INC,US0378331005,10,\"180,00\",USD,\"1.800,00\",\"1.650,00\"",
+		"VANGUARD FTSE ALL-WORLD,IE00BK5BQT80,\"2,5\",\"110,00\",EUR,\"275,00\",\"275,00\"",
+		"FLW P31.00 18DEC26,,-1,\"1,20\",EUR,\"-120,00\",\"-120,00\"",
+		"UNKNOWN PRODUCT,,3,\"1,00\",EUR,\"3,00\",\"3,00\"",
+		"BROKEN,NL0000000001,abc,\"1,00\",EUR,\"3,00\",\"3,00\"",
+	}, "\n")
+
+	positions, err := NewParser().ParsePositions(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ParsePositions: %v", err)
+	}
+	want := []models.BrokerPosition{
+		{Source: "degiro", AssetType: "STOCK", ISIN: "US0378331005", ProductName: "APPLE INC", Quantity: 10, Currency: "USD"},
+		{Source: "degiro", AssetType: "STOCK", ISIN: "IE00BK5BQT80", ProductName: "VANGUARD FTSE ALL-WORLD", Quantity: 2.5, Currency: "EUR"},
+		{Source: "degiro", AssetType: "OPTION", ProductName: "FLW P31.00 18DEC26", Quantity: -1, Currency: "EUR"},
+	}
+	if !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %+v, want %+v", positions, want)
+	}
+}
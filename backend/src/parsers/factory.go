@@ -4,17 +4,54 @@ package parsers
 import (
 	"fmt"
 
+	"github.com/username/taxfolio/backend/src/parsers/binance"
+	"github.com/username/taxfolio/backend/src/parsers/custom"
 	"github.com/username/taxfolio/backend/src/parsers/degiro"
+	"github.com/username/taxfolio/backend/src/parsers/freedom24"
 	"github.com/username/taxfolio/backend/src/parsers/ibkr"
+	"github.com/username/taxfolio/backend/src/parsers/kraken"
+	"github.com/username/taxfolio/backend/src/parsers/revolut"
+	"github.com/username/taxfolio/backend/src/parsers/xtb"
 )
 
-func GetParser(source string) (Parser, error) {
+func GetParser(source string, opts Options) (Parser, error) {
+	switch source {
+	case "degiro":
+		p := degiro.NewParser()
+		p.IncludeFXConversions = opts.IncludeFXConversions
+		return p, nil
+	case "ibkr":
+		p := ibkr.NewParser()
+		p.IncludeFXConversions = opts.IncludeFXConversions
+		return p, nil
+	case "revolut":
+		return revolut.NewParser(), nil
+	case "xtb":
+		return xtb.NewParser(), nil
+	case "freedom24":
+		return freedom24.NewParser(), nil
+	case "binance":
+		return binance.NewParser(), nil
+	case "kraken":
+		return kraken.NewParser(), nil
+	case "custom":
+		if opts.Profile == nil {
+			return nil, fmt.Errorf("the custom source requires a parser profile")
+		}
+		return custom.NewParser(*opts.Profile)
+	default:
+		return nil, fmt.Errorf("no parser available for source: %s", source)
+	}
+}
+
+// GetPositionParser returns the parser for a broker's open positions export.
+func GetPositionParser(source string) (PositionParser, error) {
 	switch source {
 	case "degiro":
 		return degiro.NewParser(), nil
 	case "ibkr":
 		return ibkr.NewParser(), nil
 	default:
-		return nil, fmt.Errorf("no parser available for source: %s", source)
+		return nil, fmt.Errorf("no position parser available for source: %s", source)
 	}
 }
@@ -0,0 +1,244 @@
+// backend/src/parsers/freedom24/parser.go
+package freedom24
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers/xlsx"
+)
+
+var dateLayouts = []string{"2006-01-02 15:04:05", "2006-01-02", "02.01.2006 15:04:05", "02.01.2006"}
+
+// Column sets identifying the two tables of the broker report (English export).
+var (
+	tradeColumns = []string{"trade date", "ticker", "operation", "quantity", "price", "currency"}
+	cashColumns  = []string{"date", "type", "amount", "currency"}
+)
+
+// Freedom24Parser implements the parsers.Parser interface for Freedom24 broker reports, as XLSX or CSV.
+// The report contains a trades table and a cash flows table (deposits, withdrawals, dividends with the
+// tax withheld, interest and fees); tables are located by their header rows on any sheet.
+type Freedom24Parser struct{}
+
+// NewParser creates a new instance of the Freedom24Parser.
+func NewParser() *Freedom24Parser {
+	return &Freedom24Parser{}
+}
+
+// Parse reads a Freedom24 broker report and converts its rows into a slice of CanonicalTransaction.
+func (p *Freedom24Parser) Parse(file io.Reader) ([]models.CanonicalTransaction, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("freedom24 parser: failed to read file: %w", err)
+	}
+
+	var tables [][][]string
+	if xlsx.IsXLSX(data) {
+		sheets, err := xlsx.ReadWorkbook(data)
+		if err != nil {
+			return nil, fmt.Errorf("freedom24 parser: %w", err)
+		}
+		for _, sheet := range sheets {
+			tables = append(tables, sheet.Rows)
+		}
+	} else {
+		rows, err := readCSV(data)
+		if err != nil {
+			return nil, fmt.Errorf("freedom24 parser: %w", err)
+		}
+		tables = append(tables, rows)
+	}
+
+	var canonicalTxs []models.CanonicalTransaction
+	foundTable := false
+	for _, rows := range tables {
+		for start := 0; start < len(rows); {
+			rest := rows[start:]
+			tradeIdx, tradeCols, hasTrades := xlsx.FindHeader(rest, tradeColumns...)
+			cashIdx, cashCols, hasCash := xlsx.FindHeader(rest, cashColumns...)
+			if !hasTrades && !hasCash {
+				break
+			}
+			foundTable = true
+			// Handle whichever table starts first, then continue after it.
+			if hasTrades && (!hasCash || tradeIdx <= cashIdx) {
+				txs, next := parseTrades(rest, tradeIdx, tradeCols)
+				canonicalTxs = append(canonicalTxs, txs...)
+				start += next
+			} else {
+				txs, next := parseCashFlows(rest, cashIdx, cashCols)
+				canonicalTxs = append(canonicalTxs, txs...)
+				start += next
+			}
+		}
+	}
+	if !foundTable {
+		return nil, fmt.Errorf("freedom24 parser: no trades or cash flows table found")
+	}
+	return canonicalTxs, nil
+}
+
+// parseTrades reads trade rows after the header until the first blank row and returns the index after the table.
+func parseTrades(rows [][]string, headerIdx int, columns map[string]int) ([]models.CanonicalTransaction, int) {
+	var canonicalTxs []models.CanonicalTransaction
+	i := headerIdx + 1
+	for ; i < len(rows) && !isBlankRow(rows[i]); i++ {
+		row := rows[i]
+		date, err := xlsx.ParseDate(xlsx.Cell(row, columns, "trade date"), dateLayouts...)
+		if err != nil {
+			log.Printf("Freedom24 Parser: Skipping trade due to invalid date: %s", xlsx.Cell(row, columns, "trade date"))
+			continue
+		}
+
+		var buySell string
+		switch strings.ToLower(xlsx.Cell(row, columns, "operation")) {
+		case "buy":
+			buySell = "BUY"
+		case "sell":
+			buySell = "SELL"
+		default:
+			log.Printf("Freedom24 Parser: Skipping trade with unknown operation: '%s'", xlsx.Cell(row, columns, "operation"))
+			continue
+		}
+
+		quantity := math.Abs(xlsx.ParseNumber(xlsx.Cell(row, columns, "quantity")))
+		price := xlsx.ParseNumber(xlsx.Cell(row, columns, "price"))
+		amount := math.Abs(xlsx.ParseNumber(xlsx.Cell(row, columns, "amount")))
+		if amount == 0 {
+			amount = quantity * price
+		}
+		if buySell == "BUY" {
+			amount = -amount
+		}
+
+		orderID := xlsx.Cell(row, columns, "trade id")
+		if orderID == "" {
+			orderID = xlsx.Cell(row, columns, "order id")
+		}
+
+		canonicalTxs = append(canonicalTxs, models.CanonicalTransaction{
+			Source:          "freedom24",
+			TransactionDate: date,
+			ProductName:     xlsx.Cell(row, columns, "ticker"),
+			ISIN:            strings.ToUpper(xlsx.Cell(row, columns, "isin")),
+			Quantity:        quantity,
+			Price:           price,
+			Commission:      math.Abs(xlsx.ParseNumber(xlsx.Cell(row, columns, "fee"))),
+			Currency:        strings.ToUpper(xlsx.Cell(row, columns, "currency")),
+			OrderID:         orderID,
+			RawText:         "Freedom24|Trade|" + strings.Join(row, "|"),
+			SourceAmount:    amount,
+			Amount:          amount,
+			TransactionType: "STOCK",
+			BuySell:         buySell,
+		})
+	}
+	return canonicalTxs, i
+}
+
+// parseCashFlows reads cash flow rows after the header until the first blank row and returns the index after the table.
+// Dividend amounts are the net credit; the "tax amount" column holds the withholding, so the gross is rebuilt from both.
+func parseCashFlows(rows [][]string, headerIdx int, columns map[string]int) ([]models.CanonicalTransaction, int) {
+	var canonicalTxs []models.CanonicalTransaction
+	i := headerIdx + 1
+	for ; i < len(rows) && !isBlankRow(rows[i]); i++ {
+		row := rows[i]
+		date, err := xlsx.ParseDate(xlsx.Cell(row, columns, "date"), dateLayouts...)
+		if err != nil {
+			log.Printf("Freedom24 Parser: Skipping cash flow due to invalid date: %s", xlsx.Cell(row, columns, "date"))
+			continue
+		}
+		flowType := strings.ToLower(xlsx.Cell(row, columns, "type"))
+		amount := xlsx.ParseNumber(xlsx.Cell(row, columns, "amount"))
+		rawText := "Freedom24|Cash|" + strings.Join(row, "|")
+
+		tx := models.CanonicalTransaction{
+			Source:          "freedom24",
+			TransactionDate: date,
+			ProductName:     xlsx.Cell(row, columns, "ticker"),
+			ISIN:            strings.ToUpper(xlsx.Cell(row, columns, "isin")),
+			Currency:        strings.ToUpper(xlsx.Cell(row, columns, "currency")),
+			RawText:         rawText,
+			SourceAmount:    amount,
+			Amount:          amount,
+		}
+
+		switch {
+		case strings.Contains(flowType, "dividend"):
+			withheld := math.Abs(xlsx.ParseNumber(xlsx.Cell(row, columns, "tax amount")))
+			tx.TransactionType = "DIVIDEND"
+			tx.Amount = math.Abs(amount) + withheld
+			canonicalTxs = append(canonicalTxs, tx)
+			if withheld > 0 {
+				tax := tx
+				tax.TransactionSubType = "TAX"
+				tax.Amount = -withheld
+				tax.RawText = rawText + "|TAX"
+				canonicalTxs = append(canonicalTxs, tax)
+			}
+			continue
+		case strings.Contains(flowType, "interest"):
+			tx.TransactionType = "INTEREST"
+			if strings.Contains(flowType, "tax") {
+				tx.TransactionSubType = "TAX"
+				tx.Amount = -math.Abs(amount)
+			}
+			if tx.ProductName == "" {
+				tx.ProductName = "Interest"
+			}
+		case strings.Contains(flowType, "fee") || strings.Contains(flowType, "commission"):
+			tx.TransactionType = "FEE"
+			tx.ProductName = xlsx.Cell(row, columns, "type")
+			tx.Amount = -math.Abs(amount)
+		case strings.Contains(flowType, "deposit") || strings.Contains(flowType, "cash in"):
+			tx.TransactionType = "CASH"
+			tx.TransactionSubType = "DEPOSIT"
+			tx.ProductName = "Cash Deposit"
+			tx.Amount = math.Abs(amount)
+		case strings.Contains(flowType, "withdrawal") || strings.Contains(flowType, "cash out"):
+			tx.TransactionType = "CASH"
+			tx.TransactionSubType = "WITHDRAWAL"
+			tx.ProductName = "Cash Withdrawal"
+			tx.Amount = -math.Abs(amount)
+		default:
+			log.Printf("Freedom24 Parser: Skipping unknown cash flow type: '%s'", flowType)
+			continue
+		}
+		canonicalTxs = append(canonicalTxs, tx)
+	}
+	return canonicalTxs, i
+}
+
+// readCSV reads a CSV report, detecting a semicolon delimiter from the first line.
+func readCSV(data []byte) ([][]string, error) {
+	firstLine := data
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		firstLine = data[:idx]
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return rows, nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
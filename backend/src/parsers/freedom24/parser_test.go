@@ -0,0 +1,178 @@
+package freedom24
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+type wantTx struct {
+	txType   string
+	subType  string
+	buySell  string
+	product  string
+	quantity float64
+	amount   float64
+	currency string
+}
+
+func checkTransactions(t *testing.T, txs []models.CanonicalTransaction, want []wantTx) {
+	t.Helper()
+	if len(txs) != len(want) {
+		t.Fatalf("got %d transactions, want %d: %+v", len(txs), len(want), txs)
+	}
+	for i, w := range want {
+		tx := txs[i]
+		if tx.TransactionType != w.txType || tx.TransactionSubType != w.subType || tx.BuySell != w.buySell || tx.ProductName != w.product {
+			t.Errorf("tx %d: unexpected classification %+v, want %+v", i, tx, w)
+			continue
+		}
+		if tx.Quantity != w.quantity || math.Abs(tx.Amount-w.amount) > 1e-9 || tx.Currency != w.currency {
+			t.Errorf("tx %d (%s): quantity %v amount %v currency %s, want %v %v %s", i, tx.ProductName, tx.Quantity, tx.Amount, tx.Currency, w.quantity, w.amount, w.currency)
+		}
+		if tx.Source != "freedom24" {
+			t.Errorf("tx %d: Source = %s, want freedom24", i, tx.Source)
+		}
+	}
+}
+
+func TestParseSemicolonCSV(t *testing.T) {
+	report := strings.Join([]string{
+		"Trade date;Ticker;ISIN;Operation;Quantity;Price;Amount;Fee;Currency;Trade ID",
+		"2024-02-05 10:00:00;AAPL.US;us0378331005;Buy;10;100,50;1005,00;1,20;usd;T1",
+		"2024-03-05;AAPL.US;us0378331005;Sell;-4;110,00;;0,80;USD;T2",
+		"2024-03-06;AAPL.US;us0378331005;Transfer;4;110,00;;;USD;T3",
+		";;;;;;;;;",
+		"Date;Type;Ticker;ISIN;Amount;Tax amount;Currency",
+		"15.03.2024;Dividend;AAPL.US;US0378331005;2,04;0,36;USD",
+		"01.02.2024;Deposit;;;1000,00;;EUR",
+		"01.04.2024;Interest;;;1,50;;EUR",
+		"02.04.2024;Custody fee;;;2,00;;EUR",
+		"03.04.2024;Bonus;;;5,00;;EUR",
+	}, "\n")
+
+	txs, err := NewParser().Parse(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	checkTransactions(t, txs, []wantTx{
+		{txType: "STOCK", buySell: "BUY", product: "AAPL.US", quantity: 10, amount: -1005, currency: "USD"},
+		{txType: "STOCK", buySell: "SELL", product: "AAPL.US", quantity: 4, amount: 440, currency: "USD"},
+		{txType: "DIVIDEND", product: "AAPL.US", amount: 2.4, currency: "USD"},
+		{txType: "DIVIDEND", subType: "TAX", product: "AAPL.US", amount: -0.36, currency: "USD"},
+		{txType: "CASH", subType: "DEPOSIT", product: "Cash Deposit", amount: 1000, currency: "EUR"},
+		{txType: "INTEREST", product: "Interest", amount: 1.5, currency: "EUR"},
+		{txType: "FEE", product: "Custody fee", amount: -2, currency: "EUR"},
+	})
+
+	buy := txs[0]
+	if buy.ISIN != "US0378331005" || buy.OrderID != "T1" || buy.Commission != 1.2 || buy.Price != 100.5 {
+		t.Errorf("buy details: %+v", buy)
+	}
+	if txs[2].SourceAmount != 2.04 {
+		t.Errorf("dividend SourceAmount = %v, want the net credit 2.04", txs[2].SourceAmount)
+	}
+	if txs[3].RawText != txs[2].RawText+"|TAX" {
+		t.Errorf("tax RawText = %q, want the dividend row with a |TAX suffix so both rows stay distinct", txs[3].RawText)
+	}
+}
+
+func TestParseCommaCSVWithBOM(t *testing.T) {
+	report := "\xef\xbb\xbfDate,Type,Amount,Currency\n" +
+		"2024-01-10,Withdrawal,250.00,EUR\n" +
+		"2024-01-11,Dividend,3.00,EUR\n"
+
+	txs, err := NewParser().Parse(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	checkTransactions(t, txs, []wantTx{
+		{txType: "CASH", subType: "WITHDRAWAL", product: "Cash Withdrawal", amount: -250, currency: "EUR"},
+		{txType: "DIVIDEND", amount: 3, currency: "EUR"},
+	})
+}
+
+func TestParseXLSXAcrossSheets(t *testing.T) {
+	workbook := buildWorkbook(t, []sheetData{
+		{name: "Summary", rows: [][]string{
+			{"Broker report"},
+			{},
+			{"Trade date", "Ticker", "Operation", "Quantity", "Price", "Currency"},
+			{"05.02.2024", "VWCE", "Buy", "2", "110.00", "EUR"},
+		}},
+		{name: "Cash", rows: [][]string{
+			{"Date", "Type", "Amount", "Tax amount", "Currency", "Ticker"},
+			{"10.03.2024", "Dividend", "4.25", "0.75", "EUR", "VWCE"},
+		}},
+	})
+
+	txs, err := NewParser().Parse(bytes.NewReader(workbook))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	checkTransactions(t, txs, []wantTx{
+		{txType: "STOCK", buySell: "BUY", product: "VWCE", quantity: 2, amount: -220, currency: "EUR"},
+		{txType: "DIVIDEND", product: "VWCE", amount: 5, currency: "EUR"},
+		{txType: "DIVIDEND", subType: "TAX", product: "VWCE", amount: -0.75, currency: "EUR"},
+	})
+}
+
+func TestParseNoTable(t *testing.T) {
+	if _, err := NewParser().Parse(strings.NewReader("Account;Currency\n123;EUR\n")); err == nil {
+		t.Fatal("expected an error for a report without a trades or cash flows table")
+	}
+}
+
+type sheetData struct {
+	name string
+	rows [][]string
+}
+
+// buildWorkbook writes a minimal XLSX archive with inline string cells.
+func buildWorkbook(t *testing.T, sheets []sheetData) []byte {
+	t.Helper()
+	var workbook, rels strings.Builder
+	workbook.WriteString(`<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(`<Relationships>`)
+	entries := map[string]string{"[Content_Types].xml": `<Types/>`}
+	for i, sheet := range sheets {
+		fmt.Fprintf(&workbook, `<sheet name="%s" r:id="rId%d"/>`, sheet.name, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		var ws strings.Builder
+		ws.WriteString(`<worksheet><sheetData>`)
+		for _, row := range sheet.rows {
+			ws.WriteString(`<row>`)
+			for col, value := range row {
+				fmt.Fprintf(&ws, `<c r="%c1" t="inlineStr"><is><t>%s</t></is></c>`, 'A'+col, value)
+			}
+			ws.WriteString(`</row>`)
+		}
+		ws.WriteString(`</sheetData></worksheet>`)
+		entries[fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)] = ws.String()
+	}
+	workbook.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+	entries["xl/workbook.xml"] = workbook.String()
+	entries["xl/_rels/workbook.xml.rels"] = rels.String()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatalf("zip entry %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip entry %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -27,11 +28,15 @@ type FlexStatement struct {
 	AccountId        string            `xml:"accountId,attr"`
 	Trades           []Trade           `xml:"Trades>Trade"`
 	CashTransactions []CashTransaction `xml:"CashTransactions>CashTransaction"`
+	OpenPositions    []OpenPosition    `xml:"OpenPositions>OpenPosition"`
+	Transfers        []Transfer        `xml:"Transfers>Transfer"`
+	CorporateActions []CorporateAction `xml:"CorporateActions>CorporateAction"`
 }
 
 // Trade represents a stock or option trade transaction.
 type Trade struct {
 	AssetCategory        string  `xml:"assetCategory,attr"`
+	SubCategory          string  `xml:"subCategory,attr"` // e.g. "COMMON", "ETF", "ADR"
 	Symbol               string  `xml:"symbol,attr"`
 	Description          string  `xml:"description,attr"`
 	Conid                string  `xml:"conid,attr"`
@@ -50,6 +55,10 @@ type Trade struct {
 	BuySell              string  `xml:"buySell,attr"`
 	IBOrderID            string  `xml:"ibOrderID,attr"`
 	PutCall              string  `xml:"putCall,attr"` // For Options
+	UnderlyingSymbol     string  `xml:"underlyingSymbol,attr"`
+	Strike               float64 `xml:"strike,attr"`
+	Expiry               string  `xml:"expiry,attr"`     // YYYYMMDD
+	AccruedInt           float64 `xml:"accruedInt,attr"` // For Bonds: accrued interest paid or received with the trade
 }
 
 // CashTransaction represents dividends, withdrawals, deposits, and other cash movements.
@@ -64,10 +73,64 @@ type CashTransaction struct {
 	Symbol        string  `xml:"symbol,attr"`
 }
 
+// Transfer represents a position moved into or out of the account (ACATS, FOP or internal transfers).
+type Transfer struct {
+	Type          string  `xml:"type,attr"`
+	Direction     string  `xml:"direction,attr"` // "IN" or "OUT"
+	AssetCategory string  `xml:"assetCategory,attr"`
+	SubCategory   string  `xml:"subCategory,attr"`
+	Symbol        string  `xml:"symbol,attr"`
+	Description   string  `xml:"description,attr"`
+	ISIN          string  `xml:"isin,attr"`
+	DateTime      string  `xml:"dateTime,attr"`
+	Date          string  `xml:"date,attr"`
+	Quantity      float64 `xml:"quantity,attr"`
+	TransferPrice float64 `xml:"transferPrice,attr"`
+	Currency      string  `xml:"currency,attr"`
+	TransactionID string  `xml:"transactionID,attr"`
+	LevelOfDetail string  `xml:"levelOfDetail,attr"`
+}
+
+// CorporateAction represents a corporate event on a position. Only bond maturities ("BM") are imported.
+type CorporateAction struct {
+	Type          string  `xml:"type,attr"`
+	AssetCategory string  `xml:"assetCategory,attr"`
+	Symbol        string  `xml:"symbol,attr"`
+	Description   string  `xml:"description,attr"`
+	ISIN          string  `xml:"isin,attr"`
+	DateTime      string  `xml:"dateTime,attr"`
+	Quantity      float64 `xml:"quantity,attr"` // Negative: the nominal amount leaving the account
+	Proceeds      float64 `xml:"proceeds,attr"`
+	Currency      string  `xml:"currency,attr"`
+	TransactionID string  `xml:"transactionID,attr"`
+	LevelOfDetail string  `xml:"levelOfDetail,attr"`
+}
+
+// OpenPosition represents a broker-reported open position at the statement's report date.
+type OpenPosition struct {
+	AssetCategory    string  `xml:"assetCategory,attr"`
+	Symbol           string  `xml:"symbol,attr"`
+	Description      string  `xml:"description,attr"`
+	ISIN             string  `xml:"isin,attr"`
+	ReportDate       string  `xml:"reportDate,attr"`
+	Position         float64 `xml:"position,attr"`
+	CostBasisMoney   float64 `xml:"costBasisMoney,attr"`
+	Currency         string  `xml:"currency,attr"`
+	LevelOfDetail    string  `xml:"levelOfDetail,attr"`
+	PutCall          string  `xml:"putCall,attr"` // For Options
+	UnderlyingSymbol string  `xml:"underlyingSymbol,attr"`
+	Strike           float64 `xml:"strike,attr"`
+	Expiry           string  `xml:"expiry,attr"` // YYYYMMDD
+	Multiplier       float64 `xml:"multiplier,attr"`
+}
+
 // --- IBKR Parser Implementation ---
 
 // IBKRParser implements the parsers.Parser interface for IBKR Flex Query XML files.
-type IBKRParser struct{}
+type IBKRParser struct {
+	// IncludeFXConversions turns IDEALFX currency trades into FX transactions instead of skipping them.
+	IncludeFXConversions bool
+}
 
 // NewParser creates a new instance of the IBKRParser.
 func NewParser() *IBKRParser {
@@ -85,10 +148,22 @@ func (p *IBKRParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error
 	var canonicalTxs []models.CanonicalTransaction
 
 	for _, stmt := range response.FlexStatements {
+		// Accrued interest already taken from bond trades, so the matching cash rows are not counted twice.
+		tradeAccruedInterest := make(map[string]bool)
+
 		// Process Trades (Stocks and Options)
 		for _, trade := range stmt.Trades {
-			// As requested, ignore internal currency exchange transactions
+			// Internal currency exchange transactions are only kept when explicitly requested
 			if trade.Exchange == "IDEALFX" {
+				if !p.IncludeFXConversions {
+					continue
+				}
+				legs, err := p.processFXTrade(trade)
+				if err != nil {
+					logger.L.Warn("IBKR Parser: Skipping FX conversion due to processing error", "ibOrderID", trade.IBOrderID, "error", err)
+					continue
+				}
+				canonicalTxs = append(canonicalTxs, legs...)
 				continue
 			}
 
@@ -98,6 +173,37 @@ func (p *IBKRParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error
 				continue
 			}
 			canonicalTxs = append(canonicalTxs, tx)
+			if trade.AssetCategory == "BOND" && trade.AccruedInt != 0 {
+				accrued := p.processAccruedInterest(trade, tx)
+				tradeAccruedInterest[bondInterestKey(accrued.ISIN, accrued.TransactionDate, accrued.Amount)] = true
+				canonicalTxs = append(canonicalTxs, accrued)
+			}
+		}
+
+		// Process bond redemptions at maturity
+		for _, action := range stmt.CorporateActions {
+			if action.Type != "BM" || (action.LevelOfDetail != "" && action.LevelOfDetail != "DETAIL") {
+				continue
+			}
+			tx, err := p.processBondMaturity(action)
+			if err != nil {
+				logger.L.Warn("IBKR Parser: Skipping bond maturity due to processing error", "transactionID", action.TransactionID, "error", err)
+				continue
+			}
+			canonicalTxs = append(canonicalTxs, tx)
+		}
+
+		// Process stock transfers between brokers
+		for _, transfer := range stmt.Transfers {
+			if transfer.AssetCategory != "STK" || (transfer.LevelOfDetail != "" && transfer.LevelOfDetail != "TRANSFER") {
+				continue
+			}
+			tx, err := p.processTransfer(transfer)
+			if err != nil {
+				logger.L.Warn("IBKR Parser: Skipping transfer due to processing error", "transactionID", transfer.TransactionID, "error", err)
+				continue
+			}
+			canonicalTxs = append(canonicalTxs, tx)
 		}
 
 		// Process Cash Transactions (Dividends, Deposits, etc.)
@@ -116,6 +222,27 @@ func (p *IBKRParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error
 					continue
 				}
 				canonicalTxs = append(canonicalTxs, tx)
+			case "Bond Interest Received", "Bond Interest Paid":
+				tx, err := p.processBondInterest(cashTx)
+				if err != nil {
+					logger.L.Warn("IBKR Parser: Skipping bond interest due to processing error", "description", cashTx.Description, "error", err)
+					continue
+				}
+				if tradeAccruedInterest[bondInterestKey(tx.ISIN, tx.TransactionDate, tx.Amount)] {
+					continue
+				}
+				canonicalTxs = append(canonicalTxs, tx)
+			case "Broker Interest Received", "Broker Interest Paid", "Withholding Tax":
+				// Withholding on dividends is not imported yet; only the tax withheld on interest is taken here.
+				if cashTx.Type == "Withholding Tax" && (cashTx.ISIN != "" || !interestWithholdingRe.MatchString(cashTx.Description)) {
+					continue
+				}
+				tx, err := p.processInterest(cashTx)
+				if err != nil {
+					logger.L.Warn("IBKR Parser: Skipping interest due to processing error", "description", cashTx.Description, "error", err)
+					continue
+				}
+				canonicalTxs = append(canonicalTxs, tx)
 			case "Deposits/Withdrawals":
 				tx, err := p.processCashMovement(cashTx)
 				if err != nil {
@@ -159,14 +286,18 @@ func (p *IBKRParser) processTrade(trade Trade) (models.CanonicalTransaction, err
 		Price:           trade.TradePrice,
 		Commission:      math.Abs(trade.IBCommission),
 		Currency:        trade.Currency,
-		OrderID:         fmt.Sprintf("%s", trade.IBOrderID),
-		RawText:         rawText,
-		SourceAmount:    trade.TradeMoney,
-		Amount:          -trade.TradeMoney, // IBKR tradeMoney is positive for BUY (cost), negative for SELL (proceeds). We invert for our model.
-		BuySell:         trade.BuySell,
+		// IBKR may charge the commission in the account's base currency rather than the trade's.
+		CommissionCurrency: commissionCurrency(trade),
+		OrderID:            fmt.Sprintf("%s", trade.IBOrderID),
+		RawText:            rawText,
+		SourceAmount:       trade.TradeMoney,
+		Amount:             -trade.TradeMoney, // IBKR tradeMoney is positive for BUY (cost), negative for SELL (proceeds). We invert for our model.
+		BuySell:            trade.BuySell,
+		InstrumentClass:    instrumentClassHint(trade.AssetCategory, trade.SubCategory),
 	}
 
-	if trade.AssetCategory == "STK" {
+	if trade.AssetCategory == "STK" || trade.AssetCategory == "FUND" || trade.AssetCategory == "WAR" {
+		// Funds and warrants are matched like shares; their instrument class keeps them apart in the reports.
 		tx.TransactionType = "STOCK"
 	} else if trade.AssetCategory == "OPT" {
 		tx.TransactionType = "OPTION"
@@ -175,6 +306,15 @@ func (p *IBKRParser) processTrade(trade Trade) (models.CanonicalTransaction, err
 		} else if trade.PutCall == "C" {
 			tx.TransactionSubType = "CALL"
 		}
+		tx.OptionRight = tx.TransactionSubType
+		tx.Underlying = strings.ToUpper(trade.UnderlyingSymbol)
+		tx.Strike = trade.Strike
+		tx.Multiplier = trade.Multiplier
+		if expiry, err := parseIBKRDateTime(trade.Expiry); err == nil {
+			tx.Expiry = expiry
+		} else if trade.Expiry != "" {
+			logger.L.Warn("IBKR Parser: Option trade with unreadable expiry", "ibOrderID", trade.IBOrderID, "expiry", trade.Expiry)
+		}
 	} else {
 		tx.TransactionType = strings.ToUpper(trade.AssetCategory)
 	}
@@ -182,6 +322,135 @@ func (p *IBKRParser) processTrade(trade Trade) (models.CanonicalTransaction, err
 	return tx, nil
 }
 
+// processFXTrade converts an IDEALFX trade into one FX transaction per currency leg.
+// For a symbol like "EUR.USD", a BUY of quantity Q at price P receives Q EUR and pays Q*P USD.
+func (p *IBKRParser) processFXTrade(trade Trade) ([]models.CanonicalTransaction, error) {
+	date, err := parseIBKRDateTime(trade.DateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	pair := strings.Split(trade.Symbol, ".")
+	if len(pair) != 2 || len(pair[0]) != 3 || len(pair[1]) != 3 {
+		return nil, fmt.Errorf("unexpected FX symbol '%s'", trade.Symbol)
+	}
+	baseCurrency, quoteCurrency := pair[0], pair[1]
+	if trade.Currency != "" && trade.Currency != quoteCurrency {
+		return nil, fmt.Errorf("FX symbol '%s' does not match trade currency '%s'", trade.Symbol, trade.Currency)
+	}
+
+	rawText := fmt.Sprintf("FX|%s|%s|%s|%s|%f|%f|%f|%s|%f",
+		trade.IBOrderID, trade.DateTime, trade.Symbol, trade.BuySell,
+		trade.Quantity, trade.TradePrice, trade.TradeMoney, trade.Currency, trade.IBCommission,
+	)
+
+	baseLeg := models.CanonicalTransaction{
+		Source:             "ibkr",
+		TransactionDate:    date,
+		ProductName:        trade.Symbol,
+		Quantity:           math.Abs(trade.Quantity),
+		Price:              trade.TradePrice,
+		Currency:           baseCurrency,
+		OrderID:            trade.IBOrderID,
+		RawText:            rawText + "|" + baseCurrency,
+		SourceAmount:       trade.Quantity,
+		Amount:             trade.Quantity, // IBKR quantity is positive for BUY (base received), negative for SELL.
+		TransactionType:    "FX",
+		TransactionSubType: "CONVERSION",
+		BuySell:            trade.BuySell,
+	}
+
+	quoteBuySell := "SELL"
+	if trade.TradeMoney < 0 {
+		quoteBuySell = "BUY"
+	}
+	quoteLeg := models.CanonicalTransaction{
+		Source:             "ibkr",
+		TransactionDate:    date,
+		ProductName:        trade.Symbol,
+		Quantity:           math.Abs(trade.TradeMoney),
+		Price:              trade.TradePrice,
+		Commission:         math.Abs(trade.IBCommission),
+		CommissionCurrency: commissionCurrency(trade),
+		Currency:           quoteCurrency,
+		OrderID:            trade.IBOrderID,
+		RawText:            rawText + "|" + quoteCurrency,
+		SourceAmount:       trade.TradeMoney,
+		Amount:             -trade.TradeMoney, // Same sign convention as stock trades.
+		TransactionType:    "FX",
+		TransactionSubType: "CONVERSION",
+		BuySell:            quoteBuySell,
+	}
+
+	return []models.CanonicalTransaction{baseLeg, quoteLeg}, nil
+}
+
+// processTransfer converts a stock Transfer record to a STOCK transaction with BuySell TRANSFER_IN or TRANSFER_OUT.
+// The receiving side does not report the original cost, so the amount is left at zero and the stock processor
+// carries the lots over from the sending broker's history.
+func (p *IBKRParser) processTransfer(transfer Transfer) (models.CanonicalTransaction, error) {
+	dateTime := transfer.DateTime
+	if dateTime == "" {
+		dateTime = transfer.Date
+	}
+	date, err := parseIBKRDateTime(dateTime)
+	if err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+
+	var buySell string
+	switch strings.ToUpper(transfer.Direction) {
+	case "IN":
+		buySell = "TRANSFER_IN"
+	case "OUT":
+		buySell = "TRANSFER_OUT"
+	default:
+		return models.CanonicalTransaction{}, fmt.Errorf("unexpected transfer direction '%s'", transfer.Direction)
+	}
+
+	rawText := fmt.Sprintf("Transfer|%s|%s|%s|%s|%s|%s|%f|%f|%s",
+		transfer.TransactionID, transfer.Type, transfer.Direction, dateTime, transfer.Symbol, transfer.ISIN,
+		transfer.Quantity, transfer.TransferPrice, transfer.Currency,
+	)
+
+	return models.CanonicalTransaction{
+		Source:             "ibkr",
+		TransactionDate:    date,
+		ProductName:        transfer.Description,
+		ISIN:               transfer.ISIN,
+		Quantity:           math.Abs(transfer.Quantity),
+		Price:              transfer.TransferPrice,
+		Currency:           transfer.Currency,
+		OrderID:            transfer.TransactionID,
+		RawText:            rawText,
+		TransactionType:    "STOCK",
+		TransactionSubType: "TRANSFER",
+		BuySell:            buySell,
+		InstrumentClass:    instrumentClassHint(transfer.AssetCategory, transfer.SubCategory),
+	}, nil
+}
+
+// instrumentClassHint maps IBKR's assetCategory and subCategory to an instrument class hint.
+// Stocks without a sub category return "" so the transaction processor falls back to its own classification.
+func instrumentClassHint(assetCategory, subCategory string) string {
+	switch assetCategory {
+	case "STK":
+		switch strings.ToUpper(subCategory) {
+		case "ETF":
+			return models.InstrumentClassETF
+		case "COMMON", "ADR", "PREFERRED", "REIT":
+			return models.InstrumentClassShare
+		}
+	case "FUND":
+		return models.InstrumentClassFund
+	case "BOND", "BILL":
+		return models.InstrumentClassBond
+	case "WAR":
+		return models.InstrumentClassWarrant
+	}
+	return ""
+}
+
 // processDividend converts an IBKR Dividend CashTransaction to a CanonicalTransaction.
 func (p *IBKRParser) processDividend(cashTx CashTransaction) (models.CanonicalTransaction, error) {
 	date, err := parseIBKRDateTime(cashTx.DateTime)
@@ -211,6 +480,139 @@ func (p *IBKRParser) processDividend(cashTx CashTransaction) (models.CanonicalTr
 	return tx, nil
 }
 
+// processAccruedInterest converts the accrued interest of a bond trade into its own BOND transaction,
+// paid (negative) on a purchase and received (positive) on a sale, so the trade itself keeps the clean price.
+func (p *IBKRParser) processAccruedInterest(trade Trade, tradeTx models.CanonicalTransaction) models.CanonicalTransaction {
+	amount := math.Abs(trade.AccruedInt)
+	if trade.BuySell == "BUY" {
+		amount = -amount
+	}
+	return models.CanonicalTransaction{
+		Source:             "ibkr",
+		TransactionDate:    tradeTx.TransactionDate,
+		ProductName:        tradeTx.ProductName,
+		ISIN:               tradeTx.ISIN,
+		Currency:           trade.Currency,
+		OrderID:            tradeTx.OrderID,
+		RawText:            tradeTx.RawText + "|AccruedInt|" + strconv.FormatFloat(trade.AccruedInt, 'f', -1, 64),
+		SourceAmount:       trade.AccruedInt,
+		Amount:             amount,
+		TransactionType:    "BOND",
+		TransactionSubType: "ACCRUED_INTEREST",
+		InstrumentClass:    models.InstrumentClassBond,
+	}
+}
+
+// processBondInterest converts a "Bond Interest Received/Paid" CashTransaction. Received interest is a coupon;
+// paid interest is accrued interest bought with a bond whose trade did not report it.
+func (p *IBKRParser) processBondInterest(cashTx CashTransaction) (models.CanonicalTransaction, error) {
+	date, err := parseIBKRDateTime(cashTx.DateTime)
+	if err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+	rawText := fmt.Sprintf("BondInterest|%s|%s|%s|%s|%f|%s|%s",
+		cashTx.Type, cashTx.DateTime, cashTx.Description, cashTx.Symbol, cashTx.Amount, cashTx.Currency, cashTx.ISIN,
+	)
+	tx := models.CanonicalTransaction{
+		Source:             "ibkr",
+		TransactionDate:    date,
+		ProductName:        cashTx.Description,
+		ISIN:               cashTx.ISIN,
+		Amount:             cashTx.Amount,
+		SourceAmount:       cashTx.Amount,
+		Currency:           cashTx.Currency,
+		RawText:            rawText,
+		TransactionType:    "BOND",
+		TransactionSubType: "COUPON",
+		InstrumentClass:    models.InstrumentClassBond,
+	}
+	if cashTx.Amount < 0 {
+		tx.TransactionSubType = "ACCRUED_INTEREST"
+	}
+	return tx, nil
+}
+
+// processBondMaturity converts a bond maturity corporate action into a BOND SELL at the redemption value.
+func (p *IBKRParser) processBondMaturity(action CorporateAction) (models.CanonicalTransaction, error) {
+	date, err := parseIBKRDateTime(action.DateTime)
+	if err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+	quantity := math.Abs(action.Quantity)
+	if quantity == 0 {
+		return models.CanonicalTransaction{}, fmt.Errorf("bond maturity without quantity")
+	}
+	rawText := fmt.Sprintf("BondMaturity|%s|%s|%s|%s|%f|%f|%s",
+		action.TransactionID, action.DateTime, action.Description, action.ISIN, action.Quantity, action.Proceeds, action.Currency,
+	)
+	return models.CanonicalTransaction{
+		Source:             "ibkr",
+		TransactionDate:    date,
+		ProductName:        action.Description,
+		ISIN:               action.ISIN,
+		Quantity:           quantity,
+		Price:              action.Proceeds / quantity * 100, // Bond prices are quoted as a percentage of the nominal amount
+		Currency:           action.Currency,
+		OrderID:            action.TransactionID,
+		RawText:            rawText,
+		SourceAmount:       action.Proceeds,
+		Amount:             math.Abs(action.Proceeds),
+		TransactionType:    "BOND",
+		TransactionSubType: "REDEMPTION",
+		BuySell:            "SELL",
+		InstrumentClass:    models.InstrumentClassBond,
+	}, nil
+}
+
+// bondInterestKey identifies an accrued interest amount on a bond and day.
+func bondInterestKey(isin string, date time.Time, amount float64) string {
+	return fmt.Sprintf("%s|%s|%.2f", isin, date.Format("2006-01-02"), amount)
+}
+
+// interestWithholdingRe matches the description IBKR gives tax withheld on interest, e.g.
+// "WITHHOLDING @ 20% ON CREDIT INT FOR MAR-2024". Dividend withholding names the security instead.
+var interestWithholdingRe = regexp.MustCompile(`(?i)^WITHHOLDING @ \d+(\.\d+)?% ON (CREDIT INT|CREDIT INTEREST|.*\(SYEP\) INTEREST) FOR [A-Z]{3}-\d{4}$`)
+
+// processInterest converts broker interest, stock yield enhancement (securities lending) income and the tax
+// withheld on them into an INTEREST CanonicalTransaction. The amount keeps IBKR's sign.
+func (p *IBKRParser) processInterest(cashTx CashTransaction) (models.CanonicalTransaction, error) {
+	date, err := parseIBKRDateTime(cashTx.DateTime)
+	if err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+	var subType string
+	upperDesc := strings.ToUpper(cashTx.Description)
+	if cashTx.Type == "Withholding Tax" {
+		subType = "TAX"
+	} else if strings.Contains(upperDesc, "SYEP") || strings.Contains(upperDesc, "YIELD ENHANCEMENT") || strings.Contains(upperDesc, "SECURITIES LENDING") {
+		subType = "LENDING"
+	}
+	rawText := fmt.Sprintf("Interest|%s|%s|%s|%f|%s",
+		cashTx.Type, cashTx.DateTime, cashTx.Description, cashTx.Amount, cashTx.Currency,
+	)
+	return models.CanonicalTransaction{
+		Source:             "ibkr",
+		TransactionDate:    date,
+		ProductName:        cashTx.Description,
+		Amount:             cashTx.Amount,
+		SourceAmount:       cashTx.Amount,
+		Currency:           cashTx.Currency,
+		RawText:            rawText,
+		TransactionType:    "INTEREST",
+		TransactionSubType: subType,
+		// The paying IBKR entity depends on the account and is not in the statement, so no country is set.
+	}, nil
+}
+
+// commissionCurrency returns the currency IBKR charged a trade's commission in, or "" when it is the trade currency.
+func commissionCurrency(trade Trade) string {
+	currency := strings.ToUpper(strings.TrimSpace(trade.IBCommissionCurrency))
+	if currency == trade.Currency {
+		return ""
+	}
+	return currency
+}
+
 // processCashMovement converts a Deposit/Withdrawal to a CanonicalTransaction.
 func (p *IBKRParser) processCashMovement(cashTx CashTransaction) (models.CanonicalTransaction, error) {
 	date, err := parseIBKRDateTime(cashTx.DateTime)
@@ -242,6 +644,68 @@ func (p *IBKRParser) processCashMovement(cashTx CashTransaction) (models.Canonic
 	return tx, nil
 }
 
+// ParsePositions reads the OpenPositions section of an IBKR Flex Query XML file.
+// Only summary-level rows are used; lot-level rows would double count.
+func (p *IBKRParser) ParsePositions(file io.Reader) ([]models.BrokerPosition, error) {
+	var response FlexQueryResponse
+	decoder := xml.NewDecoder(file)
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("ibkr parser: failed to decode XML: %w", err)
+	}
+
+	var positions []models.BrokerPosition
+	for _, stmt := range response.FlexStatements {
+		for _, pos := range stmt.OpenPositions {
+			if pos.LevelOfDetail != "" && pos.LevelOfDetail != "SUMMARY" {
+				continue
+			}
+
+			var assetType string
+			switch pos.AssetCategory {
+			case "STK":
+				assetType = "STOCK"
+			case "OPT":
+				assetType = "OPTION"
+			default:
+				continue
+			}
+
+			reportDate := ""
+			if date, err := parseIBKRDateTime(pos.ReportDate); err == nil {
+				reportDate = date.Format("02-01-2006")
+			}
+
+			position := models.BrokerPosition{
+				Source:      "ibkr",
+				ReportDate:  reportDate,
+				AssetType:   assetType,
+				ISIN:        pos.ISIN,
+				Symbol:      pos.Symbol,
+				ProductName: pos.Description,
+				Quantity:    pos.Position,
+				CostBasis:   pos.CostBasisMoney,
+				Currency:    pos.Currency,
+			}
+			if assetType == "OPTION" {
+				switch pos.PutCall {
+				case "P":
+					position.OptionRight = "PUT"
+				case "C":
+					position.OptionRight = "CALL"
+				}
+				position.Underlying = strings.ToUpper(pos.UnderlyingSymbol)
+				position.Strike = pos.Strike
+				position.Multiplier = pos.Multiplier
+				if expiry, err := parseIBKRDateTime(pos.Expiry); err == nil {
+					position.Expiry = expiry.Format("02-01-2006")
+				}
+			}
+			positions = append(positions, position)
+		}
+	}
+	return positions, nil
+}
+
 // parseIBKRDateTime converts IBKR's "YYYYMMDD;HHMMSS" format to time.Time.
 func parseIBKRDateTime(datetime string) (time.Time, error) {
 	// Handle cases with and without time
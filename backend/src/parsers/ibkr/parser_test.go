@@ -0,0 +1,190 @@
+package ibkr
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error")
+	if err := utils.InitCountryData("../../../data/country.json"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestParseInterest(t *testing.T) {
+	tests := []struct {
+		name        string
+		cashTx      string
+		wantImport  bool
+		wantSubType string
+	}{
+		{
+			name:       "credit interest",
+			cashTx:     `type="Broker Interest Received" description="EUR CREDIT INT FOR MAR-2024" amount="12.5"`,
+			wantImport: true,
+		},
+		{
+			name:        "securities lending income",
+			cashTx:      `type="Broker Interest Received" description="USD IBKR MANAGED SECURITIES (SYEP) INTEREST FOR MAR-2024" amount="1.2"`,
+			wantImport:  true,
+			wantSubType: "LENDING",
+		},
+		{
+			name:        "tax withheld on credit interest",
+			cashTx:      `type="Withholding Tax" description="WITHHOLDING @ 20% ON CREDIT INT FOR MAR-2024" amount="-2.5"`,
+			wantImport:  true,
+			wantSubType: "TAX",
+		},
+		{
+			name:        "tax withheld on securities lending",
+			cashTx:      `type="Withholding Tax" description="WITHHOLDING @ 20% ON USD IBKR MANAGED SECURITIES (SYEP) INTEREST FOR MAR-2024" amount="-0.24"`,
+			wantImport:  true,
+			wantSubType: "TAX",
+		},
+		{
+			name:   "dividend withholding of a company named INTEL",
+			cashTx: `type="Withholding Tax" description="INTC CASH DIVIDEND USD 0.125 PER SHARE - US TAX" amount="-1.9"`,
+		},
+		{
+			name:   "dividend withholding mentioning INTERNATIONAL",
+			cashTx: `type="Withholding Tax" description="IBM INTERNATIONAL BUSINESS MACHINES - US TAX" amount="-3"`,
+		},
+		{
+			name:   "dividend withholding with an ISIN",
+			cashTx: `type="Withholding Tax" isin="US4581401001" description="WITHHOLDING @ 20% ON CREDIT INT FOR MAR-2024" amount="-1.9"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xml := `<FlexQueryResponse><FlexStatements><FlexStatement accountId="U1"><CashTransactions>` +
+				`<CashTransaction ` + tt.cashTx + ` dateTime="20240402;120000" currency="EUR" levelOfDetail="DETAIL"/>` +
+				`</CashTransactions></FlexStatement></FlexStatements></FlexQueryResponse>`
+			txs, err := NewParser().Parse(strings.NewReader(xml))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !tt.wantImport {
+				if len(txs) != 0 {
+					t.Fatalf("imported %+v, want the row skipped", txs)
+				}
+				return
+			}
+			if len(txs) != 1 {
+				t.Fatalf("got %d transactions, want 1", len(txs))
+			}
+			if txs[0].TransactionType != "INTEREST" || txs[0].TransactionSubType != tt.wantSubType {
+				t.Errorf("classified %s/%s, want INTEREST/%s", txs[0].TransactionType, txs[0].TransactionSubType, tt.wantSubType)
+			}
+			if txs[0].CountryCode != "" {
+				t.Errorf("CountryCode = %q, want none: the paying entity is not in the statement", txs[0].CountryCode)
+			}
+		})
+	}
+}
+
+func TestParseTradeCommissionCurrency(t *testing.T) {
+	tests := []struct {
+		name               string
+		commissionCurrency string
+		want               string
+	}{
+		{name: "commission in the trade currency", commissionCurrency: "USD"},
+		{name: "commission in the base currency", commissionCurrency: "EUR", want: "EUR"},
+		{name: "no commission currency reported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xml := `<FlexQueryResponse><FlexStatements><FlexStatement accountId="U1"><Trades>` +
+				`<Trade assetCategory="STK" symbol="AAPL" description="APPLE INC" isin="US0378331005" dateTime="20240305;153000" ` +
+				`quantity="10" tradePrice="180" tradeMoney="1800" currency="USD" ibCommission="-1" ibCommissionCurrency="` + tt.commissionCurrency + `" ` +
+				`buySell="BUY" ibOrderID="1"/>` +
+				`</Trades></FlexStatement></FlexStatements></FlexQueryResponse>`
+			txs, err := NewParser().Parse(strings.NewReader(xml))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(txs) != 1 {
+				t.Fatalf("got %d transactions, want 1", len(txs))
+			}
+			if txs[0].Commission != 1 || txs[0].CommissionCurrency != tt.want {
+				t.Errorf("commission %v %q, want 1 %q", txs[0].Commission, txs[0].CommissionCurrency, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	xml := `<FlexQueryResponse><FlexStatements><FlexStatement accountId="U1"><OpenPositions>` +
+		`<OpenPosition assetCategory="STK" symbol="AAPL" description="APPLE INC" isin="US0378331005" reportDate="20240328" ` +
+		`position="10" costBasisMoney="1800" currency="USD" levelOfDetail="SUMMARY"/>` +
+		`<OpenPosition assetCategory="STK" symbol="AAPL" description="APPLE INC" isin="US0378331005" reportDate="20240328" ` +
+		`position="10" costBasisMoney="1800" currency="USD" levelOfDetail="LOT"/>` +
+		`<OpenPosition assetCategory="OPT" symbol="FLW   261218P00031000" description="FLW 18DEC26 31 P" reportDate="20240328" ` +
+		`position="-2" costBasisMoney="-240" currency="EUR" levelOfDetail="SUMMARY" putCall="P" underlyingSymbol="flw" strike="31" expiry="20261218" multiplier="100"/>` +
+		`<OpenPosition assetCategory="CASH" symbol="USD" reportDate="20240328" position="100" currency="USD" levelOfDetail="SUMMARY"/>` +
+		`</OpenPositions></FlexStatement></FlexStatements></FlexQueryResponse>`
+
+	positions, err := NewParser().ParsePositions(strings.NewReader(xml))
+	if err != nil {
+		t.Fatalf("ParsePositions: %v", err)
+	}
+	want := []models.BrokerPosition{
+		{Source: "ibkr", ReportDate: "28-03-2024", AssetType: "STOCK", ISIN: "US0378331005", Symbol: "AAPL", ProductName: "APPLE INC",
+			Quantity: 10, CostBasis: 1800, Currency: "USD"},
+		{Source: "ibkr", ReportDate: "28-03-2024", AssetType: "OPTION", Symbol: "FLW   261218P00031000", ProductName: "FLW 18DEC26 31 P",
+			Quantity: -2, CostBasis: -240, Currency: "EUR", Underlying: "FLW", Strike: 31, Expiry: "18-12-2026", OptionRight: "PUT", Multiplier: 100},
+	}
+	if !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %+v, want %+v", positions, want)
+	}
+}
+
+func TestParseBondAccruedInterest(t *testing.T) {
+	xml := `<FlexQueryResponse><FlexStatements><FlexStatement accountId="U1"><Trades>` +
+		`<Trade assetCategory="BOND" symbol="DBR 0 08/15/30" description="BUND 2030" isin="DE0001102507" dateTime="20240305;153000" ` +
+		`quantity="1000" tradePrice="98" tradeMoney="980" currency="EUR" ibCommission="-1" buySell="BUY" ibOrderID="1" accruedInt="-12.5"/>` +
+		`</Trades><CashTransactions>` +
+		`<CashTransaction type="Bond Interest Paid" description="PURCHASE ACCRUED INT DBR 0 08/15/30" isin="DE0001102507" ` +
+		`dateTime="20240305;000000" amount="-12.5" currency="EUR" levelOfDetail="DETAIL"/>` +
+		`<CashTransaction type="Bond Interest Paid" description="PURCHASE ACCRUED INT OTHER" isin="DE0001102515" ` +
+		`dateTime="20240305;000000" amount="-7.25" currency="EUR" levelOfDetail="DETAIL"/>` +
+		`<CashTransaction type="Bond Interest Received" description="DBR 0 08/15/30 COUPON" isin="DE0001102507" ` +
+		`dateTime="20240815;000000" amount="20" currency="EUR" levelOfDetail="DETAIL"/>` +
+		`</CashTransactions></FlexStatement></FlexStatements></FlexQueryResponse>`
+
+	txs, err := NewParser().Parse(strings.NewReader(xml))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := []struct {
+		isin    string
+		buySell string
+		subType string
+		amount  float64
+	}{
+		{isin: "DE0001102507", buySell: "BUY", amount: -980},
+		{isin: "DE0001102507", subType: "ACCRUED_INTEREST", amount: -12.5},
+		// The cash row repeating the trade's accrued interest is dropped; other bonds' rows are kept.
+		{isin: "DE0001102515", subType: "ACCRUED_INTEREST", amount: -7.25},
+		{isin: "DE0001102507", subType: "COUPON", amount: 20},
+	}
+	if len(txs) != len(want) {
+		t.Fatalf("got %d transactions, want %d: %+v", len(txs), len(want), txs)
+	}
+	for i, w := range want {
+		tx := txs[i]
+		if tx.TransactionType != "BOND" || tx.ISIN != w.isin || tx.BuySell != w.buySell || tx.TransactionSubType != w.subType || tx.Amount != w.amount {
+			t.Errorf("tx %d = %s %s %s/%s %v, want BOND %s %s/%s %v", i, tx.TransactionType, tx.ISIN, tx.BuySell, tx.TransactionSubType, tx.Amount, w.isin, w.buySell, w.subType, w.amount)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// backend/src/parsers/kraken/parser.go
+package kraken
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers/cryptotrade"
+)
+
+var requiredColumns = []string{"txid", "pair", "time", "type", "price", "cost", "fee", "vol"}
+
+// quoteCodes are tried in order to split pairs such as "XXBTZEUR" or "SOLEUR".
+var quoteCodes = []string{"ZEUR", "ZUSD", "ZGBP", "ZCAD", "ZJPY", "ZCHF", "ZAUD", "USDT", "USDC", "XXBT", "XETH",
+	"EUR", "USD", "GBP", "CHF", "CAD", "JPY", "AUD", "XBT", "ETH", "DAI"}
+
+// legacyAssetCodes maps Kraken's X/Z-prefixed and XBT/XDG codes to common tickers.
+var legacyAssetCodes = map[string]string{
+	"XXBT": "BTC", "XBT": "BTC", "XXDG": "DOGE", "XDG": "DOGE", "XETH": "ETH", "XLTC": "LTC", "XXRP": "XRP",
+	"XXLM": "XLM", "XZEC": "ZEC", "XXMR": "XMR", "XETC": "ETC", "XREP": "REP", "XMLN": "MLN",
+	"ZEUR": "EUR", "ZUSD": "USD", "ZGBP": "GBP", "ZCAD": "CAD", "ZJPY": "JPY", "ZCHF": "CHF", "ZAUD": "AUD",
+}
+
+var timeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00"}
+
+// KrakenParser implements the parsers.Parser interface for Kraken "trades" history CSV exports
+// (txid,ordertxid,pair,time,type,ordertype,price,cost,fee,vol,margin,misc,ledgers). Fees are in the quote currency.
+type KrakenParser struct{}
+
+// NewParser creates a new instance of the KrakenParser.
+func NewParser() *KrakenParser {
+	return &KrakenParser{}
+}
+
+// Parse reads a Kraken trades CSV and converts its rows into a slice of CanonicalTransaction.
+func (p *KrakenParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("kraken parser: failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("kraken parser: missing column '%s' in CSV header", name)
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("kraken parser: failed to read all CSV records: %w", err)
+	}
+
+	var canonicalTxs []models.CanonicalTransaction
+	for _, record := range records {
+		if len(record) < len(header) {
+			continue
+		}
+		field := func(name string) string { return strings.TrimSpace(record[columns[name]]) }
+
+		date, err := parseKrakenTime(field("time"))
+		if err != nil {
+			log.Printf("Kraken Parser: Skipping trade due to invalid time: %s (txid: %s)", field("time"), field("txid"))
+			continue
+		}
+		base, quote, ok := cryptotrade.SplitPair(field("pair"), quoteCodes)
+		if !ok {
+			log.Printf("Kraken Parser: Skipping trade with unrecognised pair: '%s'", field("pair"))
+			continue
+		}
+		side := strings.ToUpper(field("type"))
+		if side != "BUY" && side != "SELL" {
+			log.Printf("Kraken Parser: Skipping trade with unknown type: '%s'", field("type"))
+			continue
+		}
+		quote = normaliseAsset(quote)
+
+		canonicalTxs = append(canonicalTxs, cryptotrade.ToCanonical(cryptotrade.Trade{
+			Source:   "kraken",
+			Time:     date,
+			OrderID:  field("txid"),
+			Base:     normaliseAsset(base),
+			Quote:    quote,
+			Side:     side,
+			Quantity: parseNumber(field("vol")),
+			Price:    parseNumber(field("price")),
+			Total:    parseNumber(field("cost")),
+			Fee:      parseNumber(field("fee")),
+			FeeAsset: quote,
+			RawText:  "Kraken|" + strings.Join(record, "|"),
+		})...)
+	}
+
+	cryptotrade.SortChronologically(canonicalTxs)
+	return canonicalTxs, nil
+}
+
+func normaliseAsset(code string) string {
+	code = strings.ToUpper(code)
+	if mapped, ok := legacyAssetCodes[code]; ok {
+		return mapped
+	}
+	return code
+}
+
+func parseKrakenTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not parse kraken time '%s'", value)
+}
+
+func parseNumber(value string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,44 @@
+package kraken
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	csv := "txid,ordertxid,pair,time,type,ordertype,price,cost,fee,vol,margin,misc,ledgers\n" +
+		"T2,O2,XETHXXBT,2024-03-02 10:00:00.1234,sell,limit,0.05,0.1,0.0002,2,0,,L2\n" +
+		"T1,O1,XXBTZEUR,2024-03-01 10:00:00,buy,market,60000,30000,48,0.5,0,,L1\n" +
+		"T3,O3,SOLEUR,2024-03-03 10:00:00,buy,market,100,200,0.32,2,0,,L3\n" +
+		"T4,O4,???,2024-03-03 10:00:00,buy,market,1,1,0,1,0,,L4\n" +
+		"T5,O5,SOLEUR,2024-03-03 10:00:00,settle,market,1,1,0,1,0,,L5\n"
+
+	txs, err := NewParser().Parse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	tests := []struct {
+		orderID, subType, buySell, product, currency string
+		quantity, amount, commission                 float64
+	}{
+		{orderID: "T1", buySell: "BUY", product: "BTC", currency: "EUR", quantity: 0.5, amount: -30000, commission: 48},
+		{orderID: "T2", subType: "SWAP", buySell: "SELL", product: "ETH", currency: "BTC", quantity: 2, amount: 0.1},
+		{orderID: "T2", subType: "SWAP", buySell: "BUY", product: "BTC", currency: "BTC", quantity: 0.1, amount: -0.1},
+		{orderID: "T2", subType: "FEE", buySell: "FEE", product: "BTC", currency: "BTC", quantity: 0.0002},
+		{orderID: "T3", buySell: "BUY", product: "SOL", currency: "EUR", quantity: 2, amount: -200, commission: 0.32},
+	}
+	// Rows are sorted by time; the unknown pair and trade type are skipped.
+	if len(txs) != len(tests) {
+		t.Fatalf("got %d transactions, want %d: %+v", len(txs), len(tests), txs)
+	}
+	for i, tt := range tests {
+		tx := txs[i]
+		if tx.OrderID != tt.orderID || tx.TransactionSubType != tt.subType || tx.BuySell != tt.buySell || tx.ProductName != tt.product || tx.Currency != tt.currency {
+			t.Errorf("row %d: got %s %s/%s %s in %s, want %s %s/%s %s in %s", i, tx.OrderID, tx.TransactionSubType, tx.BuySell, tx.ProductName, tx.Currency, tt.orderID, tt.subType, tt.buySell, tt.product, tt.currency)
+		}
+		if tx.Quantity != tt.quantity || tx.Amount != tt.amount || tx.Commission != tt.commission {
+			t.Errorf("row %d: quantity %v amount %v commission %v, want %v %v %v", i, tx.Quantity, tx.Amount, tx.Commission, tt.quantity, tt.amount, tt.commission)
+		}
+	}
+}
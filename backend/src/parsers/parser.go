@@ -10,3 +10,18 @@ import (
 type Parser interface {
 	Parse(file io.Reader) ([]models.CanonicalTransaction, error)
 }
+
+// Options carries per-upload choices that change what a parser ingests.
+type Options struct {
+	// IncludeFXConversions makes parsers emit currency conversions as "FX" transactions
+	// instead of skipping them. Each conversion is emitted as one row per currency leg.
+	IncludeFXConversions bool
+
+	// Profile is the user's column mapping for the "custom" source; other sources ignore it.
+	Profile *models.ParserProfile
+}
+
+// PositionParser is implemented by parsers that can read a broker-reported open positions snapshot.
+type PositionParser interface {
+	ParsePositions(file io.Reader) ([]models.BrokerPosition, error)
+}
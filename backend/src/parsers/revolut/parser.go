@@ -0,0 +1,169 @@
+// backend/src/parsers/revolut/parser.go
+package revolut
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers/xlsx"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+var requiredColumns = []string{"date", "ticker", "type", "quantity", "price per share", "total amount", "currency"}
+
+// RevolutParser implements the parsers.Parser interface for Revolut trading account statement CSV files.
+type RevolutParser struct{}
+
+// NewParser creates a new instance of the RevolutParser.
+func NewParser() *RevolutParser {
+	return &RevolutParser{}
+}
+
+// Parse reads a Revolut CSV (Date,Ticker,Type,Quantity,Price per share,Total Amount,Currency,FX Rate)
+// and converts its rows into a slice of CanonicalTransaction.
+func (p *RevolutParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("revolut parser: failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("revolut parser: missing column '%s' in CSV header", name)
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("revolut parser: failed to read all CSV records: %w", err)
+	}
+
+	var canonicalTxs []models.CanonicalTransaction
+	for _, record := range records {
+		if len(record) < len(header) {
+			continue
+		}
+		field := func(name string) string { return strings.TrimSpace(record[columns[name]]) }
+
+		date, err := parseRevolutDate(field("date"))
+		if err != nil {
+			log.Printf("Revolut Parser: Skipping row due to invalid date: %s", field("date"))
+			continue
+		}
+
+		ticker := field("ticker")
+		currency := strings.ToUpper(field("currency"))
+		rowType := strings.ToUpper(strings.ReplaceAll(field("type"), "_", " "))
+		quantity := parseNumber(field("quantity"))
+		price := parseNumber(field("price per share"))
+		total := parseNumber(field("total amount"))
+
+		base := models.CanonicalTransaction{
+			Source:          "revolut",
+			TransactionDate: date,
+			ProductName:     ticker,
+			Currency:        currency,
+			RawText:         strings.Join(record, ","),
+			SourceAmount:    total,
+		}
+		// Revolut only reports tickers, so the country of the instrument is not known.
+		if ticker != "" {
+			base.CountryCode = utils.UnknownCountry
+		}
+
+		switch {
+		case strings.HasPrefix(rowType, "BUY"), strings.HasPrefix(rowType, "SELL"):
+			tx := base
+			tx.TransactionType = "STOCK"
+			tx.Quantity = math.Abs(quantity)
+			tx.Price = price
+			if strings.HasPrefix(rowType, "BUY") {
+				tx.BuySell = "BUY"
+				tx.Amount = -math.Abs(total)
+			} else {
+				tx.BuySell = "SELL"
+				tx.Amount = math.Abs(total)
+			}
+			canonicalTxs = append(canonicalTxs, tx)
+
+		case rowType == "DIVIDEND":
+			// The row holds the dividend net of any withholding, which the statement does not itemise.
+			tx := base
+			tx.TransactionType = "DIVIDEND"
+			tx.Amount = math.Abs(total)
+			canonicalTxs = append(canonicalTxs, tx)
+
+		case rowType == "CUSTODY FEE":
+			tx := base
+			tx.TransactionType = "FEE"
+			tx.TransactionSubType = "CUSTODY"
+			tx.ProductName = "Custody Fee"
+			tx.Amount = -math.Abs(total)
+			canonicalTxs = append(canonicalTxs, tx)
+
+		case rowType == "CASH TOP-UP" || rowType == "CASH TOP UP":
+			tx := base
+			tx.TransactionType = "CASH"
+			tx.TransactionSubType = "DEPOSIT"
+			tx.ProductName = "Cash Deposit"
+			tx.Amount = math.Abs(total)
+			canonicalTxs = append(canonicalTxs, tx)
+
+		case rowType == "CASH WITHDRAWAL":
+			tx := base
+			tx.TransactionType = "CASH"
+			tx.TransactionSubType = "WITHDRAWAL"
+			tx.ProductName = "Cash Withdrawal"
+			tx.Amount = -math.Abs(total)
+			canonicalTxs = append(canonicalTxs, tx)
+
+		case rowType == "STOCK SPLIT":
+			// Quantity is the change in shares (negative for reverse splits); no cash moves.
+			tx := base
+			tx.TransactionType = "STOCK"
+			tx.TransactionSubType = "SPLIT"
+			tx.BuySell = "SPLIT"
+			tx.Quantity = quantity
+			canonicalTxs = append(canonicalTxs, tx)
+
+		default:
+			log.Printf("Revolut Parser: Skipping unknown transaction type: '%s'", field("type"))
+		}
+	}
+
+	return canonicalTxs, nil
+}
+
+// parseRevolutDate accepts the ISO timestamps used by current exports and the plain formats of older ones.
+func parseRevolutDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not parse revolut date '%s'", value)
+}
+
+// parseNumber reads amounts such as "USD 1,234.50", "$1,234.50", "-$0.12" or "12,50 €", returning 0 for empty cells.
+// The currency code or symbol is dropped and the rest is read with the locale-aware xlsx.ParseNumber.
+func parseNumber(value string) float64 {
+	var b strings.Builder
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return xlsx.ParseNumber(b.String())
+}
@@ -0,0 +1,77 @@
+package revolut
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"", 0},
+		{"USD 1,234.50", 1234.5},
+		{"$1,234.50", 1234.5},
+		{"-$0.12", -0.12},
+		{"12,50 €", 12.5},
+		{"EUR 1.234,56", 1234.56},
+		{"0.0254", 0.0254},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := parseNumber(tt.value); got != tt.want {
+				t.Errorf("parseNumber(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	csv := strings.Join([]string{
+		"Date,Ticker,Type,Quantity,Price per share,Total Amount,Currency,FX Rate",
+		"2024-01-02T10:00:00.000Z,,CASH TOP-UP,,,\"USD 1,000\",USD,1.10",
+		"2024-01-03T10:00:00.000Z,AAPL,BUY - MARKET,0.5,USD 180,USD 90,USD,1.10",
+		"2024-02-15T10:00:00.000Z,AAPL,DIVIDEND,,,USD 0.10,USD,1.08",
+		"2024-03-01T10:00:00.000Z,,CUSTODY FEE,,,USD -0.12,USD,1.08",
+		"2024-04-01T10:00:00.000Z,AAPL,STOCK SPLIT,1.5,,,USD,1.08",
+		"2024-05-01T10:00:00.000Z,AAPL,SELL - MARKET,2,USD 60,USD 120,USD,1.08",
+		"2024-05-02T10:00:00.000Z,AAPL,SOMETHING NEW,,,USD 1,USD,1.08",
+		"not a date,AAPL,BUY - MARKET,1,USD 1,USD 1,USD,1.08",
+	}, "\n")
+
+	txs, err := NewParser().Parse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	tests := []struct {
+		txType   string
+		subType  string
+		buySell  string
+		quantity float64
+		amount   float64
+		country  string
+	}{
+		{txType: "CASH", subType: "DEPOSIT", amount: 1000},
+		{txType: "STOCK", buySell: "BUY", quantity: 0.5, amount: -90, country: utils.UnknownCountry},
+		{txType: "DIVIDEND", amount: 0.1, country: utils.UnknownCountry},
+		{txType: "FEE", subType: "CUSTODY", amount: -0.12},
+		{txType: "STOCK", subType: "SPLIT", buySell: "SPLIT", quantity: 1.5, country: utils.UnknownCountry},
+		{txType: "STOCK", buySell: "SELL", quantity: 2, amount: 120, country: utils.UnknownCountry},
+	}
+	if len(txs) != len(tests) {
+		t.Fatalf("got %d transactions, want %d: %+v", len(txs), len(tests), txs)
+	}
+	for i, tt := range tests {
+		tx := txs[i]
+		if tx.TransactionType != tt.txType || tx.TransactionSubType != tt.subType || tx.BuySell != tt.buySell {
+			t.Errorf("row %d: classified %s/%s/%s, want %s/%s/%s", i, tx.TransactionType, tx.TransactionSubType, tx.BuySell, tt.txType, tt.subType, tt.buySell)
+		}
+		if tx.Quantity != tt.quantity || tx.Amount != tt.amount || tx.CountryCode != tt.country {
+			t.Errorf("row %d: quantity %v amount %v country %q, want %v %v %q", i, tx.Quantity, tx.Amount, tx.CountryCode, tt.quantity, tt.amount, tt.country)
+		}
+	}
+}
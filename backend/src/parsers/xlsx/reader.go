@@ -0,0 +1,323 @@
+// backend/src/parsers/xlsx/reader.go
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+// Sheet is a worksheet read as plain cell text, one slice per row. Missing cells are empty strings.
+type Sheet struct {
+	Name string
+	Rows [][]string
+}
+
+// --- XML Data Structures (only the parts needed to read cell values) ---
+
+type workbookXML struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"id,attr"` // r:id
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type sharedStringsXML struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type worksheetXML struct {
+	Rows []struct {
+		Cells []struct {
+			Ref        string `xml:"r,attr"`
+			Type       string `xml:"t,attr"`
+			Value      string `xml:"v"`
+			InlineText string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// IsXLSX reports whether the data starts with a zip signature.
+func IsXLSX(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// ReadWorkbook reads every worksheet of an XLSX file, in workbook order.
+// The archive is checked with validation.ValidateXLSXArchive before any entry is decompressed.
+func ReadWorkbook(data []byte) ([]Sheet, error) {
+	if err := validation.ValidateXLSXArchive(bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: failed to open archive: %w", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var workbook workbookXML
+	if err := decodeEntry(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var rels relationshipsXML
+	if err := decodeEntry(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	var sharedStrings []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst sharedStringsXML
+		if err := decodeEntry(files, "xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			text := item.Text
+			for _, run := range item.Runs {
+				text += run.Text
+			}
+			sharedStrings = append(sharedStrings, text)
+		}
+	}
+
+	var sheets []Sheet
+	for _, s := range workbook.Sheets {
+		target, ok := targets[s.RID]
+		if !ok {
+			return nil, fmt.Errorf("xlsx: sheet '%s' has no relationship target", s.Name)
+		}
+		var ws worksheetXML
+		if err := decodeEntry(files, target, &ws); err != nil {
+			return nil, err
+		}
+		sheet := Sheet{Name: s.Name}
+		for _, row := range ws.Rows {
+			var values []string
+			for i, cell := range row.Cells {
+				col := i
+				if cell.Ref != "" {
+					if idx, ok := columnIndex(cell.Ref); ok {
+						col = idx
+					}
+				}
+				for len(values) <= col {
+					values = append(values, "")
+				}
+				values[col] = cellText(cell.Type, cell.Value, cell.InlineText, sharedStrings)
+			}
+			sheet.Rows = append(sheet.Rows, values)
+		}
+		sheets = append(sheets, sheet)
+	}
+	return sheets, nil
+}
+
+func decodeEntry(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("xlsx: missing entry '%s'", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("xlsx: failed to open entry '%s': %w", name, err)
+	}
+	defer rc.Close()
+	// The declared size was checked already; the limit guards against archives that lie about it.
+	limited := io.LimitReader(rc, validation.MaxXLSXEntryBytes)
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: failed to decode entry '%s': %w", name, err)
+	}
+	return nil
+}
+
+func cellText(cellType, value, inlineText string, sharedStrings []string) string {
+	switch cellType {
+	case "s":
+		idx, err := strconv.Atoi(value)
+		if err != nil || idx < 0 || idx >= len(sharedStrings) {
+			return ""
+		}
+		return strings.TrimSpace(sharedStrings[idx])
+	case "inlineStr":
+		return strings.TrimSpace(inlineText)
+	default:
+		return strings.TrimSpace(value)
+	}
+}
+
+// columnIndex converts the letters of a cell reference such as "AB12" to a zero-based column index.
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// excelEpoch is day zero of the 1900 date system as used by Excel (accounting for its 1900 leap-year bug).
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ParseDate reads a date cell that is either an Excel serial number or text in one of the given layouts.
+func ParseDate(value string, layouts ...string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		days := int(serial)
+		seconds := int((serial - float64(days)) * 86400)
+		return excelEpoch.AddDate(0, 0, days).Add(time.Duration(seconds) * time.Second), nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("xlsx: could not parse date '%s'", value)
+}
+
+// FindHeader returns the index of the first row that contains all the given column names (case-insensitive)
+// and a lookup from lower-cased column name to column index for that row.
+func FindHeader(rows [][]string, required ...string) (int, map[string]int, bool) {
+	for i, row := range rows {
+		columns := make(map[string]int, len(row))
+		for j, cell := range row {
+			name := strings.ToLower(strings.TrimSpace(cell))
+			if _, seen := columns[name]; name != "" && !seen {
+				columns[name] = j
+			}
+		}
+		found := true
+		for _, name := range required {
+			if _, ok := columns[name]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i, columns, true
+		}
+	}
+	return -1, nil, false
+}
+
+// Cell returns the trimmed value of a named column in a row, or "" when the column or cell is missing.
+func Cell(row []string, columns map[string]int, name string) string {
+	idx, ok := columns[name]
+	if !ok || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+// ParseNumber reads numeric cells written with either decimal convention ("1,234.56", "1.234,56", "1234,5"),
+// tolerating spaces and thousands separators. The decimal separator is the last separator when both appear;
+// a separator that repeats groups thousands, as does a single comma with one to three digits before it and
+// exactly three after ("1,234"). A lone dot is always a decimal point, as in the raw values of spreadsheet cells.
+// Empty or non-numeric cells return 0.
+func ParseNumber(value string) float64 {
+	value = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(strings.TrimSpace(value))
+	if strings.ContainsAny(value, "eE") {
+		// Spreadsheets store very small and very large cell values in scientific notation ("1.5E-2").
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+		return 0
+	}
+	sign := ""
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		sign, value = value[:1], value[1:]
+	}
+	if value == "" || strings.Trim(value, "0123456789.,") != "" {
+		return 0
+	}
+
+	lastDot := strings.LastIndex(value, ".")
+	lastComma := strings.LastIndex(value, ",")
+	var decimal, thousands string
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal, thousands = ".", ","
+		if lastComma > lastDot {
+			decimal, thousands = ",", "."
+		}
+		if strings.Count(value, decimal) > 1 || strings.Contains(value[strings.LastIndex(value, decimal):], thousands) {
+			return 0
+		}
+	case lastComma >= 0:
+		if strings.Count(value, ",") > 1 || (len(value)-lastComma-1 == 3 && lastComma <= 3 && !strings.HasPrefix(value, "0")) {
+			thousands = ","
+		} else {
+			decimal = ","
+		}
+	case strings.Count(value, ".") > 1:
+		thousands = "."
+	}
+
+	if thousands != "" {
+		integerPart := value
+		if decimal != "" {
+			integerPart = value[:strings.LastIndex(value, decimal)]
+		}
+		if !validThousandsGrouping(strings.Split(integerPart, thousands)) {
+			return 0
+		}
+		value = strings.ReplaceAll(value, thousands, "")
+	}
+	if decimal == "," {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+
+	v, err := strconv.ParseFloat(sign+value, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// validThousandsGrouping checks that the digits around thousands separators form groups of three.
+func validThousandsGrouping(groups []string) bool {
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package xlsx
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"", 0},
+		{"12", 12},
+		{"-12.5", -12.5},
+		{"+3", 3},
+		{"12,50", 12.5},
+		{"0,125", 0.125},
+		{"1234,567", 1234.567},
+		{"1,234", 1234},
+		{"-1,234", -1234},
+		{"1.234", 1.234},
+		{"1,234.56", 1234.56},
+		{"1.234,56", 1234.56},
+		{"1,234,567", 1234567},
+		{"1.234.567", 1234567},
+		{"1.234.567,89", 1234567.89},
+		{"1 234,56", 1234.56},
+		{"1 234,56", 1234.56},
+		{"1'234.56", 1234.56},
+		{"1.5E-2", 0.015},
+		{"1,23,4", 0},
+		{"1.234,5,6", 0},
+		{"12,34.5", 0},
+		{"abc", 0},
+		{"NaN", 0},
+		{"Infinity", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := ParseNumber(tt.value); got != tt.want {
+				t.Errorf("ParseNumber(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
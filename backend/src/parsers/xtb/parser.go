@@ -0,0 +1,288 @@
+// backend/src/parsers/xtb/parser.go
+package xtb
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers/xlsx"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+var dateLayouts = []string{"02.01.2006 15:04:05", "02.01.2006 15:04", "02.01.2006", "2006-01-02 15:04:05", "2006-01-02"}
+
+// tradeCommentRe matches cash operation comments such as "OPEN BUY 10 @ 45.30" or "CLOSE BUY 3/10 @ 50.10".
+var tradeCommentRe = regexp.MustCompile(`(?i)(OPEN|CLOSE)\s+BUY\s+([\d.]+)(?:/[\d.]+)?\s*@\s*([\d.]+)`)
+
+// symbolCurrency maps XTB symbol suffixes to the currency the listing is quoted in. London listings are left out
+// because they are quoted in pence.
+var symbolCurrency = map[string]string{
+	"US": "USD", "DE": "EUR", "FR": "EUR", "NL": "EUR", "ES": "EUR", "PT": "EUR", "IT": "EUR", "BE": "EUR", "FI": "EUR",
+	"IE": "EUR", "DK": "DKK", "SE": "SEK", "CH": "CHF", "PL": "PLN", "NO": "NOK", "CZ": "CZK",
+}
+
+// XTBParser implements the parsers.Parser interface for XTB account statement XLSX files.
+// Cash events and trades come from the "Cash Operations" sheet; the "Closed Positions" sheet is only
+// used for trades when the cash sheet has none (older exports).
+type XTBParser struct{}
+
+// NewParser creates a new instance of the XTBParser.
+func NewParser() *XTBParser {
+	return &XTBParser{}
+}
+
+// Parse reads an XTB XLSX statement and converts its rows into a slice of CanonicalTransaction.
+func (p *XTBParser) Parse(file io.Reader) ([]models.CanonicalTransaction, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("xtb parser: failed to read file: %w", err)
+	}
+	if !xlsx.IsXLSX(data) {
+		return nil, fmt.Errorf("xtb parser: expected an XLSX statement")
+	}
+	sheets, err := xlsx.ReadWorkbook(data)
+	if err != nil {
+		return nil, fmt.Errorf("xtb parser: %w", err)
+	}
+
+	var cashSheet, closedSheet *xlsx.Sheet
+	for i := range sheets {
+		name := strings.ToUpper(sheets[i].Name)
+		switch {
+		case strings.Contains(name, "CASH OPERATION"):
+			cashSheet = &sheets[i]
+		case strings.Contains(name, "CLOSED POSITION"):
+			closedSheet = &sheets[i]
+		}
+	}
+	if cashSheet == nil && closedSheet == nil {
+		return nil, fmt.Errorf("xtb parser: no 'Cash Operations' or 'Closed Positions' sheet found")
+	}
+
+	var canonicalTxs []models.CanonicalTransaction
+	hasTrades := false
+	if cashSheet != nil {
+		txs, err := parseCashOperations(*cashSheet)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range txs {
+			if tx.TransactionType == "STOCK" {
+				hasTrades = true
+			}
+		}
+		canonicalTxs = append(canonicalTxs, txs...)
+	}
+	if closedSheet != nil && !hasTrades {
+		txs, err := parseClosedPositions(*closedSheet)
+		if err != nil {
+			return nil, err
+		}
+		canonicalTxs = append(canonicalTxs, txs...)
+	}
+	return canonicalTxs, nil
+}
+
+func parseCashOperations(sheet xlsx.Sheet) ([]models.CanonicalTransaction, error) {
+	headerIdx, columns, ok := xlsx.FindHeader(sheet.Rows, "id", "type", "time", "comment", "symbol", "amount")
+	if !ok {
+		return nil, fmt.Errorf("xtb parser: cash operations header row not found")
+	}
+	currency := accountCurrency(sheet.Rows[:headerIdx])
+
+	var canonicalTxs []models.CanonicalTransaction
+	for _, row := range sheet.Rows[headerIdx+1:] {
+		opType := strings.ToLower(xlsx.Cell(row, columns, "type"))
+		if opType == "" {
+			continue
+		}
+		date, err := xlsx.ParseDate(xlsx.Cell(row, columns, "time"), dateLayouts...)
+		if err != nil {
+			log.Printf("XTB Parser: Skipping row due to invalid date: %s (ID: %s)", xlsx.Cell(row, columns, "time"), xlsx.Cell(row, columns, "id"))
+			continue
+		}
+
+		symbol := xlsx.Cell(row, columns, "symbol")
+		isin := strings.ToUpper(xlsx.Cell(row, columns, "isin"))
+		comment := xlsx.Cell(row, columns, "comment")
+		amount := xlsx.ParseNumber(xlsx.Cell(row, columns, "amount"))
+
+		tx := models.CanonicalTransaction{
+			Source:          "xtb",
+			TransactionDate: date,
+			ProductName:     symbol,
+			ISIN:            isin,
+			Currency:        currency,
+			OrderID:         xlsx.Cell(row, columns, "id"),
+			RawText:         "XTB|" + strings.Join(row, "|"),
+			SourceAmount:    amount,
+			Amount:          amount, // XTB amounts are already signed from the account's point of view
+			CountryCode:     countryCode(isin),
+		}
+
+		switch {
+		case strings.Contains(opType, "purchase"), strings.Contains(opType, "sale"):
+			matches := tradeCommentRe.FindStringSubmatch(comment)
+			if matches == nil {
+				log.Printf("XTB Parser: Skipping trade with unrecognised comment: '%s'", comment)
+				continue
+			}
+			tx.TransactionType = "STOCK"
+			tx.Quantity, _ = strconv.ParseFloat(matches[2], 64)
+			// The comment quotes the price in the instrument's currency, while the amount (and Currency) are in
+			// the account currency, so the price is restated per share in the account currency.
+			tx.Price, _ = strconv.ParseFloat(matches[3], 64)
+			if tx.Quantity > 0 && amount != 0 {
+				tx.Price = math.Abs(amount) / tx.Quantity
+			}
+			if strings.Contains(opType, "purchase") {
+				tx.BuySell = "BUY"
+				tx.Amount = -math.Abs(amount)
+			} else {
+				tx.BuySell = "SELL"
+				tx.Amount = math.Abs(amount)
+			}
+		case opType == "divident" || opType == "dividend":
+			tx.TransactionType = "DIVIDEND"
+		case opType == "withholding tax":
+			tx.TransactionType = "DIVIDEND"
+			tx.TransactionSubType = "TAX"
+			tx.Amount = -math.Abs(amount)
+		case opType == "deposit":
+			tx.TransactionType = "CASH"
+			tx.TransactionSubType = "DEPOSIT"
+			tx.ProductName = "Cash Deposit"
+		case opType == "withdrawal":
+			tx.TransactionType = "CASH"
+			tx.TransactionSubType = "WITHDRAWAL"
+			tx.ProductName = "Cash Withdrawal"
+			tx.Amount = -math.Abs(amount)
+		case opType == "free-funds interest":
+			// Interest on uninvested cash; the statement does not say which XTB entity paid it.
+			tx.TransactionType = "INTEREST"
+			tx.ProductName = "Free-funds Interest"
+		case opType == "free-funds interest tax":
+			tx.TransactionType = "INTEREST"
+			tx.TransactionSubType = "TAX"
+			tx.ProductName = "Free-funds Interest"
+			tx.Amount = -math.Abs(amount)
+		case strings.Contains(opType, "fee") || strings.Contains(opType, "commission"):
+			tx.TransactionType = "FEE"
+			tx.ProductName = xlsx.Cell(row, columns, "type")
+			tx.Amount = -math.Abs(amount)
+		default:
+			log.Printf("XTB Parser: Skipping unknown cash operation type: '%s'", opType)
+			continue
+		}
+		canonicalTxs = append(canonicalTxs, tx)
+	}
+	return canonicalTxs, nil
+}
+
+// parseClosedPositions turns each closed position into its opening BUY and closing SELL.
+func parseClosedPositions(sheet xlsx.Sheet) ([]models.CanonicalTransaction, error) {
+	headerIdx, columns, ok := xlsx.FindHeader(sheet.Rows, "position", "symbol", "volume", "open time", "open price", "close time", "close price")
+	if !ok {
+		return nil, fmt.Errorf("xtb parser: closed positions header row not found")
+	}
+	currency := accountCurrency(sheet.Rows[:headerIdx])
+
+	var canonicalTxs []models.CanonicalTransaction
+	for _, row := range sheet.Rows[headerIdx+1:] {
+		position := xlsx.Cell(row, columns, "position")
+		symbol := xlsx.Cell(row, columns, "symbol")
+		isin := strings.ToUpper(xlsx.Cell(row, columns, "isin"))
+		if position == "" || symbol == "" {
+			continue
+		}
+		if side := strings.ToUpper(xlsx.Cell(row, columns, "type")); side != "" && side != "BUY" {
+			log.Printf("XTB Parser: Skipping closed %s position %s (only long stock positions are supported)", side, position)
+			continue
+		}
+		openDate, errOpen := xlsx.ParseDate(xlsx.Cell(row, columns, "open time"), dateLayouts...)
+		closeDate, errClose := xlsx.ParseDate(xlsx.Cell(row, columns, "close time"), dateLayouts...)
+		if errOpen != nil || errClose != nil {
+			log.Printf("XTB Parser: Skipping closed position %s due to invalid dates", position)
+			continue
+		}
+
+		volume := xlsx.ParseNumber(xlsx.Cell(row, columns, "volume"))
+		openPrice := xlsx.ParseNumber(xlsx.Cell(row, columns, "open price"))
+		closePrice := xlsx.ParseNumber(xlsx.Cell(row, columns, "close price"))
+		// Newer exports carry the values in account currency, so the prices are restated in it as well. Older ones
+		// only have the prices, in the instrument's currency, so the trades are recorded in that currency.
+		tradeCurrency := currency
+		purchaseValue := xlsx.ParseNumber(xlsx.Cell(row, columns, "purchase value"))
+		saleValue := xlsx.ParseNumber(xlsx.Cell(row, columns, "sale value"))
+		if purchaseValue != 0 && saleValue != 0 && volume > 0 {
+			openPrice = math.Abs(purchaseValue) / volume
+			closePrice = math.Abs(saleValue) / volume
+		} else {
+			purchaseValue = volume * openPrice
+			saleValue = volume * closePrice
+			if instrumentCurrency := symbolCurrencyCode(symbol); instrumentCurrency != "" {
+				tradeCurrency = instrumentCurrency
+			} else {
+				log.Printf("XTB Parser: Unknown quote currency for %s; using the account currency %s", symbol, currency)
+			}
+		}
+		rawText := "XTB|Closed|" + strings.Join(row, "|")
+
+		canonicalTxs = append(canonicalTxs,
+			models.CanonicalTransaction{
+				Source: "xtb", TransactionDate: openDate, ProductName: symbol, ISIN: isin, Quantity: volume, Price: openPrice,
+				Currency: tradeCurrency, OrderID: position, RawText: rawText + "|OPEN", SourceAmount: purchaseValue,
+				Amount: -math.Abs(purchaseValue), TransactionType: "STOCK", BuySell: "BUY", CountryCode: countryCode(isin),
+			},
+			models.CanonicalTransaction{
+				Source: "xtb", TransactionDate: closeDate, ProductName: symbol, ISIN: isin, Quantity: volume, Price: closePrice,
+				Currency: tradeCurrency, OrderID: position, RawText: rawText + "|CLOSE", SourceAmount: saleValue,
+				Amount: math.Abs(saleValue), TransactionType: "STOCK", BuySell: "SELL", CountryCode: countryCode(isin),
+			},
+		)
+	}
+	return canonicalTxs, nil
+}
+
+// accountCurrency finds the "Currency" label in the account summary above the table and returns the value below it.
+func accountCurrency(summaryRows [][]string) string {
+	for i, row := range summaryRows {
+		for j, cell := range row {
+			if strings.EqualFold(strings.TrimSpace(cell), "currency") && i+1 < len(summaryRows) && j < len(summaryRows[i+1]) {
+				if value := strings.ToUpper(strings.TrimSpace(summaryRows[i+1][j])); len(value) == 3 {
+					return value
+				}
+			}
+		}
+	}
+	return "EUR"
+}
+
+// countryCode returns the country of an ISIN. The listing suffix of a symbol says nothing about where the issuer is
+// domiciled (VWCE.DE is an Irish fund), so rows without an ISIN get utils.UnknownCountry.
+func countryCode(isin string) string {
+	if isin == "" {
+		return utils.UnknownCountry
+	}
+	return utils.GetCountryCodeString(isin)
+}
+
+// symbolCurrencyCode returns the quote currency of a symbol's listing, or "" when it is not known.
+func symbolCurrencyCode(symbol string) string {
+	return symbolCurrency[symbolSuffix(symbol)]
+}
+
+// symbolSuffix returns the upper-cased market suffix of a symbol such as "AAPL.US", or "" without one.
+func symbolSuffix(symbol string) string {
+	dot := strings.LastIndex(symbol, ".")
+	if dot < 0 {
+		return ""
+	}
+	return strings.ToUpper(symbol[dot+1:])
+}
@@ -0,0 +1,157 @@
+package xtb
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/parsers/xlsx"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error")
+	if err := utils.InitCountryData("../../../data/country.json"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestParseCashOperations(t *testing.T) {
+	sheet := xlsx.Sheet{Name: "Cash Operations", Rows: [][]string{
+		{"Name and surname", "Account", "Currency"},
+		{"Jane Doe", "123456", "EUR"},
+		{},
+		{"ID", "Type", "Time", "Comment", "Symbol", "Amount"},
+		{"1", "Stocks/ETF purchase", "05.02.2024 10:00:00", "OPEN BUY 10 @ 100.00", "AAPL.US", "-920,50"},
+		{"2", "Stocks/ETF sale", "05.03.2024 10:00:00", "CLOSE BUY 4/10 @ 110.00", "AAPL.US", "404,00"},
+		{"3", "DIVIDENT", "10.03.2024 10:00:00", "AAPL.US USD 0.24/ SHR", "AAPL.US", "1,80"},
+		{"4", "Withholding Tax", "10.03.2024 10:00:00", "AAPL.US USD WHT 15%", "AAPL.US", "-0,27"},
+		{"5", "Deposit", "01.02.2024 09:00:00", "Transfer", "", "1.000,00"},
+		{"6", "Unknown thing", "01.02.2024 09:00:00", "", "", "5"},
+		{"7", "Free-funds Interest", "01.04.2024 00:00:00", "Free-funds Interest 2024-03", "", "2,10"},
+		{"8", "Free-funds Interest Tax", "01.04.2024 00:00:00", "Free-funds Interest Tax 2024-03", "", "-0,40"},
+	}}
+
+	txs, err := parseCashOperations(sheet)
+	if err != nil {
+		t.Fatalf("parseCashOperations: %v", err)
+	}
+
+	tests := []struct {
+		orderID  string
+		txType   string
+		subType  string
+		buySell  string
+		quantity float64
+		price    float64
+		amount   float64
+	}{
+		{orderID: "1", txType: "STOCK", buySell: "BUY", quantity: 10, price: 92.05, amount: -920.5},
+		{orderID: "2", txType: "STOCK", buySell: "SELL", quantity: 4, price: 101, amount: 404},
+		{orderID: "3", txType: "DIVIDEND", amount: 1.8},
+		{orderID: "4", txType: "DIVIDEND", subType: "TAX", amount: -0.27},
+		{orderID: "5", txType: "CASH", subType: "DEPOSIT", amount: 1000},
+		{orderID: "7", txType: "INTEREST", amount: 2.1},
+		{orderID: "8", txType: "INTEREST", subType: "TAX", amount: -0.4},
+	}
+	if len(txs) != len(tests) {
+		t.Fatalf("got %d transactions, want %d: %+v", len(txs), len(tests), txs)
+	}
+	for i, tt := range tests {
+		t.Run(tt.orderID, func(t *testing.T) {
+			tx := txs[i]
+			if tx.OrderID != tt.orderID || tx.TransactionType != tt.txType || tx.TransactionSubType != tt.subType || tx.BuySell != tt.buySell {
+				t.Fatalf("unexpected classification: %+v", tx)
+			}
+			if tx.Quantity != tt.quantity || math.Abs(tx.Price-tt.price) > 1e-9 || tx.Amount != tt.amount {
+				t.Errorf("quantity %v price %v amount %v, want %v %v %v", tx.Quantity, tx.Price, tx.Amount, tt.quantity, tt.price, tt.amount)
+			}
+			if tx.Currency != "EUR" {
+				t.Errorf("Currency = %s, want the account currency EUR", tx.Currency)
+			}
+		})
+	}
+}
+
+func TestParseCountry(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      []string
+		row         []string
+		wantISIN    string
+		wantCountry string
+	}{
+		{
+			name:        "listing suffix is not a country",
+			header:      []string{"ID", "Type", "Time", "Comment", "Symbol", "Amount"},
+			row:         []string{"1", "Stocks/ETF purchase", "05.02.2024 10:00:00", "OPEN BUY 2 @ 110.00", "VWCE.DE", "-220,00"},
+			wantCountry: utils.UnknownCountry,
+		},
+		{
+			name:        "ISIN column gives the country",
+			header:      []string{"ID", "Type", "Time", "Comment", "Symbol", "ISIN", "Amount"},
+			row:         []string{"1", "Stocks/ETF purchase", "05.02.2024 10:00:00", "OPEN BUY 2 @ 110.00", "VWCE.DE", "ie00bk5bqt80", "-220,00"},
+			wantISIN:    "IE00BK5BQT80",
+			wantCountry: utils.GetCountryCodeString("IE00BK5BQT80"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, err := parseCashOperations(xlsx.Sheet{Name: "Cash Operations", Rows: [][]string{tt.header, tt.row}})
+			if err != nil {
+				t.Fatalf("parseCashOperations: %v", err)
+			}
+			if len(txs) != 1 || txs[0].ISIN != tt.wantISIN || txs[0].CountryCode != tt.wantCountry {
+				t.Errorf("got %+v, want ISIN %q country %q", txs, tt.wantISIN, tt.wantCountry)
+			}
+		})
+	}
+}
+
+func TestParseClosedPositionsCurrency(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       []string
+		row          []string
+		wantCurrency string
+		wantPrices   [2]float64
+		wantAmounts  [2]float64
+	}{
+		{
+			name:         "values in account currency",
+			header:       []string{"Position", "Symbol", "Type", "Volume", "Open time", "Open price", "Close time", "Close price", "Purchase value", "Sale value"},
+			row:          []string{"77", "AAPL.US", "BUY", "10", "05.02.2024", "100.00", "05.03.2024", "110.00", "920.00", "1010.00"},
+			wantCurrency: "EUR",
+			wantPrices:   [2]float64{92, 101},
+			wantAmounts:  [2]float64{-920, 1010},
+		},
+		{
+			name:         "prices only are in the instrument currency",
+			header:       []string{"Position", "Symbol", "Type", "Volume", "Open time", "Open price", "Close time", "Close price"},
+			row:          []string{"78", "AAPL.US", "BUY", "10", "05.02.2024", "100.00", "05.03.2024", "110.00"},
+			wantCurrency: "USD",
+			wantPrices:   [2]float64{100, 110},
+			wantAmounts:  [2]float64{-1000, 1100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := xlsx.Sheet{Name: "Closed Positions", Rows: [][]string{{"Currency"}, {"EUR"}, tt.header, tt.row}}
+			txs, err := parseClosedPositions(sheet)
+			if err != nil {
+				t.Fatalf("parseClosedPositions: %v", err)
+			}
+			if len(txs) != 2 {
+				t.Fatalf("got %d transactions, want the opening buy and closing sale", len(txs))
+			}
+			for i, tx := range txs {
+				if tx.Currency != tt.wantCurrency || tx.Price != tt.wantPrices[i] || tx.Amount != tt.wantAmounts[i] {
+					t.Errorf("%s: currency %s price %v amount %v, want %s %v %v", tx.BuySell, tx.Currency, tx.Price, tx.Amount, tt.wantCurrency, tt.wantPrices[i], tt.wantAmounts[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// backend/src/prices/csv.go
+package prices
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+var dateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006"}
+
+// ParseCSV reads closing prices from a CSV with a header row. The "date" and "close" (or "price") columns are
+// required; optional "isin" and "currency" columns override the isin and currency given for the whole file.
+// Both "," and ";" separators are accepted, as are decimal commas when ";" separates the fields.
+func ParseCSV(r io.Reader, isin, currency string) ([]Quote, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file: %w", err)
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := strings.Cut(text, "\n")
+	semicolon := strings.Count(firstLine, ";") > strings.Count(firstLine, ",")
+	if semicolon {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	closeCol, ok := columns["close"]
+	if !ok {
+		closeCol, ok = columns["price"]
+	}
+	dateCol, hasDate := columns["date"]
+	if !ok || !hasDate {
+		return nil, fmt.Errorf("price file must have 'date' and 'close' columns")
+	}
+	isinCol, hasISIN := columns["isin"]
+	currencyCol, hasCurrency := columns["currency"]
+
+	var quotes []Quote
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if field(dateCol) == "" && field(closeCol) == "" {
+			continue
+		}
+
+		q := Quote{ISIN: isin, Currency: currency}
+		if hasISIN && field(isinCol) != "" {
+			q.ISIN = field(isinCol)
+		}
+		if hasCurrency && field(currencyCol) != "" {
+			q.Currency = field(currencyCol)
+		}
+		q.ISIN = strings.ToUpper(q.ISIN)
+		q.Currency = strings.ToUpper(q.Currency)
+		if q.ISIN == "" || q.Currency == "" {
+			return nil, fmt.Errorf("line %d: ISIN and currency are required", line)
+		}
+		// Per-row values bypass the checks the handler applies to the form fields.
+		if err := validation.ValidateISIN(q.ISIN); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := validation.ValidateCurrencyCode(q.Currency); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if q.Date, err = parseDate(field(dateCol)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		value := field(closeCol)
+		if semicolon {
+			value = strings.ReplaceAll(value, ",", ".")
+		}
+		if q.Close, err = strconv.ParseFloat(value, 64); err != nil || q.Close < 0 {
+			return nil, fmt.Errorf("line %d: invalid close price '%s'", line, field(closeCol))
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD or DD-MM-YYYY)", value)
+}
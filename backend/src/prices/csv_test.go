@@ -0,0 +1,79 @@
+package prices
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error")
+	os.Exit(m.Run())
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		csv       string
+		isin      string
+		currency  string
+		want      []Quote
+		wantErr   bool
+		wantValid bool // The error is a validation error
+	}{
+		{
+			name:     "file-wide ISIN and currency",
+			csv:      "Date,Close\n2024-03-01,101.5\n\n02-03-2024,102\n",
+			isin:     "ie00b4l5y983",
+			currency: "eur",
+			want: []Quote{
+				{ISIN: "IE00B4L5Y983", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Close: 101.5, Currency: "EUR"},
+				{ISIN: "IE00B4L5Y983", Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Close: 102, Currency: "EUR"},
+			},
+		},
+		{
+			name:     "per-row columns and decimal commas",
+			csv:      "\ufeffisin;date;price;currency\nUS0378331005;01/03/2024;180,25;usd\n",
+			isin:     "IE00B4L5Y983",
+			currency: "EUR",
+			want:     []Quote{{ISIN: "US0378331005", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Close: 180.25, Currency: "USD"}},
+		},
+		{name: "invalid per-row ISIN", csv: "isin,date,close,currency\nUS03783/1005,2024-03-01,1,USD\n", wantErr: true, wantValid: true},
+		{name: "invalid per-row currency", csv: "isin,date,close,currency\nUS0378331005,2024-03-01,1,DOLLARS\n", wantErr: true, wantValid: true},
+		{name: "missing ISIN", csv: "date,close\n2024-03-01,1\n", currency: "EUR", wantErr: true},
+		{name: "missing close column", csv: "date,value\n2024-03-01,1\n", isin: "US0378331005", currency: "USD", wantErr: true},
+		{name: "invalid date", csv: "date,close\n2024/03/01,1\n", isin: "US0378331005", currency: "USD", wantErr: true},
+		{name: "negative price", csv: "date,close\n2024-03-01,-1\n", isin: "US0378331005", currency: "USD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes, err := ParseCSV(strings.NewReader(tt.csv), tt.isin, tt.currency)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCSV returned %+v, want an error", quotes)
+				}
+				if tt.wantValid && !errors.Is(err, validation.ErrValidationFailed) {
+					t.Errorf("error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(quotes) != len(tt.want) {
+				t.Fatalf("got %d quotes, want %d: %+v", len(quotes), len(tt.want), quotes)
+			}
+			for i, want := range tt.want {
+				if quotes[i] != want {
+					t.Errorf("quote %d = %+v, want %+v", i, quotes[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// backend/src/prices/provider.go
+package prices
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Quote is the end-of-day closing price of an instrument in its trading currency.
+type Quote struct {
+	ISIN     string
+	Date     time.Time
+	Close    float64
+	Currency string
+}
+
+// Provider supplies closing prices for an ISIN. The local directory provider is the default;
+// a market data feed can be added by implementing this interface.
+type Provider interface {
+	Name() string
+	// Fetch returns the quotes of an ISIN between from and to (inclusive). An unknown ISIN yields no quotes and no error.
+	Fetch(isin string, from, to time.Time) ([]Quote, error)
+}
+
+// LocalProvider reads prices from CSV files named "<ISIN>.csv" in a directory, in the format accepted by ParseCSV.
+// Files must carry a currency column.
+type LocalProvider struct {
+	dir string
+}
+
+// NewLocalProvider creates a provider reading price files from dir.
+func NewLocalProvider(dir string) *LocalProvider {
+	return &LocalProvider{dir: dir}
+}
+
+func (p *LocalProvider) Name() string {
+	return "local"
+}
+
+func (p *LocalProvider) Fetch(isin string, from, to time.Time) ([]Quote, error) {
+	isin = strings.ToUpper(strings.TrimSpace(isin))
+	if isin == "" || strings.ContainsAny(isin, `/\.`) {
+		return nil, fmt.Errorf("invalid ISIN '%s'", isin)
+	}
+	file, err := os.Open(filepath.Join(p.dir, isin+".csv"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open price file for %s: %w", isin, err)
+	}
+	defer file.Close()
+
+	quotes, err := ParseCSV(file, isin, "")
+	if err != nil {
+		return nil, fmt.Errorf("price file for %s: %w", isin, err)
+	}
+	var inRange []Quote
+	for _, q := range quotes {
+		if !q.Date.Before(from) && !q.Date.After(to) {
+			inRange = append(inRange, q)
+		}
+	}
+	return inRange, nil
+}
@@ -0,0 +1,100 @@
+// backend/src/prices/store.go
+package prices
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/database"
+)
+
+// storeDateLayout keeps price_date sortable as text.
+const storeDateLayout = "2006-01-02"
+
+// SaveQuotes stores a user's quotes, replacing any price already stored for the same ISIN, date and currency.
+func SaveQuotes(userID int64, quotes []Quote, source string) (int, error) {
+	dbTx, err := database.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error beginning database transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			dbTx.Rollback()
+		}
+	}()
+
+	stmt, err := dbTx.Prepare(`
+		INSERT INTO price_history (user_id, isin, price_date, close, currency, source)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, isin, price_date, currency)
+		DO UPDATE SET close = excluded.close, source = excluded.source, updated_at = CURRENT_TIMESTAMP`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing price insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, q := range quotes {
+		if _, err := stmt.Exec(userID, q.ISIN, q.Date.Format(storeDateLayout), q.Close, q.Currency, source); err != nil {
+			return 0, fmt.Errorf("error storing price for %s on %s: %w", q.ISIN, q.Date.Format(storeDateLayout), err)
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing prices: %w", err)
+	}
+	committed = true
+	return len(quotes), nil
+}
+
+// LatestOnOrBefore returns the most recent quote of an ISIN on or before date. When several currencies are stored
+// for that day, the preferred currency wins. It reports false when no price is stored up to that date.
+func LatestOnOrBefore(userID int64, isin string, date time.Time, preferredCurrency string) (Quote, bool, error) {
+	q := Quote{ISIN: isin}
+	var priceDate string
+	err := database.DB.QueryRow(`
+		SELECT price_date, close, currency FROM price_history
+		WHERE user_id = ? AND isin = ? AND price_date <= ?
+		ORDER BY price_date DESC, currency = ? DESC
+		LIMIT 1`, userID, isin, date.Format(storeDateLayout), preferredCurrency).Scan(&priceDate, &q.Close, &q.Currency)
+	if err == sql.ErrNoRows {
+		return Quote{}, false, nil
+	}
+	if err != nil {
+		return Quote{}, false, fmt.Errorf("error querying price of %s: %w", isin, err)
+	}
+	if q.Date, err = time.Parse(storeDateLayout, priceDate); err != nil {
+		return Quote{}, false, fmt.Errorf("invalid stored price date '%s' for %s: %w", priceDate, isin, err)
+	}
+	return q, true, nil
+}
+
+// History returns the stored quotes of an ISIN up to and including to, oldest first.
+func History(userID int64, isin string, to time.Time) ([]Quote, error) {
+	rows, err := database.DB.Query(`
+		SELECT price_date, close, currency FROM price_history
+		WHERE user_id = ? AND isin = ? AND price_date <= ?
+		ORDER BY price_date ASC, currency ASC`, userID, isin, to.Format(storeDateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("error querying price history of %s: %w", isin, err)
+	}
+	defer rows.Close()
+
+	var quotes []Quote
+	for rows.Next() {
+		q := Quote{ISIN: isin}
+		var priceDate string
+		if err := rows.Scan(&priceDate, &q.Close, &q.Currency); err != nil {
+			return nil, fmt.Errorf("error scanning price history of %s: %w", isin, err)
+		}
+		if q.Date, err = time.Parse(storeDateLayout, priceDate); err != nil {
+			return nil, fmt.Errorf("invalid stored price date '%s' for %s: %w", priceDate, isin, err)
+		}
+		quotes = append(quotes, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price history of %s: %w", isin, err)
+	}
+	return quotes, nil
+}
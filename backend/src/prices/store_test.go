@@ -0,0 +1,54 @@
+package prices
+
+import (
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/database"
+)
+
+func TestLatestOnOrBefore(t *testing.T) {
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	const isin = "US0378331005"
+	quotes := []Quote{
+		{ISIN: isin, Date: day(1), Close: 180, Currency: "USD"},
+		{ISIN: isin, Date: day(4), Close: 182, Currency: "USD"},
+		{ISIN: isin, Date: day(4), Close: 168, Currency: "EUR"},
+	}
+	if _, err := SaveQuotes(1, quotes, "import"); err != nil {
+		t.Fatalf("SaveQuotes: %v", err)
+	}
+	// A re-import replaces the stored price instead of adding a second one.
+	if _, err := SaveQuotes(1, []Quote{{ISIN: isin, Date: day(1), Close: 181, Currency: "USD"}}, "import"); err != nil {
+		t.Fatalf("SaveQuotes: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		userID    int64
+		date      time.Time
+		preferred string
+		wantFound bool
+		wantClose float64
+	}{
+		{name: "no price before the first quote", userID: 1, date: day(0)},
+		{name: "re-imported price", userID: 1, date: day(1), preferred: "USD", wantFound: true, wantClose: 181},
+		{name: "latest earlier price on a day without a quote", userID: 1, date: day(3), preferred: "USD", wantFound: true, wantClose: 181},
+		{name: "preferred currency wins on the same day", userID: 1, date: day(10), preferred: "EUR", wantFound: true, wantClose: 168},
+		{name: "another user's prices are not used", userID: 2, date: day(10), preferred: "USD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, found, err := LatestOnOrBefore(tt.userID, isin, tt.date, tt.preferred)
+			if err != nil {
+				t.Fatalf("LatestOnOrBefore: %v", err)
+			}
+			if found != tt.wantFound || quote.Close != tt.wantClose {
+				t.Errorf("got %+v (found %v), want close %v (found %v)", quote, found, tt.wantClose, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package processors
+
+import (
+	"math"
+	"sort"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// Sub types of "BOND" transactions. Purchases and sales have no sub type.
+const (
+	BondSubTypeCoupon          = "COUPON"
+	BondSubTypeAccruedInterest = "ACCRUED_INTEREST" // Negative when paid on a purchase, positive when received on a sale
+	BondSubTypeRedemption      = "REDEMPTION"       // SELL at maturity
+)
+
+// bondProcessorImpl implements the BondProcessor interface.
+type bondProcessorImpl struct{}
+
+// NewBondProcessor creates a new instance of BondProcessor.
+func NewBondProcessor() BondProcessor {
+	return &bondProcessorImpl{}
+}
+
+// Process matches bond sales and redemptions FIFO per ISIN against purchases at their clean price, and collects
+// coupons and accrued interest as interest income. Accrued interest paid on a purchase is deducted from the
+// interest income of the year it was paid.
+func (p *bondProcessorImpl) Process(transactions []models.ProcessedTransaction) models.BondReport {
+	report := models.BondReport{
+		Sales:          []models.BondSaleDetail{},
+		Holdings:       []models.BondHolding{},
+		Interest:       []models.BondInterestDetail{},
+		Summary:        make(map[string]models.BondYearSummary),
+		MatchingErrors: []models.LotMatchingError{},
+	}
+	openLotsByKey := make(map[string][]*models.ProcessedTransaction)
+
+	for _, tx := range filterAndSortBondTransactions(transactions) {
+		year := utils.ParseDate(tx.Date).Format("2006")
+		lotKey := stockLotKey(tx)
+
+		switch {
+		case tx.TransactionSubType == BondSubTypeCoupon || tx.TransactionSubType == BondSubTypeAccruedInterest:
+			interestType := "COUPON"
+			summary := report.Summary[year]
+			switch {
+			case tx.TransactionSubType == BondSubTypeCoupon:
+				summary.CouponsEUR += tx.AmountEUR
+			case tx.AmountEUR < 0:
+				interestType = "ACCRUED_PAID"
+				summary.AccruedInterestPaidEUR += -tx.AmountEUR
+			default:
+				interestType = "ACCRUED_RECEIVED"
+				summary.AccruedInterestReceivedEUR += tx.AmountEUR
+			}
+			report.Summary[year] = summary
+			report.Interest = append(report.Interest, models.BondInterestDetail{
+				Source:      tx.Source,
+				Date:        tx.Date,
+				ProductName: tx.ProductName,
+				ISIN:        tx.ISIN,
+				Type:        interestType,
+				Amount:      tx.Amount,
+				Currency:    tx.Currency,
+				AmountEUR:   utils.RoundFloat(tx.AmountEUR, 2),
+				CountryCode: transactionCountry(tx),
+			})
+
+		case tx.BuySell == "BUY":
+			purchaseCopy := tx
+			openLotsByKey[lotKey] = append(openLotsByKey[lotKey], &purchaseCopy)
+
+		case tx.BuySell == "SELL":
+			remainingQty := tx.Quantity
+			lots := openLotsByKey[lotKey]
+			for remainingQty > quantityEpsilon && len(lots) > 0 {
+				lot := lots[0]
+				matchedQty := math.Min(remainingQty, lot.Quantity)
+				saleRatio := matchedQty / tx.Quantity
+				var purchaseRatio float64
+				if lot.OriginalQuantity > 0 {
+					purchaseRatio = matchedQty / lot.OriginalQuantity
+				}
+				commission := tx.Commission*saleRatio + lot.Commission
+				lot.Commission = 0
+				buyAmountEUR := utils.RoundFloat(lot.AmountEUR*purchaseRatio, 2)
+				saleAmountEUR := utils.RoundFloat(tx.AmountEUR*saleRatio, 2)
+				delta := utils.RoundFloat(buyAmountEUR+saleAmountEUR, 2)
+
+				report.Sales = append(report.Sales, models.BondSaleDetail{
+					Source:        tx.Source,
+					SaleDate:      tx.Date,
+					BuyDate:       lot.Date,
+					ProductName:   tx.ProductName,
+					ISIN:          tx.ISIN,
+					Quantity:      matchedQty,
+					BuyAmountEUR:  buyAmountEUR,
+					SaleAmountEUR: saleAmountEUR,
+					Commission:    utils.RoundFloat(commission, 2),
+					Delta:         delta,
+					Redemption:    tx.TransactionSubType == BondSubTypeRedemption,
+					CountryCode:   transactionCountry(tx),
+				})
+				summary := report.Summary[year]
+				summary.CapitalGainEUR += delta
+				report.Summary[year] = summary
+
+				remainingQty -= matchedQty
+				lot.Quantity -= matchedQty
+				if lot.Quantity <= quantityEpsilon {
+					lots = lots[1:]
+				}
+			}
+			openLotsByKey[lotKey] = lots
+			if remainingQty > quantityEpsilon {
+				report.MatchingErrors = append(report.MatchingErrors, newLotMatchingError(tx, remainingQty, "sale exceeds the open lots for this bond"))
+			}
+		}
+	}
+
+	for _, lots := range openLotsByKey {
+		for _, lot := range lots {
+			if lot.Quantity <= quantityEpsilon {
+				continue
+			}
+			var costEUR float64
+			if lot.OriginalQuantity > 0 {
+				costEUR = lot.AmountEUR * lot.Quantity / lot.OriginalQuantity
+			}
+			report.Holdings = append(report.Holdings, models.BondHolding{
+				Source:       lot.Source,
+				BuyDate:      lot.Date,
+				ProductName:  lot.ProductName,
+				ISIN:         lot.ISIN,
+				Quantity:     lot.Quantity,
+				BuyAmountEUR: utils.RoundFloat(costEUR, 2),
+				Currency:     lot.Currency,
+				CountryCode:  transactionCountry(*lot),
+			})
+		}
+	}
+	sort.SliceStable(report.Holdings, func(i, j int) bool {
+		if report.Holdings[i].ISIN != report.Holdings[j].ISIN {
+			return report.Holdings[i].ISIN < report.Holdings[j].ISIN
+		}
+		return utils.ParseDate(report.Holdings[i].BuyDate).Before(utils.ParseDate(report.Holdings[j].BuyDate))
+	})
+
+	for year, summary := range report.Summary {
+		summary.CouponsEUR = utils.RoundFloat(summary.CouponsEUR, 2)
+		summary.AccruedInterestReceivedEUR = utils.RoundFloat(summary.AccruedInterestReceivedEUR, 2)
+		summary.AccruedInterestPaidEUR = utils.RoundFloat(summary.AccruedInterestPaidEUR, 2)
+		summary.InterestIncomeEUR = utils.RoundFloat(summary.CouponsEUR+summary.AccruedInterestReceivedEUR-summary.AccruedInterestPaidEUR, 2)
+		summary.CapitalGainEUR = utils.RoundFloat(summary.CapitalGainEUR, 2)
+		report.Summary[year] = summary
+	}
+	return report
+}
+
+// sameDayBondEventRank orders same-day events so that a bond is bought before it is sold or redeemed.
+var sameDayBondEventRank = map[string]int{
+	"BUY":  0,
+	"SELL": 1,
+}
+
+func filterAndSortBondTransactions(transactions []models.ProcessedTransaction) []models.ProcessedTransaction {
+	var bondTxs []models.ProcessedTransaction
+	for _, tx := range transactions {
+		if tx.TransactionType == "BOND" {
+			bondTxs = append(bondTxs, tx)
+		}
+	}
+	sort.SliceStable(bondTxs, func(i, j int) bool {
+		dateI := utils.ParseDate(bondTxs[i].Date)
+		dateJ := utils.ParseDate(bondTxs[j].Date)
+		if dateI.Equal(dateJ) {
+			return sameDayBondEventRank[bondTxs[i].BuySell] < sameDayBondEventRank[bondTxs[j].BuySell]
+		}
+		return dateI.Before(dateJ)
+	})
+	return bondTxs
+}
@@ -0,0 +1,90 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+func bondInterest(id int64, date, subType string, amount float64) models.ProcessedTransaction {
+	tx := bondTrade(id, date, "", 0, amount)
+	tx.TransactionSubType = subType
+	return tx
+}
+
+func TestBondProcess(t *testing.T) {
+	firstBuy := bondTrade(1, "01-03-2023", "BUY", 1000, -990)
+	firstBuy.Commission = 2
+	sale := bondTrade(5, "01-11-2023", "SELL", 1500, 1500)
+	sale.Commission = 3
+	redemption := bondTrade(7, "01-03-2024", "SELL", 500, 500)
+	redemption.TransactionSubType = BondSubTypeRedemption
+
+	transactions2023 := []models.ProcessedTransaction{
+		// Listed out of order: the processor sorts by date.
+		sale,
+		bondInterest(6, "01-11-2023", BondSubTypeAccruedInterest, 10),
+		firstBuy,
+		bondInterest(2, "01-03-2023", BondSubTypeAccruedInterest, -15),
+		bondTrade(3, "01-06-2023", "BUY", 1000, -980),
+		bondInterest(4, "15-09-2023", BondSubTypeCoupon, 30),
+		stockTrade(99, "ibkr", "01-06-2023", "BUY", 10, -100),
+	}
+
+	t.Run("partial sale and interest netting", func(t *testing.T) {
+		report := NewBondProcessor().Process(transactions2023)
+
+		wantSales := []models.BondSaleDetail{
+			{BuyDate: "01-03-2023", Quantity: 1000, BuyAmountEUR: -990, SaleAmountEUR: 1000, Commission: 4, Delta: 10},
+			{BuyDate: "01-06-2023", Quantity: 500, BuyAmountEUR: -490, SaleAmountEUR: 500, Commission: 1, Delta: 10},
+		}
+		if len(report.Sales) != len(wantSales) {
+			t.Fatalf("got %d sales, want %d: %+v", len(report.Sales), len(wantSales), report.Sales)
+		}
+		for i, want := range wantSales {
+			got := report.Sales[i]
+			if got.BuyDate != want.BuyDate || got.Quantity != want.Quantity || got.BuyAmountEUR != want.BuyAmountEUR ||
+				got.SaleAmountEUR != want.SaleAmountEUR || got.Commission != want.Commission || got.Delta != want.Delta || got.Redemption {
+				t.Errorf("sale %d = %+v, want %+v", i, got, want)
+			}
+		}
+
+		if len(report.Holdings) != 1 || report.Holdings[0].Quantity != 500 || report.Holdings[0].BuyAmountEUR != -490 {
+			t.Errorf("holdings = %+v, want 500 nominal left of the second lot at -490", report.Holdings)
+		}
+
+		want := models.BondYearSummary{CouponsEUR: 30, AccruedInterestReceivedEUR: 10, AccruedInterestPaidEUR: 15, InterestIncomeEUR: 25, CapitalGainEUR: 20}
+		if got := report.Summary["2023"]; got != want {
+			t.Errorf("2023 summary = %+v, want %+v", got, want)
+		}
+		if len(report.Interest) != 3 || report.Interest[0].Type != "ACCRUED_PAID" || report.Interest[1].Type != "COUPON" || report.Interest[2].Type != "ACCRUED_RECEIVED" {
+			t.Errorf("interest = %+v, want ACCRUED_PAID, COUPON, ACCRUED_RECEIVED", report.Interest)
+		}
+	})
+
+	t.Run("redemption", func(t *testing.T) {
+		report := NewBondProcessor().Process(append(transactions2023, redemption))
+
+		last := report.Sales[len(report.Sales)-1]
+		if !last.Redemption || last.SaleDate != "01-03-2024" || last.Quantity != 500 || last.Delta != 10 {
+			t.Errorf("redemption sale = %+v, want the remaining 500 nominal redeemed with a gain of 10", last)
+		}
+		if len(report.Holdings) != 0 {
+			t.Errorf("holdings = %+v, want none after the redemption", report.Holdings)
+		}
+		if got := report.Summary["2024"]; got.CapitalGainEUR != 10 || got.InterestIncomeEUR != 0 {
+			t.Errorf("2024 summary = %+v, want only the redemption gain", got)
+		}
+		if len(report.MatchingErrors) != 0 {
+			t.Errorf("unexpected matching errors: %+v", report.MatchingErrors)
+		}
+	})
+
+	t.Run("sale beyond the open lots", func(t *testing.T) {
+		oversold := bondTrade(8, "01-04-2024", "SELL", 100, 100)
+		report := NewBondProcessor().Process(append(transactions2023, redemption, oversold))
+		if len(report.MatchingErrors) != 1 {
+			t.Fatalf("got %d matching errors, want 1: %+v", len(report.MatchingErrors), report.MatchingErrors)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package processors
+
+import (
+	"math"
+	"sort"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// cashLedgerTolerance absorbs rounding in broker amounts before a balance is reported as negative.
+const cashLedgerTolerance = 0.01
+
+// cashMovement is the change a transaction makes to its account's cash in one currency.
+type cashMovement struct {
+	tx       models.ProcessedTransaction
+	currency string
+	amount   float64
+}
+
+// cashLedgerProcessor implements the CashLedgerProcessor interface.
+type cashLedgerProcessor struct{}
+
+// NewCashLedgerProcessor creates a new instance of CashLedgerProcessor.
+func NewCashLedgerProcessor() CashLedgerProcessor {
+	return &cashLedgerProcessor{}
+}
+
+// Process replays the signed amounts of all transactions into a running balance per broker and currency.
+// Commissions are deducted from their trades unless the broker also books them as fee rows.
+func (p *cashLedgerProcessor) Process(transactions []models.ProcessedTransaction) models.CashLedgerReport {
+	report := models.CashLedgerReport{
+		Entries:  []models.CashLedgerEntry{},
+		Balances: []models.CashBalance{},
+	}
+	feeOrders := feeOrderKeys(transactions)
+	balances := make(map[string]*models.CashBalance)
+	var order []string
+
+	var movements []cashMovement
+	for _, tx := range sortTransactionsChronologically(transactions) {
+		movements = append(movements, cashEffect(tx, feeOrders)...)
+	}
+	// Within a day, inflows come first: the order a broker settles a day's rows in should not show as a negative balance.
+	sort.SliceStable(movements, func(i, j int) bool {
+		dateI := utils.ParseDate(movements[i].tx.Date)
+		dateJ := utils.ParseDate(movements[j].tx.Date)
+		if !dateI.Equal(dateJ) {
+			return dateI.Before(dateJ)
+		}
+		return movements[i].amount > 0 && movements[j].amount < 0
+	})
+
+	for _, m := range movements {
+		tx, amount := m.tx, m.amount
+		key := tx.Source + "|" + m.currency
+		balance, ok := balances[key]
+		if !ok {
+			balance = &models.CashBalance{Source: tx.Source, Currency: m.currency}
+			balances[key] = balance
+			order = append(order, key)
+		}
+		balance.Balance += amount
+		if balance.Balance < balance.LowestBalance || balance.LowestBalanceDate == "" {
+			balance.LowestBalance = balance.Balance
+			balance.LowestBalanceDate = tx.Date
+		}
+
+		report.Entries = append(report.Entries, models.CashLedgerEntry{
+			TransactionID:      tx.ID,
+			Date:               tx.Date,
+			Source:             tx.Source,
+			Currency:           m.currency,
+			TransactionType:    tx.TransactionType,
+			TransactionSubType: tx.TransactionSubType,
+			BuySell:            tx.BuySell,
+			ProductName:        tx.ProductName,
+			Amount:             utils.RoundFloat(amount, 2),
+			Balance:            utils.RoundFloat(balance.Balance, 2),
+		})
+	}
+
+	for _, key := range order {
+		balance := balances[key]
+		balance.Balance = utils.RoundFloat(balance.Balance, 2)
+		balance.LowestBalance = utils.RoundFloat(balance.LowestBalance, 2)
+		balance.Negative = balance.LowestBalance < -cashLedgerTolerance
+		report.Balances = append(report.Balances, *balance)
+	}
+	sort.SliceStable(report.Balances, func(i, j int) bool {
+		if report.Balances[i].Source != report.Balances[j].Source {
+			return report.Balances[i].Source < report.Balances[j].Source
+		}
+		return report.Balances[i].Currency < report.Balances[j].Currency
+	})
+	return report
+}
+
+// cashEffect returns the changes a transaction makes to its account's cash by currency: the amount in the
+// transaction currency, and any commission not booked as a fee row in the currency it was charged in.
+func cashEffect(tx models.ProcessedTransaction, feeOrders map[string]bool) []cashMovement {
+	if !movesCash(tx) {
+		return nil
+	}
+	var movements []cashMovement
+	add := func(currency string, amount float64) {
+		if amount == 0 {
+			return
+		}
+		for i := range movements {
+			if movements[i].currency == currency {
+				movements[i].amount += amount
+				return
+			}
+		}
+		movements = append(movements, cashMovement{tx: tx, currency: currency, amount: amount})
+	}
+	add(tx.Currency, tx.Amount)
+	if chargesCommission(tx, feeOrders) {
+		add(commissionCurrency(tx), -math.Abs(tx.Commission))
+	}
+	return movements
+}
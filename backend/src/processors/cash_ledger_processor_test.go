@@ -0,0 +1,75 @@
+package processors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+func TestCashLedgerProcessor(t *testing.T) {
+	usdDeposit := models.ProcessedTransaction{ID: 1, Date: "01-02-2024", Source: "ibkr", TransactionType: "CASH", TransactionSubType: "DEPOSIT", Currency: "USD", Amount: 1000, AmountEUR: 920, ExchangeRate: 1.087}
+	eurDeposit := models.ProcessedTransaction{ID: 2, Date: "01-02-2024", Source: "ibkr", TransactionType: "CASH", TransactionSubType: "DEPOSIT", Currency: "EUR", Amount: 10, AmountEUR: 10, ExchangeRate: 1}
+	usdBuy := func(commission float64, commissionCurrency string) models.ProcessedTransaction {
+		return models.ProcessedTransaction{ID: 3, Date: "05-02-2024", Source: "ibkr", OrderID: "7", TransactionType: "STOCK", BuySell: "BUY", Quantity: 4,
+			Currency: "USD", Amount: -800, AmountEUR: -736, ExchangeRate: 1.087, Commission: commission, CommissionCurrency: commissionCurrency}
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.ProcessedTransaction
+		want         map[string]float64
+		wantNegative []string
+	}{
+		{
+			name:         "commission in the trade currency",
+			transactions: []models.ProcessedTransaction{usdDeposit, usdBuy(1, "")},
+			want:         map[string]float64{"ibkr|USD": 199},
+		},
+		{
+			name:         "commission in the base currency is deducted from that account",
+			transactions: []models.ProcessedTransaction{usdDeposit, eurDeposit, usdBuy(1, "EUR")},
+			want:         map[string]float64{"ibkr|EUR": 9, "ibkr|USD": 200},
+		},
+		{
+			name: "commission booked as a fee row is deducted once",
+			transactions: []models.ProcessedTransaction{
+				eurDeposit,
+				{ID: 3, Date: "05-02-2024", Source: "degiro", OrderID: "A1", TransactionType: "STOCK", BuySell: "BUY", Quantity: 1, Currency: "EUR", Amount: -8, AmountEUR: -8, ExchangeRate: 1, Commission: 2},
+				{ID: 4, Date: "05-02-2024", Source: "degiro", OrderID: "A1", TransactionType: "FEE", TransactionSubType: "COMMISSION", Currency: "EUR", Amount: -2, AmountEUR: -2, ExchangeRate: 1},
+			},
+			want:         map[string]float64{"degiro|EUR": -10, "ibkr|EUR": 10},
+			wantNegative: []string{"degiro|EUR"},
+		},
+		{
+			name: "transfers and splits move no cash",
+			transactions: []models.ProcessedTransaction{
+				eurDeposit,
+				{ID: 3, Date: "05-02-2024", Source: "ibkr", TransactionType: "STOCK", BuySell: "TRANSFER_IN", Quantity: 5, Currency: "EUR", Amount: -500, AmountEUR: -500, ExchangeRate: 1},
+				{ID: 4, Date: "06-02-2024", Source: "ibkr", TransactionType: "STOCK", BuySell: "SPLIT", Quantity: 5, Currency: "EUR", ExchangeRate: 1},
+			},
+			want: map[string]float64{"ibkr|EUR": 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewCashLedgerProcessor().Process(tt.transactions)
+			got := make(map[string]float64)
+			var negative []string
+			for _, balance := range report.Balances {
+				key := balance.Source + "|" + balance.Currency
+				got[key] = balance.Balance
+				if balance.Negative {
+					negative = append(negative, key)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("balances = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(negative, tt.wantNegative) {
+				t.Errorf("negative balances = %v, want %v", negative, tt.wantNegative)
+			}
+		})
+	}
+}
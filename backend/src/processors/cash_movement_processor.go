@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"math"
 	"strings"
 
 	"github.com/username/taxfolio/backend/src/models"
@@ -14,25 +15,32 @@ func NewCashMovementProcessor() CashMovementProcessor {
 	return &cashMovementProcessor{}
 }
 
-// Process identifies cash deposits and withdrawals from the list of processed transactions.
+// Process identifies cash deposits and withdrawals from the list of processed transactions, oldest first.
+// Withdrawals have negative amounts.
 func (p *cashMovementProcessor) Process(transactions []models.ProcessedTransaction) []models.CashMovement {
 	var cashMovements []models.CashMovement
 
-	for _, tx := range transactions {
-		// Check for cash deposits
-		if strings.ToLower(tx.TransactionType) == "cash" && strings.ToLower(tx.TransactionSubType) == "deposit" {
-			movement := models.CashMovement{
-				Date:     tx.Date,
-				Type:     "deposit", // Currently only handling deposits
-				Amount:   tx.Amount,
-				Currency: tx.Currency,
-			}
-			cashMovements = append(cashMovements, movement)
+	for _, tx := range sortTransactionsChronologically(transactions) {
+		if strings.ToLower(tx.TransactionType) != "cash" {
+			continue
 		}
-		// TODO: Add logic for withdrawals if needed, e.g., check for a specific OrderType or Description
+		var movementType string
+		var amount float64
+		switch strings.ToLower(tx.TransactionSubType) {
+		case "deposit":
+			movementType, amount = "deposit", math.Abs(tx.Amount)
+		case "withdrawal":
+			movementType, amount = "withdrawal", -math.Abs(tx.Amount)
+		default:
+			continue
+		}
+		cashMovements = append(cashMovements, models.CashMovement{
+			Date:     tx.Date,
+			Type:     movementType,
+			Amount:   amount,
+			Currency: tx.Currency,
+		})
 	}
 
-	// TODO: Consider sorting cashMovements by date if necessary
-
 	return cashMovements
 }
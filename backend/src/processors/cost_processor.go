@@ -0,0 +1,223 @@
+package processors
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// Cost categories.
+const (
+	CostCategoryCommission   = "COMMISSION"
+	CostCategoryConnectivity = "CONNECTIVITY"
+	CostCategoryFX           = "FX"
+	CostCategoryCustody      = "CUSTODY"
+	CostCategoryOther        = "OTHER"
+)
+
+// costProcessorImpl implements the CostProcessor interface.
+type costProcessorImpl struct{}
+
+// NewCostProcessor creates a new instance of CostProcessor.
+func NewCostProcessor() CostProcessor {
+	return &costProcessorImpl{}
+}
+
+// Process totals the fee rows and the trade commissions not also booked as fee rows (DeGiro books each commission
+// twice). Fees paid in a crypto asset have no EUR amount and are left out. dailyValues, the portfolio value at the
+// end of each day, gives the average value the costs are compared with.
+func (p *costProcessorImpl) Process(transactions []models.ProcessedTransaction, dailyValues []models.PerformancePoint) models.CostReport {
+	report := models.CostReport{
+		Items: []models.CostItem{},
+		Years: make(map[string]models.CostYearSummary),
+		Total: newCostYearSummary(),
+	}
+	feeOrders := feeOrderKeys(transactions)
+
+	for _, tx := range transactions {
+		date := utils.ParseDate(tx.Date)
+		if date.IsZero() {
+			continue
+		}
+		year := date.Format("2006")
+
+		if volume := tradedVolumeEUR(tx); volume > 0 {
+			summary := costYearSummary(report.Years, year)
+			summary.TradedVolumeEUR += volume
+			report.Years[year] = summary
+			report.Total.TradedVolumeEUR += volume
+		}
+
+		var item models.CostItem
+		switch {
+		case tx.TransactionType == "FEE":
+			item = models.CostItem{Category: feeCategory(tx), Amount: -tx.Amount, AmountEUR: -tx.AmountEUR, Currency: tx.Currency}
+		case chargesCommission(tx, feeOrders):
+			category := CostCategoryCommission
+			if tx.TransactionType == "FX" {
+				category = CostCategoryFX
+			}
+			item = models.CostItem{Category: category, Amount: math.Abs(tx.Commission), AmountEUR: commissionEUR(tx), Currency: commissionCurrency(tx)}
+		default:
+			continue
+		}
+		item.Date = tx.Date
+		item.Source = tx.Source
+		item.ProductName = tx.ProductName
+		item.OrderID = tx.OrderID
+		item.Amount = utils.RoundFloat(item.Amount, 2)
+		report.Items = append(report.Items, item)
+
+		summary := costYearSummary(report.Years, year)
+		addCost(&summary.CostBreakdown, item)
+		summary.ByBroker[tx.Source] = addedCost(summary.ByBroker[tx.Source], item)
+		summary.ByCurrency[item.Currency] = addedCost(summary.ByCurrency[item.Currency], item)
+		report.Years[year] = summary
+		addCost(&report.Total.CostBreakdown, item)
+		report.Total.ByBroker[tx.Source] = addedCost(report.Total.ByBroker[tx.Source], item)
+		report.Total.ByCurrency[item.Currency] = addedCost(report.Total.ByCurrency[item.Currency], item)
+	}
+
+	valueSums := make(map[string]float64)
+	valueDays := make(map[string]int)
+	var totalValue float64
+	for _, point := range dailyValues {
+		year := utils.ParseDate(point.Date).Format("2006")
+		valueSums[year] += point.ValueEUR
+		valueDays[year]++
+		totalValue += point.ValueEUR
+	}
+	for year, summary := range report.Years {
+		if valueDays[year] > 0 {
+			summary.AveragePortfolioValueEUR = valueSums[year] / float64(valueDays[year])
+		}
+		report.Years[year] = finishCostYearSummary(summary)
+	}
+	if len(dailyValues) > 0 {
+		report.Total.AveragePortfolioValueEUR = totalValue / float64(len(dailyValues))
+	}
+	report.Total = finishCostYearSummary(report.Total)
+
+	for i := range report.Items {
+		report.Items[i].AmountEUR = utils.RoundFloat(report.Items[i].AmountEUR, 2)
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return utils.ParseDate(report.Items[i].Date).Before(utils.ParseDate(report.Items[j].Date))
+	})
+	return report
+}
+
+// costYearSummary returns the summary of year so far, or a new one.
+func costYearSummary(years map[string]models.CostYearSummary, year string) models.CostYearSummary {
+	if summary, ok := years[year]; ok {
+		return summary
+	}
+	return newCostYearSummary()
+}
+
+func newCostYearSummary() models.CostYearSummary {
+	return models.CostYearSummary{
+		ByBroker:   make(map[string]models.CostBreakdown),
+		ByCurrency: make(map[string]models.CostBreakdown),
+	}
+}
+
+// commissionCurrency returns the currency a transaction's commission was charged in.
+func commissionCurrency(tx models.ProcessedTransaction) string {
+	if tx.CommissionCurrency != "" {
+		return tx.CommissionCurrency
+	}
+	return tx.Currency
+}
+
+// feeCategory classifies a fee row by its subtype, falling back to the order it belongs to and its description for
+// rows stored before the parsers set a subtype.
+func feeCategory(tx models.ProcessedTransaction) string {
+	switch tx.TransactionSubType {
+	case CostCategoryCommission, CostCategoryConnectivity, CostCategoryFX, CostCategoryCustody:
+		return tx.TransactionSubType
+	}
+	text := strings.ToLower(tx.InputString + " " + tx.Description + " " + tx.ProductName)
+	switch {
+	case strings.Contains(text, "autofx") || strings.Contains(text, "auto fx"):
+		return CostCategoryFX
+	case strings.Contains(text, "conectividade") || strings.Contains(text, "connectivity"):
+		return CostCategoryConnectivity
+	case strings.Contains(text, "custody") || strings.Contains(text, "custódia"):
+		return CostCategoryCustody
+	case tx.OrderID != "" || strings.Contains(text, "comiss") || strings.Contains(text, "commission"):
+		return CostCategoryCommission
+	}
+	return CostCategoryOther
+}
+
+// tradedVolumeEUR returns the EUR value of a purchase or sale of securities or crypto, and zero for anything else.
+func tradedVolumeEUR(tx models.ProcessedTransaction) float64 {
+	if tx.BuySell != "BUY" && tx.BuySell != "SELL" {
+		return 0
+	}
+	switch tx.TransactionType {
+	case "STOCK", "OPTION", "BOND":
+		return math.Abs(tx.AmountEUR)
+	case "CRYPTO":
+		if tx.TransactionSubType == "SWAP" {
+			return 0
+		}
+		return math.Abs(tx.AmountEUR)
+	}
+	return 0
+}
+
+func addCost(breakdown *models.CostBreakdown, item models.CostItem) {
+	switch item.Category {
+	case CostCategoryCommission:
+		breakdown.CommissionsEUR += item.AmountEUR
+	case CostCategoryConnectivity:
+		breakdown.ConnectivityEUR += item.AmountEUR
+	case CostCategoryFX:
+		breakdown.FXCostsEUR += item.AmountEUR
+	case CostCategoryCustody:
+		breakdown.CustodyEUR += item.AmountEUR
+	default:
+		breakdown.OtherEUR += item.AmountEUR
+	}
+	breakdown.TotalEUR += item.AmountEUR
+}
+
+func addedCost(breakdown models.CostBreakdown, item models.CostItem) models.CostBreakdown {
+	addCost(&breakdown, item)
+	return breakdown
+}
+
+// finishCostYearSummary computes the percentages and rounds the amounts.
+func finishCostYearSummary(summary models.CostYearSummary) models.CostYearSummary {
+	if summary.TradedVolumeEUR > 0 {
+		summary.CostOfVolumePct = utils.RoundFloat(summary.TotalEUR/summary.TradedVolumeEUR*100, 4)
+	}
+	if summary.AveragePortfolioValueEUR > 0 {
+		summary.CostOfPortfolioPct = utils.RoundFloat(summary.TotalEUR/summary.AveragePortfolioValueEUR*100, 4)
+	}
+	summary.CostBreakdown = roundCostBreakdown(summary.CostBreakdown)
+	for broker, breakdown := range summary.ByBroker {
+		summary.ByBroker[broker] = roundCostBreakdown(breakdown)
+	}
+	for currency, breakdown := range summary.ByCurrency {
+		summary.ByCurrency[currency] = roundCostBreakdown(breakdown)
+	}
+	summary.TradedVolumeEUR = utils.RoundFloat(summary.TradedVolumeEUR, 2)
+	summary.AveragePortfolioValueEUR = utils.RoundFloat(summary.AveragePortfolioValueEUR, 2)
+	return summary
+}
+
+func roundCostBreakdown(breakdown models.CostBreakdown) models.CostBreakdown {
+	breakdown.CommissionsEUR = utils.RoundFloat(breakdown.CommissionsEUR, 2)
+	breakdown.ConnectivityEUR = utils.RoundFloat(breakdown.ConnectivityEUR, 2)
+	breakdown.FXCostsEUR = utils.RoundFloat(breakdown.FXCostsEUR, 2)
+	breakdown.CustodyEUR = utils.RoundFloat(breakdown.CustodyEUR, 2)
+	breakdown.OtherEUR = utils.RoundFloat(breakdown.OtherEUR, 2)
+	breakdown.TotalEUR = utils.RoundFloat(breakdown.TotalEUR, 2)
+	return breakdown
+}
@@ -0,0 +1,54 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+func TestCostProcessor(t *testing.T) {
+	usdTrade := func(id int64, source, orderID string, commission float64, commissionCurrency string) models.ProcessedTransaction {
+		return models.ProcessedTransaction{ID: id, Date: "05-03-2024", Source: source, OrderID: orderID, ProductName: "ACME",
+			TransactionType: "STOCK", BuySell: "BUY", Quantity: 10, Amount: -1100, AmountEUR: -1000, Currency: "USD", ExchangeRate: 1.1,
+			Commission: commission, CommissionCurrency: commissionCurrency}
+	}
+	fee := func(id int64, subType, orderID, description string, amountEUR float64) models.ProcessedTransaction {
+		return models.ProcessedTransaction{ID: id, Date: "05-03-2024", Source: "degiro", OrderID: orderID, TransactionType: "FEE",
+			TransactionSubType: subType, Description: description, Amount: amountEUR, AmountEUR: amountEUR, Currency: "EUR", ExchangeRate: 1}
+	}
+
+	tests := []struct {
+		name         string
+		tx           []models.ProcessedTransaction
+		wantCategory string
+		wantCurrency string
+		wantEUR      float64
+	}{
+		{name: "commission in the trade currency", tx: []models.ProcessedTransaction{usdTrade(1, "ibkr", "1", 2.2, "")}, wantCategory: CostCategoryCommission, wantCurrency: "USD", wantEUR: 2},
+		{name: "commission in another currency", tx: []models.ProcessedTransaction{usdTrade(1, "ibkr", "1", 2, "EUR")}, wantCategory: CostCategoryCommission, wantCurrency: "EUR", wantEUR: 2},
+		{
+			name:         "commission booked as a fee row is counted once",
+			tx:           []models.ProcessedTransaction{usdTrade(1, "degiro", "A1", 2.2, ""), fee(2, CostCategoryCommission, "A1", "", -2)},
+			wantCategory: CostCategoryCommission, wantCurrency: "EUR", wantEUR: 2,
+		},
+		{name: "AutoFX fee", tx: []models.ProcessedTransaction{fee(1, CostCategoryFX, "", "", -0.5)}, wantCategory: CostCategoryFX, wantCurrency: "EUR", wantEUR: 0.5},
+		{name: "custody fee", tx: []models.ProcessedTransaction{fee(1, CostCategoryCustody, "", "", -3)}, wantCategory: CostCategoryCustody, wantCurrency: "EUR", wantEUR: 3},
+		{name: "AutoFX fee stored without a subtype", tx: []models.ProcessedTransaction{fee(1, "", "", "Custo de câmbio AutoFX", -0.5)}, wantCategory: CostCategoryFX, wantCurrency: "EUR", wantEUR: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewCostProcessor().Process(tt.tx, nil)
+			if len(report.Items) != 1 {
+				t.Fatalf("got %d cost items, want 1: %+v", len(report.Items), report.Items)
+			}
+			item := report.Items[0]
+			if item.Category != tt.wantCategory || item.Currency != tt.wantCurrency || item.AmountEUR != tt.wantEUR {
+				t.Errorf("item = %s %s %v EUR, want %s %s %v EUR", item.Category, item.Currency, item.AmountEUR, tt.wantCategory, tt.wantCurrency, tt.wantEUR)
+			}
+			if got := report.Total.ByCurrency[tt.wantCurrency].TotalEUR; got != tt.wantEUR {
+				t.Errorf("ByCurrency[%s] = %v, want %v", tt.wantCurrency, got, tt.wantEUR)
+			}
+		})
+	}
+}
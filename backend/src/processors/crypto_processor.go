@@ -0,0 +1,323 @@
+package processors
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// CryptoExemptHoldingDays is the holding period from which crypto gains are exempt.
+const CryptoExemptHoldingDays = 365
+
+// stablecoinPegs values stablecoins at their peg when a swap has no fiat leg.
+var stablecoinPegs = map[string]string{
+	"USDT": "USD", "USDC": "USD", "BUSD": "USD", "FDUSD": "USD", "TUSD": "USD", "USDP": "USD", "DAI": "USD",
+	"EURC": "EUR", "EURT": "EUR",
+}
+
+// cryptoLot is an acquired quantity of a crypto asset still held.
+type cryptoLot struct {
+	source         string
+	asset          string
+	date           string
+	remaining      float64
+	costPerUnitEUR float64
+}
+
+// cryptoProcessorImpl implements the CryptoProcessor interface.
+type cryptoProcessorImpl struct{}
+
+// NewCryptoProcessor creates a new instance of CryptoProcessor.
+func NewCryptoProcessor() CryptoProcessor {
+	return &cryptoProcessorImpl{}
+}
+
+// cryptoState carries the open lots, the last known EUR price per asset and the report being built.
+type cryptoState struct {
+	lotsByAsset  map[string][]*cryptoLot
+	lastPriceEUR map[string]float64
+	report       *models.CryptoGainReport
+}
+
+// Process matches crypto disposals FIFO per asset, pooled across exchanges since coins move freely between them.
+// Sales against fiat are valued at their EUR amount net of fees. Crypto-to-crypto swaps dispose of the asset given up
+// at the EUR value of the swap, which becomes the cost of the asset received; the value comes from a stablecoin leg,
+// or else the last EUR price seen for either asset. Fees paid in crypto consume lots without realising a result.
+func (p *cryptoProcessorImpl) Process(transactions []models.ProcessedTransaction) models.CryptoGainReport {
+	report := models.CryptoGainReport{
+		Details:        []models.CryptoDisposalDetail{},
+		Holdings:       []models.CryptoLot{},
+		Summary:        make(map[string]models.CryptoYearSummary),
+		MatchingErrors: []models.LotMatchingError{},
+	}
+	state := &cryptoState{
+		lotsByAsset:  make(map[string][]*cryptoLot),
+		lastPriceEUR: make(map[string]float64),
+		report:       &report,
+	}
+
+	cryptoTxs := filterAndSortCryptoTransactions(transactions)
+	swapLegs := make(map[string][]models.ProcessedTransaction)
+	for _, tx := range cryptoTxs {
+		if tx.TransactionSubType == "SWAP" {
+			key := swapKey(tx)
+			swapLegs[key] = append(swapLegs[key], tx)
+		}
+	}
+	handledSwaps := make(map[string]bool)
+
+	for _, tx := range cryptoTxs {
+		switch {
+		case tx.TransactionSubType == "SWAP":
+			key := swapKey(tx)
+			if handledSwaps[key] {
+				continue
+			}
+			handledSwaps[key] = true
+			state.processSwap(swapLegs[key])
+
+		case tx.TransactionSubType == "FEE":
+			if _, unmatched := state.takeLots(tx.ProductName, tx.Quantity); unmatched > quantityEpsilon {
+				report.MatchingErrors = append(report.MatchingErrors, newLotMatchingError(tx, unmatched, "fee exceeds the open lots for this asset"))
+			}
+
+		case tx.BuySell == "BUY":
+			cost := math.Abs(tx.AmountEUR) + commissionEUR(tx)
+			state.addLot(tx, cost)
+			if tx.Quantity > 0 {
+				state.lastPriceEUR[tx.ProductName] = math.Abs(tx.AmountEUR) / tx.Quantity
+			}
+
+		case tx.BuySell == "SELL":
+			proceeds := math.Abs(tx.AmountEUR) - commissionEUR(tx)
+			state.dispose(tx, proceeds, false)
+			if tx.Quantity > 0 {
+				state.lastPriceEUR[tx.ProductName] = math.Abs(tx.AmountEUR) / tx.Quantity
+			}
+		}
+	}
+
+	for _, lots := range state.lotsByAsset {
+		for _, lot := range lots {
+			if lot.remaining <= quantityEpsilon {
+				continue
+			}
+			report.Holdings = append(report.Holdings, models.CryptoLot{
+				Source:          lot.source,
+				Asset:           lot.asset,
+				AcquisitionDate: lot.date,
+				Quantity:        lot.remaining,
+				CostEUR:         utils.RoundFloat(lot.remaining*lot.costPerUnitEUR, 2),
+			})
+		}
+	}
+	sort.SliceStable(report.Holdings, func(i, j int) bool {
+		if report.Holdings[i].Asset != report.Holdings[j].Asset {
+			return report.Holdings[i].Asset < report.Holdings[j].Asset
+		}
+		return utils.ParseDate(report.Holdings[i].AcquisitionDate).Before(utils.ParseDate(report.Holdings[j].AcquisitionDate))
+	})
+
+	for year, summary := range report.Summary {
+		summary.ProceedsEUR = utils.RoundFloat(summary.ProceedsEUR, 2)
+		summary.CostEUR = utils.RoundFloat(summary.CostEUR, 2)
+		summary.ExemptGainEUR = utils.RoundFloat(summary.ExemptGainEUR, 2)
+		summary.TaxableGainEUR = utils.RoundFloat(summary.TaxableGainEUR, 2)
+		if summary.TaxableGainEUR > 0 {
+			summary.EstimatedTaxEUR = utils.RoundFloat(summary.TaxableGainEUR*AutonomousTaxRate, 2)
+		}
+		report.Summary[year] = summary
+	}
+	return report
+}
+
+// processSwap values a crypto-to-crypto trade and turns it into a disposal of the asset given up
+// and an acquisition of the asset received at the same EUR value.
+func (s *cryptoState) processSwap(legs []models.ProcessedTransaction) {
+	var given, received *models.ProcessedTransaction
+	for i := range legs {
+		switch legs[i].BuySell {
+		case "SELL":
+			given = &legs[i]
+		case "BUY":
+			received = &legs[i]
+		}
+	}
+
+	value, valued := 0.0, false
+	for _, leg := range []*models.ProcessedTransaction{given, received} {
+		if leg == nil || valued {
+			continue
+		}
+		value, valued = s.valueEUR(leg.ProductName, leg.Quantity, utils.ParseDate(leg.Date))
+	}
+
+	if given != nil {
+		// Without a price the swap is valued at the cost of what was given up, so it realises no result.
+		consumedCost := s.dispose(*given, value, !valued)
+		if !valued {
+			value = consumedCost
+		}
+	}
+	if received != nil {
+		s.addLot(*received, value)
+	}
+	if valued {
+		for _, leg := range []*models.ProcessedTransaction{given, received} {
+			if leg != nil && leg.Quantity > 0 {
+				s.lastPriceEUR[leg.ProductName] = value / leg.Quantity
+			}
+		}
+	}
+}
+
+// valueEUR returns the EUR value of a quantity of an asset from its stablecoin peg or its last known price.
+func (s *cryptoState) valueEUR(asset string, quantity float64, date time.Time) (float64, bool) {
+	if peg, ok := stablecoinPegs[asset]; ok {
+		if rate, err := GetExchangeRate(peg, date); err == nil && rate > 0 {
+			return quantity / rate, true
+		}
+	}
+	if price, ok := s.lastPriceEUR[asset]; ok {
+		return quantity * price, true
+	}
+	return 0, false
+}
+
+func (s *cryptoState) addLot(tx models.ProcessedTransaction, costEUR float64) {
+	if tx.Quantity <= 0 {
+		return
+	}
+	s.lotsByAsset[tx.ProductName] = append(s.lotsByAsset[tx.ProductName], &cryptoLot{
+		source:         tx.Source,
+		asset:          tx.ProductName,
+		date:           tx.Date,
+		remaining:      tx.Quantity,
+		costPerUnitEUR: costEUR / tx.Quantity,
+	})
+}
+
+// dispose matches a disposal FIFO against the asset's lots and records a detail per matched lot.
+// With atCost the proceeds equal the matched cost (unvalued swap). It returns the total cost consumed.
+func (s *cryptoState) dispose(tx models.ProcessedTransaction, proceedsEUR float64, atCost bool) float64 {
+	if tx.Quantity <= 0 {
+		return 0
+	}
+	disposalType := "SELL"
+	if tx.TransactionSubType == "SWAP" {
+		disposalType = "SWAP"
+	}
+	disposalDate := utils.ParseDate(tx.Date)
+	year := disposalDate.Format("2006")
+	proceedsPerUnit := proceedsEUR / tx.Quantity
+
+	taken, unmatched := s.takeLots(tx.ProductName, tx.Quantity)
+	totalCost := 0.0
+	for _, part := range taken {
+		cost := part.remaining * part.costPerUnitEUR
+		totalCost += cost
+		proceeds := part.remaining * proceedsPerUnit
+		if atCost {
+			proceeds = cost
+		}
+		holdingDays := int(disposalDate.Sub(utils.ParseDate(part.date)).Hours() / 24)
+		exempt := holdingDays >= CryptoExemptHoldingDays
+
+		costRounded := utils.RoundFloat(cost, 2)
+		proceedsRounded := utils.RoundFloat(proceeds, 2)
+		s.report.Details = append(s.report.Details, models.CryptoDisposalDetail{
+			Source:            tx.Source,
+			Asset:             tx.ProductName,
+			AcquisitionDate:   part.date,
+			DisposalDate:      tx.Date,
+			Quantity:          part.remaining,
+			CostEUR:           costRounded,
+			ProceedsEUR:       proceedsRounded,
+			Delta:             utils.RoundFloat(proceedsRounded-costRounded, 2),
+			HoldingDays:       holdingDays,
+			Exempt:            exempt,
+			DisposalType:      disposalType,
+			ValuationFallback: atCost,
+		})
+
+		summary := s.report.Summary[year]
+		summary.ProceedsEUR += proceeds
+		summary.CostEUR += cost
+		if exempt {
+			summary.ExemptGainEUR += proceeds - cost
+		} else {
+			summary.TaxableGainEUR += proceeds - cost
+		}
+		s.report.Summary[year] = summary
+	}
+	if unmatched > quantityEpsilon {
+		s.report.MatchingErrors = append(s.report.MatchingErrors, newLotMatchingError(tx, unmatched, "disposal exceeds the open lots for this asset"))
+	}
+	return totalCost
+}
+
+// takeLots removes up to quantity from the front of the asset's lots. The returned lots hold the taken quantity.
+func (s *cryptoState) takeLots(asset string, quantity float64) ([]cryptoLot, float64) {
+	var taken []cryptoLot
+	lots := s.lotsByAsset[asset]
+	for quantity > quantityEpsilon && len(lots) > 0 {
+		lot := lots[0]
+		matched := math.Min(quantity, lot.remaining)
+		part := *lot
+		part.remaining = matched
+		taken = append(taken, part)
+		quantity -= matched
+		lot.remaining -= matched
+		if lot.remaining <= quantityEpsilon {
+			lots = lots[1:]
+		}
+	}
+	s.lotsByAsset[asset] = lots
+	return taken, quantity
+}
+
+// commissionEUR converts a transaction's commission to EUR. A commission charged in another currency than the
+// trade's is converted at that currency's own rate on the trade date.
+func commissionEUR(tx models.ProcessedTransaction) float64 {
+	if tx.Commission == 0 {
+		return 0
+	}
+	if tx.CommissionCurrency != "" && tx.CommissionCurrency != tx.Currency {
+		rate, err := GetExchangeRate(tx.CommissionCurrency, utils.ParseDate(tx.Date))
+		if err != nil || rate <= 0 {
+			logger.L.Warn("Could not find exchange rate for commission, defaulting to 1.0", "currency", tx.CommissionCurrency, "date", tx.Date, "orderID", tx.OrderID, "error", err)
+			rate = 1.0
+		}
+		return math.Abs(tx.Commission) / rate
+	}
+	if tx.ExchangeRate <= 0 {
+		return 0
+	}
+	return math.Abs(tx.Commission) / tx.ExchangeRate
+}
+
+func swapKey(tx models.ProcessedTransaction) string {
+	return tx.Source + "|" + tx.Date + "|" + tx.OrderID
+}
+
+func filterAndSortCryptoTransactions(transactions []models.ProcessedTransaction) []models.ProcessedTransaction {
+	var cryptoTxs []models.ProcessedTransaction
+	for _, tx := range transactions {
+		if tx.TransactionType == "CRYPTO" {
+			cryptoTxs = append(cryptoTxs, tx)
+		}
+	}
+	sort.SliceStable(cryptoTxs, func(i, j int) bool {
+		dateI := utils.ParseDate(cryptoTxs[i].Date)
+		dateJ := utils.ParseDate(cryptoTxs[j].Date)
+		if dateI.Equal(dateJ) {
+			return cryptoTxs[i].ID < cryptoTxs[j].ID
+		}
+		return dateI.Before(dateJ)
+	})
+	return cryptoTxs
+}
@@ -0,0 +1,78 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+// cryptoTrade builds a CRYPTO trade against EUR; amount follows the parsers' sign convention.
+func cryptoTrade(id int64, date, buySell, asset string, quantity, amount float64) models.ProcessedTransaction {
+	return models.ProcessedTransaction{
+		ID: id, Date: date, Source: "kraken", TransactionType: "CRYPTO", BuySell: buySell, ProductName: asset,
+		Quantity: quantity, Currency: "EUR", ExchangeRate: 1, Amount: amount, AmountEUR: amount,
+	}
+}
+
+func TestCryptoProcessorExemption(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []models.ProcessedTransaction
+		wantExempt   float64
+		wantTaxable  float64
+		wantTax      float64
+		wantErrors   int
+	}{
+		{
+			name: "held exactly 365 days is exempt",
+			transactions: []models.ProcessedTransaction{
+				cryptoTrade(1, "01-03-2023", "BUY", "BTC", 1, -20000),
+				cryptoTrade(2, "29-02-2024", "SELL", "BTC", 1, 30000),
+			},
+			wantExempt: 10000,
+		},
+		{
+			name: "held 364 days is taxed at the autonomous rate",
+			transactions: []models.ProcessedTransaction{
+				cryptoTrade(1, "01-03-2023", "BUY", "BTC", 1, -20000),
+				cryptoTrade(2, "28-02-2024", "SELL", "BTC", 1, 30000),
+			},
+			wantTaxable: 10000,
+			wantTax:     2800,
+		},
+		{
+			name: "FIFO across lots splits the result",
+			transactions: []models.ProcessedTransaction{
+				cryptoTrade(1, "01-01-2023", "BUY", "ETH", 1, -1000),
+				cryptoTrade(2, "01-12-2023", "BUY", "ETH", 1, -2000),
+				cryptoTrade(3, "01-06-2024", "SELL", "ETH", 2, 6000),
+			},
+			wantExempt:  2000,
+			wantTaxable: 1000,
+			wantTax:     280,
+		},
+		{
+			name: "sale beyond the open lots is reported",
+			transactions: []models.ProcessedTransaction{
+				cryptoTrade(1, "01-01-2024", "BUY", "ETH", 1, -1000),
+				cryptoTrade(2, "01-06-2024", "SELL", "ETH", 2, 3000),
+			},
+			wantTaxable: 500,
+			wantTax:     140,
+			wantErrors:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewCryptoProcessor().Process(tt.transactions)
+			summary := report.Summary["2024"]
+			if summary.ExemptGainEUR != tt.wantExempt || summary.TaxableGainEUR != tt.wantTaxable || summary.EstimatedTaxEUR != tt.wantTax {
+				t.Errorf("exempt %v taxable %v tax %v, want %v %v %v", summary.ExemptGainEUR, summary.TaxableGainEUR, summary.EstimatedTaxEUR, tt.wantExempt, tt.wantTaxable, tt.wantTax)
+			}
+			if len(report.MatchingErrors) != tt.wantErrors {
+				t.Errorf("got %d matching errors, want %d", len(report.MatchingErrors), tt.wantErrors)
+			}
+		})
+	}
+}
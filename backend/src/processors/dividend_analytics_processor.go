@@ -0,0 +1,136 @@
+package processors
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// dividendHistory collects the gross payments of one ISIN, summed per payment date.
+type dividendHistory struct {
+	analytics models.DividendHoldingAnalytics
+	payments  map[time.Time]float64
+}
+
+// Analyze computes per-ISIN dividend analytics as of asOf. Withholding tax rows are ignored; yield on cost is
+// measured against the open long lots in holdings, and only ISINs still held are projected. Year-over-year growth
+// is only given for years completed before asOf.
+func (p *dividendProcessorImpl) Analyze(transactions []models.ProcessedTransaction, holdings []models.PurchaseLot, asOf time.Time) models.DividendAnalytics {
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	trailingStart := asOf.AddDate(-1, 0, 0)
+
+	openCost := make(map[string]float64)
+	for _, lot := range holdings {
+		if lot.Quantity <= 0 {
+			continue
+		}
+		key := lot.ISIN
+		if key == "" {
+			key = "TICKER:" + lot.ProductName
+		}
+		openCost[key] -= lot.BuyAmountEUR
+	}
+
+	histories := make(map[string]*dividendHistory)
+	var order []string
+	for _, tx := range transactions {
+		if tx.TransactionType != "DIVIDEND" || tx.TransactionSubType == "TAX" {
+			continue
+		}
+		date := utils.ParseDate(tx.Date)
+		if date.IsZero() || date.After(asOf) {
+			continue
+		}
+		key := stockLotKey(tx)
+		history, ok := histories[key]
+		if !ok {
+			history = &dividendHistory{
+				analytics: models.DividendHoldingAnalytics{
+					ISIN:               tx.ISIN,
+					ProductName:        tx.ProductName,
+					YearlyEUR:          make(map[string]float64),
+					YearOverYearGrowth: make(map[string]float64),
+				},
+				payments: make(map[time.Time]float64),
+			}
+			histories[key] = history
+			order = append(order, key)
+		}
+		history.analytics.YearlyEUR[strconv.Itoa(date.Year())] += tx.AmountEUR
+		history.analytics.MonthlyEUR[date.Month()-1] += tx.AmountEUR
+		history.payments[date] += tx.AmountEUR
+	}
+
+	result := models.DividendAnalytics{
+		Date:     asOf.Format("02-01-2006"),
+		Holdings: []models.DividendHoldingAnalytics{},
+		Calendar: []models.ProjectedDividend{},
+	}
+	for _, key := range order {
+		history := histories[key]
+		a := &history.analytics
+
+		var lastPayment time.Time
+		for date, amount := range history.payments {
+			if date.After(lastPayment) {
+				lastPayment = date
+			}
+			if !date.After(trailingStart) {
+				continue
+			}
+			a.TrailingTwelveMonthsEUR += amount
+			a.PaymentsPerYear++
+			if openCost[key] > 0 {
+				result.Calendar = append(result.Calendar, models.ProjectedDividend{
+					Date:        date.AddDate(1, 0, 0).Format("02-01-2006"),
+					ISIN:        a.ISIN,
+					ProductName: a.ProductName,
+					AmountEUR:   utils.RoundFloat(amount, 2),
+				})
+				result.ProjectedTwelveMonthsEUR += amount
+			}
+		}
+		a.LastPaymentDate = lastPayment.Format("02-01-2006")
+
+		a.OpenCostEUR = utils.RoundFloat(openCost[key], 2)
+		if a.OpenCostEUR > 0 {
+			a.YieldOnCost = utils.RoundFloat(a.TrailingTwelveMonthsEUR/a.OpenCostEUR*100, 2)
+		}
+		for year, amount := range a.YearlyEUR {
+			y, _ := strconv.Atoi(year)
+			// The year of asOf is still running; comparing it with a full year would show a drop.
+			if y >= asOf.Year() {
+				continue
+			}
+			if previous := a.YearlyEUR[strconv.Itoa(y-1)]; previous > 0 {
+				a.YearOverYearGrowth[year] = utils.RoundFloat((amount-previous)/previous*100, 2)
+			}
+		}
+		for year, amount := range a.YearlyEUR {
+			a.YearlyEUR[year] = utils.RoundFloat(amount, 2)
+		}
+		for month := range a.MonthlyEUR {
+			result.MonthlyEUR[month] += a.MonthlyEUR[month]
+			a.MonthlyEUR[month] = utils.RoundFloat(a.MonthlyEUR[month], 2)
+		}
+		result.TrailingTwelveMonthsEUR += a.TrailingTwelveMonthsEUR
+		a.TrailingTwelveMonthsEUR = utils.RoundFloat(a.TrailingTwelveMonthsEUR, 2)
+		result.Holdings = append(result.Holdings, *a)
+	}
+
+	for month := range result.MonthlyEUR {
+		result.MonthlyEUR[month] = utils.RoundFloat(result.MonthlyEUR[month], 2)
+	}
+	result.TrailingTwelveMonthsEUR = utils.RoundFloat(result.TrailingTwelveMonthsEUR, 2)
+	result.ProjectedTwelveMonthsEUR = utils.RoundFloat(result.ProjectedTwelveMonthsEUR, 2)
+	sort.SliceStable(result.Holdings, func(i, j int) bool {
+		return result.Holdings[i].TrailingTwelveMonthsEUR > result.Holdings[j].TrailingTwelveMonthsEUR
+	})
+	sort.SliceStable(result.Calendar, func(i, j int) bool {
+		return utils.ParseDate(result.Calendar[i].Date).Before(utils.ParseDate(result.Calendar[j].Date))
+	})
+	return result
+}
@@ -0,0 +1,89 @@
+package processors
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+func dividend(isin, productName, date string, amountEUR float64) models.ProcessedTransaction {
+	return models.ProcessedTransaction{Date: date, Source: "degiro", ProductName: productName, ISIN: isin, TransactionType: "DIVIDEND",
+		Amount: amountEUR, AmountEUR: amountEUR, Currency: "EUR", ExchangeRate: 1}
+}
+
+func TestDividendAnalyze(t *testing.T) {
+	withholding := dividend("NL0000000001", "ACME", "15-03-2024", -1.5)
+	withholding.TransactionSubType = "TAX"
+	transactions := []models.ProcessedTransaction{
+		dividend("NL0000000001", "ACME", "15-03-2022", 10),
+		dividend("NL0000000001", "ACME", "15-09-2022", 10),
+		dividend("NL0000000001", "ACME", "15-03-2023", 12),
+		dividend("NL0000000001", "ACME", "15-09-2023", 13),
+		dividend("NL0000000001", "ACME", "15-03-2024", 5),
+		withholding,
+		dividend("NL0000000001", "ACME", "15-07-2024", 7), // After asOf
+		dividend("NL0000000002", "SOLD CO", "01-12-2023", 40),
+		stockTrade(1, "degiro", "01-01-2022", "BUY", 10, -1000),
+	}
+	holdings := []models.PurchaseLot{
+		{ISIN: "NL0000000001", ProductName: "ACME", Quantity: 6, BuyAmountEUR: -600},
+		{ISIN: "NL0000000001", ProductName: "ACME", Quantity: 4, BuyAmountEUR: -400},
+		{ISIN: "NL0000000001", ProductName: "ACME", Quantity: -2, BuyAmountEUR: 250}, // Short lots earn no yield
+	}
+	asOf := time.Date(2024, 6, 30, 15, 0, 0, 0, time.UTC)
+
+	result := NewDividendProcessor().Analyze(transactions, holdings, asOf)
+
+	if result.Date != "30-06-2024" || result.TrailingTwelveMonthsEUR != 58 || result.ProjectedTwelveMonthsEUR != 18 {
+		t.Errorf("totals: date %s trailing %v projected %v, want 30-06-2024 58 18", result.Date, result.TrailingTwelveMonthsEUR, result.ProjectedTwelveMonthsEUR)
+	}
+	wantMonthly := [12]float64{2: 27, 8: 23, 11: 40}
+	if result.MonthlyEUR != wantMonthly {
+		t.Errorf("monthly = %v, want %v", result.MonthlyEUR, wantMonthly)
+	}
+
+	if len(result.Holdings) != 2 {
+		t.Fatalf("got %d holdings, want 2: %+v", len(result.Holdings), result.Holdings)
+	}
+	sold, acme := result.Holdings[0], result.Holdings[1]
+	if sold.ISIN != "NL0000000002" || sold.TrailingTwelveMonthsEUR != 40 || sold.OpenCostEUR != 0 || sold.YieldOnCost != 0 || len(sold.YearOverYearGrowth) != 0 {
+		t.Errorf("sold holding = %+v, want 40 trailing, no cost, yield or growth", sold)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "trailing twelve months", got: acme.TrailingTwelveMonthsEUR, want: 18.0},
+		{name: "payments per year", got: acme.PaymentsPerYear, want: 2},
+		{name: "last payment", got: acme.LastPaymentDate, want: "15-03-2024"},
+		{name: "open cost", got: acme.OpenCostEUR, want: 1000.0},
+		{name: "yield on cost", got: acme.YieldOnCost, want: 1.8},
+		{name: "yearly", got: acme.YearlyEUR, want: map[string]float64{"2022": 20, "2023": 25, "2024": 5}},
+		// 2024 is still running on asOf and has no growth yet.
+		{name: "year over year growth", got: acme.YearOverYearGrowth, want: map[string]float64{"2023": 25}},
+		{name: "calendar", got: result.Calendar, want: []models.ProjectedDividend{
+			{Date: "15-09-2024", ISIN: "NL0000000001", ProductName: "ACME", AmountEUR: 13},
+			{Date: "15-03-2025", ISIN: "NL0000000001", ProductName: "ACME", AmountEUR: 5},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	t.Run("growth of a completed year", func(t *testing.T) {
+		result := NewDividendProcessor().Analyze(transactions, holdings, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+		for _, h := range result.Holdings {
+			if h.ISIN == "NL0000000001" && h.YearOverYearGrowth["2024"] != -52 {
+				t.Errorf("2024 growth = %v, want -52 once the year is complete", h.YearOverYearGrowth["2024"])
+			}
+		}
+	})
+}
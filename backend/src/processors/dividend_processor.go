@@ -36,10 +36,10 @@ func (p *dividendProcessorImpl) Calculate(transactions []models.ProcessedTransac
 		}
 		year := parsedTime.Format("2006") // Extract the year as string "YYYY"
 
-		if len(t.ISIN) < 2 {
+		countryFormattedString := transactionCountry(t)
+		if countryFormattedString == "" {
 			continue
 		}
-		countryFormattedString := utils.GetCountryCodeString(t.ISIN)
 		amount := roundToTwoDecimalPlaces(t.AmountEUR)
 
 		if _, ok := result[year]; !ok {
@@ -80,10 +80,10 @@ func (p *dividendProcessorImpl) CalculateTaxSummary(transactions []models.Proces
 		year := parsedTime.Format("2006") // Extract the year as string "YYYY"
 
 		// Get the formatted country string (e.g., "840 - United States of America (the)")
-		if len(t.ISIN) < 2 {
-			continue // Skip invalid ISINs
+		countryFormattedString := transactionCountry(t)
+		if countryFormattedString == "" {
+			continue // Skip transactions without a usable ISIN or country
 		}
-		countryFormattedString := utils.GetCountryCodeString(t.ISIN)
 
 		// Use AmountEUR directly and round it
 		amount := roundToTwoDecimalPlaces(t.AmountEUR)
@@ -123,3 +123,16 @@ func (p *dividendProcessorImpl) CalculateTaxSummary(transactions []models.Proces
 func roundToTwoDecimalPlaces(value float64) float64 {
 	return math.Round(value*100) / 100
 }
+
+// transactionCountry returns the formatted country for a transaction, derived from its ISIN when there is one
+// and otherwise from the country the parser assigned (brokers such as Revolut do not report ISINs).
+func transactionCountry(t models.ProcessedTransaction) string {
+	if len(t.ISIN) >= 2 {
+		return utils.GetCountryCodeString(t.ISIN)
+	}
+	// Rows without an ISIN that no parser assigned a country to carry the "invalid ISIN" placeholder.
+	if t.ISIN == "" && t.CountryCode != utils.GetCountryCodeString("") {
+		return t.CountryCode
+	}
+	return ""
+}
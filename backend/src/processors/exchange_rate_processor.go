@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort" // Import the sort package
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/username/taxfolio/backend/src/logger"
@@ -15,39 +16,76 @@ import (
 var historicalRates models.ExchangeRate
 var ratesLoaded bool = false
 
+// ratesMu guards historicalRates so the rate file can be reloaded while requests are being served.
+var ratesMu sync.RWMutex
+var ratesFileModTime time.Time
+
 // LoadHistoricalRates loads rates from the specified file path.
 func LoadHistoricalRates(filePath string) error {
 	logger.L.Info("Loading historical exchange rates", "path", filePath)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		logger.L.Error("Error reading historical exchange rate file", "path", filePath, "error", err)
+		return fmt.Errorf("error reading historical exchange rate file '%s': %w", filePath, err)
+	}
 	file, err := os.ReadFile(filePath)
 	if err != nil {
 		logger.L.Error("Error reading historical exchange rate file", "path", filePath, "error", err)
 		return fmt.Errorf("error reading historical exchange rate file '%s': %w", filePath, err)
 	}
 
-	err = json.Unmarshal(file, &historicalRates)
+	var loaded models.ExchangeRate
+	err = json.Unmarshal(file, &loaded)
 	if err != nil {
 		logger.L.Error("Error unmarshalling historical exchange rates", "path", filePath, "error", err)
 		return fmt.Errorf("error unmarshalling historical exchange rates from '%s': %w", filePath, err)
 	}
 
 	// Sort the observations: Primary by Currency, Secondary by Date (ascending)
-	sort.SliceStable(historicalRates.Root.Obs, func(i, j int) bool {
-		if historicalRates.Root.Obs[i].Ccy != historicalRates.Root.Obs[j].Ccy {
-			return historicalRates.Root.Obs[i].Ccy < historicalRates.Root.Obs[j].Ccy
+	sort.SliceStable(loaded.Root.Obs, func(i, j int) bool {
+		if loaded.Root.Obs[i].Ccy != loaded.Root.Obs[j].Ccy {
+			return loaded.Root.Obs[i].Ccy < loaded.Root.Obs[j].Ccy
 		}
 		// Assuming _TIME_PERIOD is "YYYY-MM-DD" format for correct string comparison
 		// For more robust date sorting, parse to time.Time, but string sort works for YYYY-MM-DD
-		return historicalRates.Root.Obs[i].TimePeriod < historicalRates.Root.Obs[j].TimePeriod
+		return loaded.Root.Obs[i].TimePeriod < loaded.Root.Obs[j].TimePeriod
 	})
 
+	ratesMu.Lock()
+	historicalRates = loaded
+	ratesFileModTime = info.ModTime()
 	ratesLoaded = true
-	logger.L.Info("Historical exchange rates loaded and sorted successfully.", "path", filePath, "observationCount", len(historicalRates.Root.Obs))
+	ratesMu.Unlock()
+
+	logger.L.Info("Historical exchange rates loaded and sorted successfully.", "path", filePath, "observationCount", len(loaded.Root.Obs))
 	return nil
 }
 
+// ReloadHistoricalRatesIfChanged reloads the rate file when its modification time differs
+// from the one seen at the last load. It reports whether a reload happened.
+func ReloadHistoricalRatesIfChanged(filePath string) (bool, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false, fmt.Errorf("error checking historical exchange rate file '%s': %w", filePath, err)
+	}
+	ratesMu.RLock()
+	unchanged := ratesLoaded && info.ModTime().Equal(ratesFileModTime)
+	ratesMu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	if err := LoadHistoricalRates(filePath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // GetExchangeRate retrieves the exchange rate for a given currency and date.
 // If an exact date match is not found, it uses the most recent rate on or before the requested date.
 func GetExchangeRate(currency string, date time.Time) (float64, error) {
+	ratesMu.RLock()
+	defer ratesMu.RUnlock()
+
 	if !ratesLoaded {
 		logger.L.Error("Attempted to GetExchangeRate before rates were loaded.")
 		return 0, fmt.Errorf("historical exchange rates not loaded")
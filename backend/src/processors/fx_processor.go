@@ -0,0 +1,167 @@
+package processors
+
+import (
+	"math"
+	"sort"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// fxLot is an acquired amount of foreign currency still held in a broker account.
+type fxLot struct {
+	date           string
+	remaining      float64
+	costPerUnitEUR float64
+}
+
+// fxProcessorImpl implements the FXProcessor interface.
+type fxProcessorImpl struct{}
+
+// NewFXProcessor creates a new instance of FXProcessor.
+func NewFXProcessor() FXProcessor {
+	return &fxProcessorImpl{}
+}
+
+// Process treats every non-EUR cash flow as an acquisition (inflow) or disposal (outflow) of that
+// currency, tracked FIFO per broker account and currency. Disposals are valued at the rate of the
+// disposal date and compared with the EUR value at acquisition to give the realised FX gain.
+// Withdrawals of foreign cash remove lots without realising a gain, since the currency is still held.
+// Only accounts whose currency conversions were imported (include_fx) are matched: without them most
+// of the foreign cash has no known acquisition, so those accounts are listed as untracked instead.
+func (p *fxProcessorImpl) Process(transactions []models.ProcessedTransaction) models.FXGainReport {
+	report := models.FXGainReport{
+		Details:           []models.FXGainDetail{},
+		Summary:           make(models.FXGainResult),
+		UntrackedAccounts: []string{},
+	}
+
+	flows := filterAndSortForeignCashFlows(transactions)
+	tracked := make(map[string]bool)
+	for _, tx := range flows {
+		if tx.TransactionType == "FX" {
+			tracked[fxAccountKey(tx)] = true
+		}
+	}
+	untracked := make(map[string]bool)
+	lotsByAccount := make(map[string][]*fxLot)
+
+	for _, tx := range flows {
+		accountKey := fxAccountKey(tx)
+		if !tracked[accountKey] {
+			if !untracked[accountKey] {
+				untracked[accountKey] = true
+				report.UntrackedAccounts = append(report.UntrackedAccounts, accountKey)
+			}
+			continue
+		}
+		amount := math.Abs(tx.Amount)
+		eurPerUnit := math.Abs(tx.AmountEUR) / amount
+
+		if tx.Amount > 0 {
+			lotsByAccount[accountKey] = append(lotsByAccount[accountKey], &fxLot{
+				date:           tx.Date,
+				remaining:      amount,
+				costPerUnitEUR: eurPerUnit,
+			})
+			continue
+		}
+
+		realises := !(tx.TransactionType == "CASH" && tx.TransactionSubType == "WITHDRAWAL")
+		year := utils.ParseDate(tx.Date).Format("2006")
+		lots := lotsByAccount[accountKey]
+		remaining := amount
+
+		for remaining > 0 && len(lots) > 0 {
+			lot := lots[0]
+			matched := math.Min(remaining, lot.remaining)
+
+			if realises {
+				costEUR := utils.RoundFloat(matched*lot.costPerUnitEUR, 2)
+				proceedsEUR := utils.RoundFloat(matched*eurPerUnit, 2)
+				report.Details = append(report.Details, models.FXGainDetail{
+					Source:          tx.Source,
+					Currency:        tx.Currency,
+					AcquisitionDate: lot.date,
+					DisposalDate:    tx.Date,
+					Amount:          utils.RoundFloat(matched, 2),
+					CostEUR:         costEUR,
+					ProceedsEUR:     proceedsEUR,
+					Delta:           utils.RoundFloat(proceedsEUR-costEUR, 2),
+					DisposalType:    tx.TransactionType,
+				})
+				addFXSummary(report.Summary, year, tx.Currency, proceedsEUR, costEUR, 0)
+			}
+
+			remaining -= matched
+			lot.remaining -= matched
+			if lot.remaining <= 1e-9 {
+				lots = lots[1:]
+			}
+		}
+		lotsByAccount[accountKey] = lots
+
+		// Outflows beyond the tracked lots come from history that was never uploaded.
+		// They are reported but not given a gain, since their acquisition cost is unknown.
+		if remaining > 1e-9 && realises {
+			addFXSummary(report.Summary, year, tx.Currency, 0, 0, remaining)
+		}
+	}
+
+	for year, currencies := range report.Summary {
+		for currency, summary := range currencies {
+			summary.ProceedsEUR = utils.RoundFloat(summary.ProceedsEUR, 2)
+			summary.CostEUR = utils.RoundFloat(summary.CostEUR, 2)
+			summary.GainEUR = utils.RoundFloat(summary.ProceedsEUR-summary.CostEUR, 2)
+			summary.UnmatchedAmount = utils.RoundFloat(summary.UnmatchedAmount, 2)
+			report.Summary[year][currency] = summary
+		}
+	}
+
+	sort.Strings(report.UntrackedAccounts)
+	return report
+}
+
+// fxAccountKey identifies the foreign cash balance a transaction moves: one per broker and currency.
+func fxAccountKey(tx models.ProcessedTransaction) string {
+	return tx.Source + "|" + tx.Currency
+}
+
+func addFXSummary(result models.FXGainResult, year, currency string, proceedsEUR, costEUR, unmatched float64) {
+	if _, ok := result[year]; !ok {
+		result[year] = make(map[string]models.FXCurrencySummary)
+	}
+	summary := result[year][currency]
+	summary.ProceedsEUR += proceedsEUR
+	summary.CostEUR += costEUR
+	summary.UnmatchedAmount += unmatched
+	result[year][currency] = summary
+}
+
+// filterAndSortForeignCashFlows keeps the transactions that move non-EUR cash and orders them by date,
+// with inflows before outflows on the same day so same-day conversions can fund same-day purchases.
+func filterAndSortForeignCashFlows(transactions []models.ProcessedTransaction) []models.ProcessedTransaction {
+	var flows []models.ProcessedTransaction
+	for _, tx := range transactions {
+		if tx.Currency == "" || tx.Currency == "EUR" || tx.Amount == 0 || tx.AmountEUR == 0 {
+			continue
+		}
+		// Position transfers carry a cost basis but no cash moves.
+		if tx.BuySell == "TRANSFER_IN" || tx.BuySell == "TRANSFER_OUT" {
+			continue
+		}
+		flows = append(flows, tx)
+	}
+	sort.SliceStable(flows, func(i, j int) bool {
+		dateI := utils.ParseDate(flows[i].Date)
+		dateJ := utils.ParseDate(flows[j].Date)
+		if dateI.Equal(dateJ) {
+			if (flows[i].Amount > 0) != (flows[j].Amount > 0) {
+				return flows[i].Amount > 0
+			}
+			return flows[i].ID < flows[j].ID
+		}
+		return dateI.Before(dateJ)
+	})
+	return flows
+}
@@ -0,0 +1,58 @@
+package processors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+func TestFXProcessorTracksOnlyConvertedAccounts(t *testing.T) {
+	conversion := models.ProcessedTransaction{ID: 1, Date: "01-02-2024", Source: "ibkr", TransactionType: "FX", Currency: "USD", Amount: 1000, AmountEUR: 900}
+	buy := models.ProcessedTransaction{ID: 2, Date: "01-03-2024", Source: "ibkr", TransactionType: "STOCK", BuySell: "BUY", Currency: "USD", Amount: -500, AmountEUR: -500}
+	dividend := models.ProcessedTransaction{ID: 3, Date: "01-03-2024", Source: "degiro", TransactionType: "DIVIDEND", Currency: "USD", Amount: 10, AmountEUR: 9}
+	degiroBuy := models.ProcessedTransaction{ID: 4, Date: "02-03-2024", Source: "degiro", TransactionType: "STOCK", BuySell: "BUY", Currency: "USD", Amount: -400, AmountEUR: -380}
+
+	tests := []struct {
+		name          string
+		transactions  []models.ProcessedTransaction
+		wantGain      float64
+		wantUnmatched float64
+		wantUntracked []string
+	}{
+		{
+			name:          "conversion funds the purchase",
+			transactions:  []models.ProcessedTransaction{buy, conversion},
+			wantGain:      50,
+			wantUntracked: []string{},
+		},
+		{
+			name:          "account without imported conversions is untracked",
+			transactions:  []models.ProcessedTransaction{dividend, degiroBuy},
+			wantUntracked: []string{"degiro|USD"},
+		},
+		{
+			name:          "outflow beyond the converted amount is unmatched",
+			transactions:  []models.ProcessedTransaction{conversion, buy, {ID: 5, Date: "01-04-2024", Source: "ibkr", TransactionType: "STOCK", BuySell: "BUY", Currency: "USD", Amount: -700, AmountEUR: -630}},
+			wantGain:      50,
+			wantUnmatched: 200,
+			wantUntracked: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewFXProcessor().Process(tt.transactions)
+			summary := report.Summary["2024"]["USD"]
+			if summary.GainEUR != tt.wantGain {
+				t.Errorf("GainEUR = %v, want %v", summary.GainEUR, tt.wantGain)
+			}
+			if summary.UnmatchedAmount != tt.wantUnmatched {
+				t.Errorf("UnmatchedAmount = %v, want %v", summary.UnmatchedAmount, tt.wantUnmatched)
+			}
+			if !reflect.DeepEqual(report.UntrackedAccounts, tt.wantUntracked) {
+				t.Errorf("UntrackedAccounts = %v, want %v", report.UntrackedAccounts, tt.wantUntracked)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// instrumentClassData is the reference file used to classify instruments: explicit ISINs first,
+// then product name patterns tried in file order.
+type instrumentClassData struct {
+	ISINs        map[string]string `json:"isins"`
+	NamePatterns []struct {
+		Class    string   `json:"class"`
+		Contains []string `json:"contains"`
+	} `json:"name_patterns"`
+}
+
+var instrumentClasses instrumentClassData
+
+// instrumentClassesMu guards instrumentClasses, mirroring the historical rates loader.
+var instrumentClassesMu sync.RWMutex
+
+// nameSeparators are replaced by spaces before name patterns are matched, so patterns can rely on word boundaries.
+var nameSeparators = strings.NewReplacer("(", " ", ")", " ", ",", " ", ".", " ", "/", " ", "_", " ")
+
+// LoadInstrumentClasses loads the ISIN and product name reference data from the specified file path.
+func LoadInstrumentClasses(filePath string) error {
+	logger.L.Info("Loading instrument class reference data", "path", filePath)
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		logger.L.Error("Error reading instrument class file", "path", filePath, "error", err)
+		return fmt.Errorf("error reading instrument class file '%s': %w", filePath, err)
+	}
+
+	var loaded instrumentClassData
+	if err := json.Unmarshal(file, &loaded); err != nil {
+		logger.L.Error("Error unmarshalling instrument classes", "path", filePath, "error", err)
+		return fmt.Errorf("error unmarshalling instrument classes from '%s': %w", filePath, err)
+	}
+	isins := make(map[string]string, len(loaded.ISINs))
+	for isin, class := range loaded.ISINs {
+		class = strings.ToUpper(class)
+		if !models.IsInstrumentClass(class) {
+			return fmt.Errorf("instrument class file '%s': unknown class '%s' for ISIN %s", filePath, class, isin)
+		}
+		isins[strings.ToUpper(isin)] = class
+	}
+	loaded.ISINs = isins
+	for i, pattern := range loaded.NamePatterns {
+		loaded.NamePatterns[i].Class = strings.ToUpper(pattern.Class)
+		if !models.IsInstrumentClass(loaded.NamePatterns[i].Class) {
+			return fmt.Errorf("instrument class file '%s': unknown class '%s' in name patterns", filePath, pattern.Class)
+		}
+	}
+
+	instrumentClassesMu.Lock()
+	instrumentClasses = loaded
+	instrumentClassesMu.Unlock()
+
+	logger.L.Info("Instrument class reference data loaded.", "path", filePath, "isinCount", len(isins), "patternCount", len(loaded.NamePatterns))
+	return nil
+}
+
+// ClassifyInstrument returns the instrument class of a transaction. Options and crypto are classed by their
+// transaction type; securities by the reference ISIN list, then the broker's hint, then product name patterns,
+// defaulting to SHARE. Cash movements, fees and FX rows have no instrument and return "".
+func ClassifyInstrument(txType, isin, productName, hint string) string {
+	switch txType {
+	case "OPTION":
+		return models.InstrumentClassOption
+	case "CRYPTO":
+		return models.InstrumentClassCrypto
+	case "STOCK", "DIVIDEND", "BOND":
+	default:
+		return ""
+	}
+
+	instrumentClassesMu.RLock()
+	defer instrumentClassesMu.RUnlock()
+
+	if class, ok := instrumentClasses.ISINs[strings.ToUpper(strings.TrimSpace(isin))]; ok {
+		return class
+	}
+	if hint = strings.ToUpper(hint); models.IsInstrumentClass(hint) {
+		return hint
+	}
+	if txType == "BOND" {
+		return models.InstrumentClassBond
+	}
+	name := " " + strings.Join(strings.Fields(nameSeparators.Replace(strings.ToUpper(productName))), " ") + " "
+	for _, pattern := range instrumentClasses.NamePatterns {
+		for _, fragment := range pattern.Contains {
+			if strings.Contains(name, strings.ToUpper(fragment)) {
+				return pattern.Class
+			}
+		}
+	}
+	return models.InstrumentClassShare
+}
+
+// SummarizeSalesByInstrumentClass totals stock sale details by sale year and instrument class. Details without
+// a class are counted as SHARE, the classifier's default.
+func SummarizeSalesByInstrumentClass(sales []models.SaleDetail) map[string]map[string]models.InstrumentClassSaleSummary {
+	summary := make(map[string]map[string]models.InstrumentClassSaleSummary)
+	for _, sale := range sales {
+		saleDate := utils.ParseDate(sale.SaleDate)
+		if saleDate.IsZero() {
+			continue
+		}
+		year := strconv.Itoa(saleDate.Year())
+		class := sale.InstrumentClass
+		if class == "" {
+			class = models.InstrumentClassShare
+		}
+		if summary[year] == nil {
+			summary[year] = make(map[string]models.InstrumentClassSaleSummary)
+		}
+		entry := summary[year][class]
+		entry.Sales++
+		entry.SaleAmountEUR = utils.RoundFloat(entry.SaleAmountEUR+sale.SaleAmountEUR, 2)
+		entry.CostEUR = utils.RoundFloat(entry.CostEUR-sale.BuyAmountEUR, 2) // BuyAmountEUR is negative
+		entry.GainEUR = utils.RoundFloat(entry.GainEUR+sale.Delta, 2)
+		summary[year][class] = entry
+	}
+	return summary
+}
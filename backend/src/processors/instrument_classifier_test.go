@@ -0,0 +1,33 @@
+package processors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+func TestSummarizeSalesByInstrumentClass(t *testing.T) {
+	sales := []models.SaleDetail{
+		{SaleDate: "10-02-2023", InstrumentClass: models.InstrumentClassETF, SaleAmountEUR: 1200, BuyAmountEUR: -1000, Delta: 200},
+		{SaleDate: "15-03-2024", InstrumentClass: models.InstrumentClassETF, SaleAmountEUR: 500, BuyAmountEUR: -600, Delta: -100},
+		{SaleDate: "20-05-2024", InstrumentClass: models.InstrumentClassETF, SaleAmountEUR: 300, BuyAmountEUR: -250, Delta: 50},
+		{SaleDate: "01-06-2024", SaleAmountEUR: 100, BuyAmountEUR: -80, Delta: 20},
+		{SaleDate: "01-07-2024", InstrumentClass: models.InstrumentClassBond, SaleAmountEUR: 990, BuyAmountEUR: -1000, Delta: -10},
+		{SaleDate: "not a date", InstrumentClass: models.InstrumentClassFund, SaleAmountEUR: 1, Delta: 1},
+	}
+
+	want := map[string]map[string]models.InstrumentClassSaleSummary{
+		"2023": {
+			models.InstrumentClassETF: {Sales: 1, SaleAmountEUR: 1200, CostEUR: 1000, GainEUR: 200},
+		},
+		"2024": {
+			models.InstrumentClassETF:   {Sales: 2, SaleAmountEUR: 800, CostEUR: 850, GainEUR: -50},
+			models.InstrumentClassShare: {Sales: 1, SaleAmountEUR: 100, CostEUR: 80, GainEUR: 20},
+			models.InstrumentClassBond:  {Sales: 1, SaleAmountEUR: 990, CostEUR: 1000, GainEUR: -10},
+		},
+	}
+	if got := SummarizeSalesByInstrumentClass(sales); !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeSalesByInstrumentClass() = %+v, want %+v", got, want)
+	}
+}
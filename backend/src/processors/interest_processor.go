@@ -0,0 +1,73 @@
+package processors
+
+import (
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// Sub types of "INTEREST" transactions. Plain interest on cash has no sub type.
+const (
+	InterestSubTypeTax     = "TAX"     // Tax withheld on interest
+	InterestSubTypeLending = "LENDING" // Securities lending income
+)
+
+// interestProcessorImpl implements the InterestProcessor interface.
+type interestProcessorImpl struct{}
+
+// NewInterestProcessor creates a new instance of InterestProcessor.
+func NewInterestProcessor() InterestProcessor {
+	return &interestProcessorImpl{}
+}
+
+// CalculateTaxSummary aggregates INTEREST transactions per year, country and currency, like the dividend summary.
+// Negative interest that is not a withheld tax is debit interest charged by the broker and kept apart from income.
+func (p *interestProcessorImpl) CalculateTaxSummary(transactions []models.ProcessedTransaction) models.InterestTaxResult {
+	result := make(models.InterestTaxResult)
+
+	for _, t := range transactions {
+		if t.TransactionType != "INTEREST" {
+			continue
+		}
+		year := utils.ParseDate(t.Date).Format("2006")
+		country := transactionCountry(t)
+		if country == "" {
+			// Brokers that do not say which entity paid the interest leave the country to the user.
+			country = utils.UnknownCountry
+		}
+		if _, ok := result[year]; !ok {
+			result[year] = make(map[string]map[string]models.InterestSummary)
+		}
+		if _, ok := result[year][country]; !ok {
+			result[year][country] = make(map[string]models.InterestSummary)
+		}
+
+		summary := result[year][country][t.Currency]
+		switch {
+		case t.TransactionSubType == InterestSubTypeTax:
+			summary.TaxedAmt += t.AmountEUR
+		case t.AmountEUR < 0:
+			summary.PaidAmt += t.AmountEUR
+		default:
+			summary.GrossAmt += t.AmountEUR
+			summary.GrossAmtOriginal += t.Amount
+			if t.TransactionSubType == InterestSubTypeLending {
+				summary.LendingAmt += t.AmountEUR
+			}
+		}
+		result[year][country][t.Currency] = summary
+	}
+
+	for _, countries := range result {
+		for _, currencies := range countries {
+			for currency, summary := range currencies {
+				summary.GrossAmt = roundToTwoDecimalPlaces(summary.GrossAmt)
+				summary.TaxedAmt = roundToTwoDecimalPlaces(summary.TaxedAmt)
+				summary.LendingAmt = roundToTwoDecimalPlaces(summary.LendingAmt)
+				summary.PaidAmt = roundToTwoDecimalPlaces(summary.PaidAmt)
+				summary.GrossAmtOriginal = roundToTwoDecimalPlaces(summary.GrossAmtOriginal)
+				currencies[currency] = summary
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+func TestInterestCalculateTaxSummary(t *testing.T) {
+	interest := func(source, subType, country string, amountEUR float64) models.ProcessedTransaction {
+		return models.ProcessedTransaction{Date: "01-04-2024", Source: source, TransactionType: "INTEREST", TransactionSubType: subType,
+			Currency: "EUR", Amount: amountEUR, AmountEUR: amountEUR, CountryCode: country}
+	}
+	germany := utils.GetCountryCodeString("DE")
+
+	result := NewInterestProcessor().CalculateTaxSummary([]models.ProcessedTransaction{
+		interest("degiro", "", germany, 3),
+		interest("ibkr", "", "", 10),
+		interest("ibkr", InterestSubTypeLending, "", 2),
+		interest("ibkr", InterestSubTypeTax, "", -2.4),
+		interest("ibkr", "", "", -1.5),
+		interest("xtb", "", utils.GetCountryCodeString(""), 4),
+	})
+
+	tests := []struct {
+		country string
+		want    models.InterestSummary
+	}{
+		{country: germany, want: models.InterestSummary{GrossAmt: 3, GrossAmtOriginal: 3}},
+		{country: utils.UnknownCountry, want: models.InterestSummary{GrossAmt: 16, GrossAmtOriginal: 16, LendingAmt: 2, TaxedAmt: -2.4, PaidAmt: -1.5}},
+	}
+	if len(result["2024"]) != len(tests) {
+		t.Fatalf("got countries %v, want %d", result["2024"], len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.country, func(t *testing.T) {
+			if got := result["2024"][tt.country]["EUR"]; got != tt.want {
+				t.Errorf("summary = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package processors
 
 import (
+	"time"
+
 	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/prices"
 )
 
 // DividendResult represents the grouped dividend amounts by year, country, and type.
@@ -12,19 +15,71 @@ type DividendResult map[string]map[string]map[string]float64
 type DividendProcessor interface {
 	Calculate(transactions []models.ProcessedTransaction) DividendResult // Deprecated: Use CalculateTaxSummary for tax-specific format
 	CalculateTaxSummary(transactions []models.ProcessedTransaction) models.DividendTaxResult
+	// Analyze returns per-ISIN dividend analytics and a projected income calendar as of asOf.
+	Analyze(transactions []models.ProcessedTransaction, holdings []models.PurchaseLot, asOf time.Time) models.DividendAnalytics
 }
 
 // StockProcessor defines the interface for processing stock transactions.
+// Sales and transfers that cannot be matched against open lots are returned as LotMatchingErrors.
 type StockProcessor interface {
-	Process(transactions []models.ProcessedTransaction) ([]models.SaleDetail, map[string][]models.PurchaseLot)
+	Process(transactions []models.ProcessedTransaction) ([]models.SaleDetail, map[string][]models.PurchaseLot, []models.LotMatchingError)
+	// HoldingsAt returns the lots open at the end of asOf.
+	HoldingsAt(transactions []models.ProcessedTransaction, asOf time.Time) []models.PurchaseLot
+	// SimulateSale matches a hypothetical sale against the open lots without storing it.
+	SimulateSale(transactions []models.ProcessedTransaction, sale models.ProcessedTransaction) models.SaleSimulation
 }
 
 // OptionProcessor defines the interface for processing option transactions.
 type OptionProcessor interface {
 	Process(transactions []models.ProcessedTransaction) ([]models.OptionSaleDetail, []models.OptionHolding)
+	// HoldingsAt returns the positions open at the end of asOf; contracts expired by then are closed.
+	HoldingsAt(transactions []models.ProcessedTransaction, asOf time.Time) []models.OptionHolding
+}
+
+// CostProcessor totals fees and commissions per year, broker and currency.
+type CostProcessor interface {
+	Process(transactions []models.ProcessedTransaction, dailyValues []models.PerformancePoint) models.CostReport
+}
+
+// TaxLossProcessor finds open stock lots whose loss could be realised to offset the year's gains.
+type TaxLossProcessor interface {
+	Process(transactions []models.ProcessedTransaction, holdings []models.PurchaseLot, valuation *models.HoldingsValuation, asOf time.Time) models.HarvestReport
 }
 
 // CashMovementProcessor defines the interface for processing cash deposits and withdrawals.
 type CashMovementProcessor interface {
 	Process(transactions []models.ProcessedTransaction) []models.CashMovement
 }
+
+// CashLedgerProcessor rebuilds the cash balance of each broker account per currency.
+type CashLedgerProcessor interface {
+	Process(transactions []models.ProcessedTransaction) models.CashLedgerReport
+}
+
+// FXProcessor defines the interface for tracking foreign-currency cash lots and realised FX gains.
+type FXProcessor interface {
+	Process(transactions []models.ProcessedTransaction) models.FXGainReport
+}
+
+// CryptoProcessor defines the interface for matching crypto disposals FIFO and splitting exempt and taxable gains.
+type CryptoProcessor interface {
+	Process(transactions []models.ProcessedTransaction) models.CryptoGainReport
+}
+
+// BondProcessor defines the interface for matching bond sales and redemptions and collecting bond interest.
+type BondProcessor interface {
+	Process(transactions []models.ProcessedTransaction) models.BondReport
+}
+
+// InterestProcessor defines the interface for aggregating interest and securities lending income.
+type InterestProcessor interface {
+	CalculateTaxSummary(transactions []models.ProcessedTransaction) models.InterestTaxResult
+}
+
+// PerformanceProcessor defines the interface for computing portfolio returns from daily valuations.
+// priceHistory holds the stored closing prices by ISIN, oldest first.
+type PerformanceProcessor interface {
+	Process(transactions []models.ProcessedTransaction, priceHistory map[string][]prices.Quote, from, to time.Time) models.PerformanceReport
+	// CompareBenchmark replays the portfolio's external flows into a benchmark priced by benchmarkQuotes.
+	CompareBenchmark(transactions []models.ProcessedTransaction, priceHistory map[string][]prices.Quote, benchmarkISIN string, benchmarkQuotes []prices.Quote, from, to time.Time) models.BenchmarkComparison
+}
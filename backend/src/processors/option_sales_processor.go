@@ -2,20 +2,24 @@ package processors
 
 import (
 	"log"
+	"math"
 	"sort"
+	"strconv"
 	"strings" // Ensure strings package is imported
+	"time"
 
 	"github.com/username/taxfolio/backend/src/models"
 	"github.com/username/taxfolio/backend/src/utils" // Import the new utils package
-	// "time" // No longer needed directly if using utils.ParseDate
 )
 
 // optionProcessorImpl implements the OptionProcessor interface.
-type optionProcessorImpl struct{}
+type optionProcessorImpl struct {
+	now func() time.Time // Clock used to decide which open positions have expired
+}
 
 // NewOptionProcessor creates a new instance of OptionProcessor.
 func NewOptionProcessor() OptionProcessor { // Return the interface type
-	return &optionProcessorImpl{} // Return the implementation struct
+	return &optionProcessorImpl{now: time.Now} // Return the implementation struct
 }
 
 // Process implements the OptionProcessor interface.
@@ -23,16 +27,16 @@ func NewOptionProcessor() OptionProcessor { // Return the interface type
 // returning details of closed option trades and currently open option holdings.
 func (p *optionProcessorImpl) Process(transactions []models.ProcessedTransaction) ([]models.OptionSaleDetail, []models.OptionHolding) {
 	optionTransactions := filterOptionTransactions(transactions)
-	transactionsByProduct := groupTransactionsByProduct(optionTransactions)
+	transactionsByContract := groupTransactionsByContract(optionTransactions)
 
 	var allOptionSaleDetails []models.OptionSaleDetail
 	var allOptionHoldings []models.OptionHolding
 
-	// Iterate over the grouped transactions; productName key is not needed in the loop body
-	for _, txs := range transactionsByProduct {
+	// Iterate over the grouped transactions; the contract key is not needed in the loop body
+	for _, txs := range transactionsByContract {
 		sortTransactionsByDate(txs)
 
-		// Process trades for this specific option product
+		// Process trades for this specific option contract
 		// We need to track both long (bought) and short (sold) open positions separately
 		var openLongPositions []*models.ProcessedTransaction
 		var openShortPositions []*models.ProcessedTransaction
@@ -55,9 +59,9 @@ func (p *optionProcessorImpl) Process(transactions []models.ProcessedTransaction
 			if isBuy { // Buy transaction (determined by Description)
 				// Try to close open short positions first (FIFO)
 				remainingBuyQty := qty
-				for remainingBuyQty > 0 && len(openShortPositions) > 0 {
+				for remainingBuyQty > quantityEpsilon && len(openShortPositions) > 0 {
 					shortPos := openShortPositions[0]
-					matchQty := utils.MinInt(remainingBuyQty, shortPos.Quantity) // Use utils.MinInt
+					matchQty := math.Min(remainingBuyQty, shortPos.Quantity)
 
 					// Create Sale Detail (Closing a short position - Buy closes Short)
 					saleDetail := createOptionSaleDetail(shortPos, currentTx, matchQty, false) // isLongPosition = false
@@ -68,12 +72,12 @@ func (p *optionProcessorImpl) Process(transactions []models.ProcessedTransaction
 					shortPos.Quantity -= matchQty
 
 					// Remove exhausted short position
-					if shortPos.Quantity == 0 {
+					if shortPos.Quantity <= quantityEpsilon {
 						openShortPositions = openShortPositions[1:]
 					}
 				}
 				// If buy quantity remains, open a new long position
-				if remainingBuyQty > 0 {
+				if remainingBuyQty > quantityEpsilon {
 					// Create a copy for the holding to avoid modifying original slice data side effects
 					holdingCopy := *currentTx
 					holdingCopy.Quantity = remainingBuyQty
@@ -82,9 +86,9 @@ func (p *optionProcessorImpl) Process(transactions []models.ProcessedTransaction
 			} else { // Sell transaction (could be opening a short or closing a long)
 				// Try to close open long positions first (FIFO)
 				remainingSellQty := qty
-				for remainingSellQty > 0 && len(openLongPositions) > 0 {
+				for remainingSellQty > quantityEpsilon && len(openLongPositions) > 0 {
 					longPos := openLongPositions[0]
-					matchQty := utils.MinInt(remainingSellQty, longPos.Quantity) // Use utils.MinInt
+					matchQty := math.Min(remainingSellQty, longPos.Quantity)
 
 					// Create Sale Detail (Closing a long position - Sell closes Long)
 					saleDetail := createOptionSaleDetail(longPos, currentTx, matchQty, true) // isLongPosition = true
@@ -95,12 +99,12 @@ func (p *optionProcessorImpl) Process(transactions []models.ProcessedTransaction
 					longPos.Quantity -= matchQty
 
 					// Remove exhausted long position
-					if longPos.Quantity == 0 {
+					if longPos.Quantity <= quantityEpsilon {
 						openLongPositions = openLongPositions[1:]
 					}
 				}
 				// If sell quantity remains, open a new short position
-				if remainingSellQty > 0 {
+				if remainingSellQty > quantityEpsilon {
 					// Create a copy for the holding
 					holdingCopy := *currentTx
 					holdingCopy.Quantity = remainingSellQty // Keep quantity positive for matching logic, sign indicates type
@@ -109,6 +113,17 @@ func (p *optionProcessorImpl) Process(transactions []models.ProcessedTransaction
 			}
 		}
 
+		// Positions still open after their expiry date expired worthless; brokers often emit no closing row for them
+		if expiry, ok := optionExpiry(txs[0]); ok && expiry.Before(p.today()) {
+			for _, pos := range openLongPositions {
+				closedDetails = append(closedDetails, createOptionExpiryDetail(pos, expiry, true))
+			}
+			for _, pos := range openShortPositions {
+				closedDetails = append(closedDetails, createOptionExpiryDetail(pos, expiry, false))
+			}
+			openLongPositions, openShortPositions = nil, nil
+		}
+
 		// Add closed details for this product to the overall list
 		allOptionSaleDetails = append(allOptionSaleDetails, closedDetails...)
 
@@ -124,6 +139,22 @@ func (p *optionProcessorImpl) Process(transactions []models.ProcessedTransaction
 	return allOptionSaleDetails, allOptionHoldings
 }
 
+// HoldingsAt replays the transactions dated up to asOf, expiring the contracts whose expiry date is before it.
+func (p *optionProcessorImpl) HoldingsAt(transactions []models.ProcessedTransaction, asOf time.Time) []models.OptionHolding {
+	atDate := &optionProcessorImpl{now: func() time.Time { return asOf }}
+	_, holdings := atDate.Process(transactionsUpTo(transactions, asOf))
+	if holdings == nil {
+		holdings = []models.OptionHolding{}
+	}
+	return holdings
+}
+
+// today returns the start of the current day, so an option expiring today is still open.
+func (p *optionProcessorImpl) today() time.Time {
+	now := p.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
 // --- Helper Functions ---
 
 func filterOptionTransactions(transactions []models.ProcessedTransaction) []models.ProcessedTransaction {
@@ -133,7 +164,7 @@ func filterOptionTransactions(transactions []models.ProcessedTransaction) []mode
 			// Ensure quantity is positive for easier matching logic later
 			// The sign of the amount will determine buy/sell direction
 			if tx.Quantity < 0 {
-				log.Printf("Warning: Option transaction %s has negative quantity %d. Taking absolute value.", tx.OrderID, tx.Quantity)
+				log.Printf("Warning: Option transaction %s has negative quantity %g. Taking absolute value.", tx.OrderID, tx.Quantity)
 				tx.Quantity = -tx.Quantity
 			}
 			if tx.Quantity == 0 {
@@ -146,19 +177,59 @@ func filterOptionTransactions(transactions []models.ProcessedTransaction) []mode
 	return options
 }
 
-func groupTransactionsByProduct(transactions []models.ProcessedTransaction) map[string][]models.ProcessedTransaction {
+func groupTransactionsByContract(transactions []models.ProcessedTransaction) map[string][]models.ProcessedTransaction {
 	grouped := make(map[string][]models.ProcessedTransaction)
 	for _, tx := range transactions {
-		// Group by ProductName for options, as ISIN might not be reliable/present
-		if tx.ProductName == "" {
-			log.Printf("Warning: Skipping option transaction with empty ProductName (OrderID: %s)", tx.OrderID)
+		key := OptionContractKey(tx)
+		if key == "" {
+			log.Printf("Warning: Skipping option transaction with no contract identity (OrderID: %s)", tx.OrderID)
 			continue
 		}
-		grouped[tx.ProductName] = append(grouped[tx.ProductName], tx)
+		grouped[key] = append(grouped[key], tx)
 	}
 	return grouped
 }
 
+// OptionContractKey identifies an option contract by underlying, right, strike, expiry and multiplier, so that
+// brokers naming the same contract differently still match. Rows stored before these fields existed fall back to
+// parsing the product name, and to the raw product name when it has no recognisable format.
+func OptionContractKey(tx models.ProcessedTransaction) string {
+	underlying, right, strike, expiry := tx.Underlying, tx.OptionRight, tx.Strike, tx.Expiry
+	if underlying == "" || expiry == "" {
+		contract, ok := utils.ParseOptionProductName(tx.ProductName)
+		if !ok {
+			return tx.ProductName
+		}
+		underlying, right, strike = contract.Underlying, contract.Right, contract.Strike
+		expiry = contract.Expiry.Format("02-01-2006")
+	}
+	return strings.Join([]string{
+		strings.ToUpper(underlying),
+		strings.ToUpper(right),
+		strconv.FormatFloat(strike, 'f', -1, 64),
+		expiry,
+		strconv.FormatFloat(optionMultiplier(&tx), 'f', -1, 64),
+	}, "|")
+}
+
+// priceBasedContractValue values one contract from its price when a row carries no amount, signed like an amount:
+// negative when buying, positive when selling.
+func priceBasedContractValue(tx *models.ProcessedTransaction) float64 {
+	value := math.Abs(tx.Price) * optionMultiplier(tx)
+	if strings.ToUpper(tx.BuySell) == "BUY" {
+		return -value
+	}
+	return value
+}
+
+// optionMultiplier returns the number of underlying units per contract, defaulting to the standard 100.
+func optionMultiplier(tx *models.ProcessedTransaction) float64 {
+	if tx.Multiplier > 0 {
+		return tx.Multiplier
+	}
+	return utils.DefaultOptionMultiplier
+}
+
 func sortTransactionsByDate(transactions []models.ProcessedTransaction) {
 	sort.Slice(transactions, func(i, j int) bool {
 		// Add secondary sort by OrderID if dates are the same, for deterministic behavior
@@ -173,7 +244,7 @@ func sortTransactionsByDate(transactions []models.ProcessedTransaction) {
 
 // Creates an OptionSaleDetail from opening and closing transactions.
 // isLongPosition indicates if the openTx represented buying to open (long).
-func createOptionSaleDetail(openTx, closeTx *models.ProcessedTransaction, quantity int, isLongPosition bool) models.OptionSaleDetail {
+func createOptionSaleDetail(openTx, closeTx *models.ProcessedTransaction, quantity float64, isLongPosition bool) models.OptionSaleDetail {
 	var delta float64
 	// Ensure quantities are not zero before division
 	// Use OriginalQuantity for per-unit calculations of the opening leg
@@ -198,14 +269,14 @@ func createOptionSaleDetail(openTx, closeTx *models.ProcessedTransaction, quanti
 	// Calculate amounts per unit for the matched quantity
 	openAmountPerUnit := 0.0
 	if openOriginalQty != 0 {
-		openAmountPerUnit = openTx.Amount / float64(openOriginalQty) // Use Original Qty
+		openAmountPerUnit = openTx.Amount / openOriginalQty // Use Original Qty
 	}
 	closeAmountPerUnit := 0.0
 	// Handle cases like exercise/assignment where Amount might be 0 but Price isn't necessarily
 	if closeTx.Amount != 0 && closeQty != 0 {
-		closeAmountPerUnit = closeTx.Amount / float64(closeQty)
-	} else if closeTx.Price != 0 { // If amount is 0, use price as per-unit value
-		closeAmountPerUnit = closeTx.Price
+		closeAmountPerUnit = closeTx.Amount / closeQty
+	} else if closeTx.Price != 0 { // If amount is 0, use the per-contract value: price times multiplier
+		closeAmountPerUnit = priceBasedContractValue(closeTx)
 	}
 	// If both Amount and Price are 0 for closeTx, closeAmountPerUnit remains 0
 
@@ -213,7 +284,7 @@ func createOptionSaleDetail(openTx, closeTx *models.ProcessedTransaction, quanti
 	openAmountEURPerUnit := 0.0
 	if openOriginalQty != 0 { // Use Original Qty
 		if openTx.ExchangeRate != 0 {
-			openAmountEURPerUnit = (openTx.Amount / float64(openOriginalQty)) / openTx.ExchangeRate
+			openAmountEURPerUnit = (openTx.Amount / openOriginalQty) / openTx.ExchangeRate
 		} else {
 			openAmountEURPerUnit = openAmountPerUnit // Assume 1:1 if rate is missing/zero
 		}
@@ -224,10 +295,10 @@ func createOptionSaleDetail(openTx, closeTx *models.ProcessedTransaction, quanti
 		if closeTx.ExchangeRate != 0 {
 			// Base EUR calculation on Amount if available, otherwise Price
 			if closeTx.Amount != 0 {
-				closeAmountEURPerUnit = (closeTx.Amount / float64(closeQty)) / closeTx.ExchangeRate
+				closeAmountEURPerUnit = (closeTx.Amount / closeQty) / closeTx.ExchangeRate
 			} else if closeTx.Price != 0 {
 				// Assume Price is in the original currency if Amount is 0
-				closeAmountEURPerUnit = closeTx.Price / closeTx.ExchangeRate
+				closeAmountEURPerUnit = priceBasedContractValue(closeTx) / closeTx.ExchangeRate
 			}
 		} else {
 			closeAmountEURPerUnit = closeAmountPerUnit // Assume 1:1 if rate is missing/zero
@@ -235,21 +306,21 @@ func createOptionSaleDetail(openTx, closeTx *models.ProcessedTransaction, quanti
 	}
 
 	// Calculate total amounts for the matched quantity
-	openAmountMatched := openAmountPerUnit * float64(quantity)
-	closeAmountMatched := closeAmountPerUnit * float64(quantity)
-	openAmountEURMatched := openAmountEURPerUnit * float64(quantity)
-	closeAmountEURMatched := closeAmountEURPerUnit * float64(quantity)
+	openAmountMatched := openAmountPerUnit * quantity
+	closeAmountMatched := closeAmountPerUnit * quantity
+	openAmountEURMatched := openAmountEURPerUnit * quantity
+	closeAmountEURMatched := closeAmountEURPerUnit * quantity
 
 	// Commission allocation (simple prorata based on quantity matched)
 	openCommissionPerUnit := 0.0
 	if openOriginalQty != 0 { // Use Original Qty
-		openCommissionPerUnit = openTx.Commission / float64(openOriginalQty)
+		openCommissionPerUnit = openTx.Commission / openOriginalQty
 	}
 	closeCommissionPerUnit := 0.0
 	if closeQty != 0 { // Use closeQty for closing leg
-		closeCommissionPerUnit = closeTx.Commission / float64(closeQty)
+		closeCommissionPerUnit = closeTx.Commission / closeQty
 	}
-	totalCommissionMatched := (openCommissionPerUnit + closeCommissionPerUnit) * float64(quantity)
+	totalCommissionMatched := (openCommissionPerUnit + closeCommissionPerUnit) * quantity
 
 	delta = openAmountEURMatched + closeAmountEURMatched
 
@@ -271,13 +342,55 @@ func createOptionSaleDetail(openTx, closeTx *models.ProcessedTransaction, quanti
 		OpenOrderID:    openTx.OrderID,
 		CloseOrderID:   closeTx.OrderID,
 		CountryCode:    utils.GetCountryCodeString(openTx.ISIN), // Add country code using the utility function
+		Underlying:     openTx.Underlying,
+		Strike:         openTx.Strike,
+		Expiry:         openTx.Expiry,
+		OptionRight:    openTx.OptionRight,
+		Multiplier:     optionMultiplier(openTx),
 	}
 }
 
+// optionExpiry returns the expiry date of a transaction's contract, from its stored field or else its product name.
+func optionExpiry(tx models.ProcessedTransaction) (time.Time, bool) {
+	if tx.Expiry != "" {
+		expiry := utils.ParseDate(tx.Expiry)
+		return expiry, !expiry.IsZero()
+	}
+	contract, ok := utils.ParseOptionProductName(tx.ProductName)
+	if !ok {
+		return time.Time{}, false
+	}
+	return contract.Expiry, true
+}
+
+// createOptionExpiryDetail closes an open position at zero on its expiry date, realising the whole premium:
+// a gain for a short position, a loss for a long one.
+func createOptionExpiryDetail(openTx *models.ProcessedTransaction, expiry time.Time, isLongPosition bool) models.OptionSaleDetail {
+	closeSide := "BUY"
+	if isLongPosition {
+		closeSide = "SELL"
+	}
+	expiryClose := models.ProcessedTransaction{
+		Date:         expiry.Format("02-01-2006"),
+		ProductName:  openTx.ProductName,
+		BuySell:      closeSide,
+		Quantity:     openTx.Quantity,
+		Currency:     openTx.Currency,
+		ExchangeRate: openTx.ExchangeRate,
+		Multiplier:   openTx.Multiplier,
+	}
+	detail := createOptionSaleDetail(openTx, &expiryClose, openTx.Quantity, isLongPosition)
+	detail.Expired = true
+	return detail
+}
+
 // Creates an OptionHolding from an open transaction.
-func createOptionHolding(tx *models.ProcessedTransaction, quantity int) models.OptionHolding {
-	// Ensure the holding reflects the remaining quantity if partially closed
-	originalQty := tx.Quantity
+func createOptionHolding(tx *models.ProcessedTransaction, quantity float64) models.OptionHolding {
+	// The amounts are for the whole opening trade, so scale them from its original quantity to the remaining one
+	originalQty := math.Abs(tx.OriginalQuantity)
+	if originalQty == 0 {
+		originalQty = tx.Quantity
+	}
 	if originalQty == 0 {
 		originalQty = 1
 	} // Avoid division by zero if something went wrong
@@ -287,10 +400,15 @@ func createOptionHolding(tx *models.ProcessedTransaction, quantity int) models.O
 		ProductName:   tx.ProductName,
 		Quantity:      quantity, // Signed quantity (+long, -short)
 		OpenPrice:     tx.Price,
-		OpenAmount:    (tx.Amount / float64(originalQty)) * float64(utils.AbsInt(quantity)), // Use utils.AbsInt
+		OpenAmount:    (tx.Amount / originalQty) * math.Abs(quantity),
 		OpenCurrency:  tx.Currency,
-		OpenAmountEUR: (tx.AmountEUR / float64(originalQty)) * float64(utils.AbsInt(quantity)), // Use utils.AbsInt
+		OpenAmountEUR: (tx.AmountEUR / originalQty) * math.Abs(quantity),
 		OpenOrderID:   tx.OrderID,
+		Underlying:    tx.Underlying,
+		Strike:        tx.Strike,
+		Expiry:        tx.Expiry,
+		OptionRight:   tx.OptionRight,
+		Multiplier:    optionMultiplier(tx),
 	}
 }
 
@@ -0,0 +1,120 @@
+package processors
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+// optionTrade builds an EUR option transaction; amount follows the parsers' sign convention.
+func optionTrade(orderID, source, date, productName, buySell string, quantity, price, amount float64) models.ProcessedTransaction {
+	return models.ProcessedTransaction{
+		OrderID:          orderID,
+		Date:             date,
+		Source:           source,
+		ProductName:      productName,
+		TransactionType:  "OPTION",
+		BuySell:          buySell,
+		Quantity:         quantity,
+		OriginalQuantity: quantity,
+		Price:            price,
+		Amount:           amount,
+		AmountEUR:        amount,
+		Currency:         "EUR",
+		ExchangeRate:     1,
+	}
+}
+
+// withContract sets the structured contract fields, as the IBKR parser does.
+func withContract(tx models.ProcessedTransaction, underlying, right string, strike float64, expiry string, multiplier float64) models.ProcessedTransaction {
+	tx.Underlying, tx.OptionRight, tx.Strike, tx.Expiry, tx.Multiplier = underlying, right, strike, expiry, multiplier
+	return tx
+}
+
+func TestOptionContractKey(t *testing.T) {
+	degiro := optionTrade("1", "degiro", "01-03-2022", "FLW P31.00 18MAR22", "SELL", 1, 1, 100)
+	ibkr := withContract(optionTrade("2", "ibkr", "02-03-2022", "FLW 18MAR22 31 P", "BUY", 1, 1, -100), "FLW", "PUT", 31, "18-03-2022", 100)
+	mini := withContract(ibkr, "FLW", "PUT", 31, "18-03-2022", 10)
+	otherStrike := withContract(ibkr, "FLW", "PUT", 32, "18-03-2022", 100)
+
+	tests := []struct {
+		name      string
+		a, b      models.ProcessedTransaction
+		wantMatch bool
+	}{
+		{name: "product name and structured fields of the same contract", a: degiro, b: ibkr, wantMatch: true},
+		{name: "different multiplier", a: ibkr, b: mini},
+		{name: "different strike", a: ibkr, b: otherStrike},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OptionContractKey(tt.a) == OptionContractKey(tt.b); got != tt.wantMatch {
+				t.Errorf("keys %q and %q match = %v, want %v", OptionContractKey(tt.a), OptionContractKey(tt.b), got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestOptionProcessorMultiplier(t *testing.T) {
+	tests := []struct {
+		name       string
+		multiplier float64
+		wantDelta  float64
+	}{
+		{name: "standard contract", multiplier: 0, wantDelta: 50},
+		{name: "mini contract", multiplier: 10, wantDelta: -85},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open := withContract(optionTrade("1", "ibkr", "01-03-2022", "ACME", "BUY", 2, 0.75, -100), "ACME", "CALL", 50, "17-06-2022", tt.multiplier)
+			// An exercise or assignment row carries a price but no amount, so the close is valued per contract.
+			closeTx := withContract(optionTrade("2", "ibkr", "01-04-2022", "ACME", "SELL", 2, 0.75, 0), "ACME", "CALL", 50, "17-06-2022", tt.multiplier)
+
+			processor := &optionProcessorImpl{now: func() time.Time { return time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC) }}
+			sales, holdings := processor.Process([]models.ProcessedTransaction{open, closeTx})
+			if len(sales) != 1 || len(holdings) != 0 {
+				t.Fatalf("got %d sales and %d holdings, want 1 and 0", len(sales), len(holdings))
+			}
+			if math.Abs(sales[0].Delta-tt.wantDelta) > 1e-9 {
+				t.Errorf("Delta = %v, want %v", sales[0].Delta, tt.wantDelta)
+			}
+		})
+	}
+}
+
+func TestOptionProcessorExpiry(t *testing.T) {
+	shortPut := optionTrade("1", "degiro", "01-03-2022", "FLW P31.00 18MAR22", "SELL", 2, 0.5, 100)
+	longCall := optionTrade("2", "degiro", "01-03-2022", "FLW C40.00 18MAR22", "BUY", 1, 0.3, -30)
+
+	tests := []struct {
+		name         string
+		today        time.Time
+		wantSales    int
+		wantHoldings int
+		wantDelta    float64
+	}{
+		{name: "still open on the expiry day", today: time.Date(2022, 3, 18, 15, 0, 0, 0, time.UTC), wantHoldings: 2},
+		{name: "expired worthless after the expiry day", today: time.Date(2022, 3, 19, 0, 0, 0, 0, time.UTC), wantSales: 2, wantDelta: 70},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &optionProcessorImpl{now: func() time.Time { return tt.today }}
+			sales, holdings := processor.Process([]models.ProcessedTransaction{shortPut, longCall})
+			if len(sales) != tt.wantSales || len(holdings) != tt.wantHoldings {
+				t.Fatalf("got %d sales and %d holdings, want %d and %d", len(sales), len(holdings), tt.wantSales, tt.wantHoldings)
+			}
+			delta := 0.0
+			for _, sale := range sales {
+				if !sale.Expired || sale.CloseDate != "18-03-2022" || sale.CloseAmountEUR != 0 {
+					t.Errorf("sale %+v is not an expiry close at zero on 18-03-2022", sale)
+				}
+				delta += sale.Delta
+			}
+			if delta != tt.wantDelta {
+				t.Errorf("total Delta = %v, want %v", delta, tt.wantDelta)
+			}
+		})
+	}
+}
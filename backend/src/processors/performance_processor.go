@@ -0,0 +1,399 @@
+package processors
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/prices"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// minReturnBaseEUR is the smallest value a daily return is computed on; below it the day is treated as flat.
+const minReturnBaseEUR = 1.0
+
+// performanceProcessorImpl implements the PerformanceProcessor interface.
+type performanceProcessorImpl struct{}
+
+// NewPerformanceProcessor creates a new instance of PerformanceProcessor.
+func NewPerformanceProcessor() PerformanceProcessor {
+	return &performanceProcessorImpl{}
+}
+
+// heldPosition is an open position tracked while replaying the transactions day by day.
+type heldPosition struct {
+	name         string
+	isin         string // Set for stocks, whose stored closing prices are used when available
+	quantity     float64
+	lastPriceEUR float64   // Last traded price per unit, the fallback when no closing price is stored
+	expiry       time.Time // Options only; the position is worthless after this date
+}
+
+// dailyValue is the portfolio value at the end of a day and the external flow that entered it during the day.
+type dailyValue struct {
+	date  time.Time
+	value float64
+	flow  float64
+}
+
+// Process replays the transactions day by day into end-of-day portfolio values: cash (the running sum of EUR
+// amounts and commissions) plus open positions at their latest closing price on or before the day, or else their
+// last trade price. Returns between from and to are chained from the daily values (time-weighted) and solved from the
+// external flows (money-weighted XIRR), for the whole period and for each calendar year in it.
+func (p *performanceProcessorImpl) Process(transactions []models.ProcessedTransaction, priceHistory map[string][]prices.Quote, from, to time.Time) models.PerformanceReport {
+	report := models.PerformanceReport{
+		PerformancePeriod: models.PerformancePeriod{From: from.Format("02-01-2006"), To: to.Format("02-01-2006")},
+		Years:             []models.PerformancePeriod{},
+		Series:            []models.PerformancePoint{},
+		UnpricedPositions: []string{},
+	}
+	sorted := sortTransactionsChronologically(transactions)
+	if len(sorted) == 0 || to.Before(from) {
+		return report
+	}
+
+	start := utils.ParseDate(sorted[0].Date)
+	if from.Before(start) {
+		start = from
+	}
+	feeOrders := feeOrderKeys(sorted)
+	closes := newClosingPrices(priceHistory)
+	positions := make(map[string]*heldPosition)
+	cash := 0.0
+	startValue := 0.0
+	var series []dailyValue
+
+	next := 0
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		flow := 0.0
+		for next < len(sorted) && !utils.ParseDate(sorted[next].Date).After(day) {
+			tx := sorted[next]
+			next++
+			cash += cashEffectEUR(tx, feeOrders)
+			flow += externalFlowEUR(tx) + applyPositionChange(positions, tx, closes, day)
+		}
+		value := cash
+		for _, pos := range positions {
+			value += pos.valueEUR(closes, day)
+		}
+		if day.Before(from) {
+			startValue = value
+			continue
+		}
+		series = append(series, dailyValue{date: day, value: value, flow: flow})
+	}
+
+	report.PerformancePeriod = summarisePeriod(series, startValue)
+	days := to.Sub(from).Hours()/24 + 1
+	report.AnnualisedTWR = report.TimeWeightedReturn
+	if days >= 365 {
+		report.AnnualisedTWR = utils.RoundFloat((math.Pow(1+report.TimeWeightedReturn/100, 365/days)-1)*100, 2)
+	}
+
+	yearStart, yearStartValue := 0, startValue
+	for i := range series {
+		if i == len(series)-1 || series[i+1].date.Year() != series[i].date.Year() {
+			report.Years = append(report.Years, summarisePeriod(series[yearStart:i+1], yearStartValue))
+			yearStart, yearStartValue = i+1, series[i].value
+		}
+	}
+
+	contributions := 0.0
+	for _, point := range series {
+		contributions += point.flow
+		report.Series = append(report.Series, models.PerformancePoint{
+			Date:                point.date.Format("02-01-2006"),
+			ValueEUR:            utils.RoundFloat(point.value, 2),
+			NetContributionsEUR: utils.RoundFloat(contributions, 2),
+		})
+	}
+
+	for _, pos := range positions {
+		if _, priced := closes.priceEUR(pos.isin, to); !priced && pos.valueEUR(closes, to) != 0 {
+			report.UnpricedPositions = append(report.UnpricedPositions, pos.name)
+		}
+	}
+	sort.Strings(report.UnpricedPositions)
+	return report
+}
+
+// CompareBenchmark replays the portfolio as in Process and, alongside it, a portfolio that puts the same starting
+// value and external flows into the benchmark at its closing price of the day (withdrawals sell units).
+func (p *performanceProcessorImpl) CompareBenchmark(transactions []models.ProcessedTransaction, priceHistory map[string][]prices.Quote, benchmarkISIN string, benchmarkQuotes []prices.Quote, from, to time.Time) models.BenchmarkComparison {
+	report := p.Process(transactions, priceHistory, from, to)
+	comparison := models.BenchmarkComparison{
+		BenchmarkISIN: benchmarkISIN,
+		Portfolio:     report.PerformancePeriod,
+		Series:        []models.BenchmarkPoint{},
+	}
+	if len(report.Series) == 0 {
+		return comparison
+	}
+
+	closes := newClosingPrices(map[string][]prices.Quote{benchmarkISIN: benchmarkQuotes})
+	units, uninvested, contributions := 0.0, report.StartValueEUR, 0.0
+	var benchmarkSeries []dailyValue
+	for _, point := range report.Series {
+		day := utils.ParseDate(point.Date)
+		flow := point.NetContributionsEUR - contributions
+		contributions = point.NetContributionsEUR
+		uninvested += flow
+
+		value := uninvested
+		if price, ok := closes.priceEUR(benchmarkISIN, day); ok && price > 0 {
+			units += uninvested / price
+			uninvested = 0
+			value = units * price
+		}
+		benchmarkSeries = append(benchmarkSeries, dailyValue{date: day, value: value, flow: flow})
+		comparison.Series = append(comparison.Series, models.BenchmarkPoint{
+			Date:                point.Date,
+			PortfolioValueEUR:   point.ValueEUR,
+			BenchmarkValueEUR:   utils.RoundFloat(value, 2),
+			NetContributionsEUR: point.NetContributionsEUR,
+		})
+	}
+
+	comparison.Benchmark = summarisePeriod(benchmarkSeries, report.StartValueEUR)
+	comparison.ExcessTimeWeightedReturn = utils.RoundFloat(comparison.Portfolio.TimeWeightedReturn-comparison.Benchmark.TimeWeightedReturn, 2)
+	if comparison.Portfolio.MoneyWeightedReturn != nil && comparison.Benchmark.MoneyWeightedReturn != nil {
+		excess := utils.RoundFloat(*comparison.Portfolio.MoneyWeightedReturn-*comparison.Benchmark.MoneyWeightedReturn, 2)
+		comparison.ExcessMoneyWeightedReturn = &excess
+	}
+	comparison.ExcessValueEUR = utils.RoundFloat(comparison.Portfolio.EndValueEUR-comparison.Benchmark.EndValueEUR, 2)
+	return comparison
+}
+
+// summarisePeriod computes the returns over consecutive days, starting from the value at the end of the day before.
+func summarisePeriod(series []dailyValue, startValue float64) models.PerformancePeriod {
+	if len(series) == 0 {
+		return models.PerformancePeriod{}
+	}
+	period := models.PerformancePeriod{
+		From:          series[0].date.Format("02-01-2006"),
+		To:            series[len(series)-1].date.Format("02-01-2006"),
+		StartValueEUR: utils.RoundFloat(startValue, 2),
+		EndValueEUR:   utils.RoundFloat(series[len(series)-1].value, 2),
+	}
+
+	// Flows are assumed to arrive at the start of the day, so they earn that day's return.
+	growth, previous := 1.0, startValue
+	amounts := []float64{-startValue}
+	dates := []time.Time{series[0].date.AddDate(0, 0, -1)}
+	for _, day := range series {
+		if base := previous + day.flow; base >= minReturnBaseEUR {
+			growth *= day.value / base
+		}
+		previous = day.value
+		period.NetContributionsEUR += day.flow
+		if day.flow != 0 {
+			amounts = append(amounts, -day.flow)
+			dates = append(dates, day.date)
+		}
+	}
+	amounts = append(amounts, series[len(series)-1].value)
+	dates = append(dates, series[len(series)-1].date)
+
+	period.NetContributionsEUR = utils.RoundFloat(period.NetContributionsEUR, 2)
+	period.GainEUR = utils.RoundFloat(period.EndValueEUR-period.StartValueEUR-period.NetContributionsEUR, 2)
+	period.TimeWeightedReturn = utils.RoundFloat((growth-1)*100, 2)
+	if rate, ok := utils.XIRR(amounts, dates); ok {
+		percent := utils.RoundFloat(rate*100, 2)
+		period.MoneyWeightedReturn = &percent
+	}
+	return period
+}
+
+// applyPositionChange updates the position a transaction trades and returns the EUR value of securities
+// transferred in (positive) or out (negative), which are external flows.
+func applyPositionChange(positions map[string]*heldPosition, tx models.ProcessedTransaction, closes *closingPrices, day time.Time) float64 {
+	key, ok := positionKey(tx)
+	if !ok {
+		return 0
+	}
+	pos, exists := positions[key]
+	if !exists {
+		pos = &heldPosition{name: tx.ProductName}
+		if tx.TransactionType == "STOCK" {
+			pos.isin = tx.ISIN
+		}
+		if tx.TransactionType == "OPTION" {
+			pos.expiry, _ = optionExpiry(tx)
+		}
+		positions[key] = pos
+	}
+
+	qty := math.Abs(tx.Quantity)
+	if (tx.BuySell == "BUY" || tx.BuySell == "SELL" || tx.BuySell == "TRANSFER_IN") && qty > 0 && tx.AmountEUR != 0 &&
+		tx.TransactionSubType != "SWAP" {
+		pos.lastPriceEUR = math.Abs(tx.AmountEUR) / qty
+	}
+
+	flow := 0.0
+	switch tx.BuySell {
+	case "BUY":
+		pos.quantity += qty
+	case "SELL", "FEE":
+		pos.quantity -= qty
+	case "SPLIT":
+		pos.quantity += tx.Quantity
+	case "TRANSFER_IN":
+		pos.quantity += qty
+		flow = qty * pos.unitPriceEUR(closes, day)
+	case "TRANSFER_OUT":
+		flow = -qty * pos.unitPriceEUR(closes, day)
+		pos.quantity -= qty
+	}
+	if math.Abs(pos.quantity) <= quantityEpsilon {
+		delete(positions, key)
+	}
+	return flow
+}
+
+// positionKey identifies the instrument a transaction trades; it reports false for rows that hold no position.
+func positionKey(tx models.ProcessedTransaction) (string, bool) {
+	switch tx.TransactionType {
+	case "STOCK":
+		return "STOCK|" + stockLotKey(tx), true
+	case "BOND":
+		if tx.TransactionSubType == BondSubTypeCoupon || tx.TransactionSubType == BondSubTypeAccruedInterest {
+			return "", false
+		}
+		return "BOND|" + stockLotKey(tx), true
+	case "OPTION":
+		return "OPTION|" + OptionContractKey(tx), true
+	case "CRYPTO":
+		return "CRYPTO|" + tx.ProductName, true
+	}
+	return "", false
+}
+
+func (pos *heldPosition) unitPriceEUR(closes *closingPrices, day time.Time) float64 {
+	if price, ok := closes.priceEUR(pos.isin, day); ok {
+		return price
+	}
+	return pos.lastPriceEUR
+}
+
+func (pos *heldPosition) valueEUR(closes *closingPrices, day time.Time) float64 {
+	if !pos.expiry.IsZero() && day.After(pos.expiry) {
+		return 0
+	}
+	return pos.quantity * pos.unitPriceEUR(closes, day)
+}
+
+// externalFlowEUR returns the EUR amount of a deposit (positive) or withdrawal (negative).
+func externalFlowEUR(tx models.ProcessedTransaction) float64 {
+	if tx.TransactionType != "CASH" {
+		return 0
+	}
+	switch tx.TransactionSubType {
+	case "DEPOSIT":
+		return math.Abs(tx.AmountEUR)
+	case "WITHDRAWAL":
+		return -math.Abs(tx.AmountEUR)
+	}
+	return 0
+}
+
+// cashEffectEUR returns the change a transaction makes to the cash balance in EUR, including its commission.
+// Transfers and splits move no cash, and crypto swaps and crypto fees are settled in crypto.
+func cashEffectEUR(tx models.ProcessedTransaction, feeOrders map[string]bool) float64 {
+	if !movesCash(tx) {
+		return 0
+	}
+	if !chargesCommission(tx, feeOrders) {
+		return tx.AmountEUR
+	}
+	return tx.AmountEUR - commissionEUR(tx)
+}
+
+// movesCash reports whether a transaction changes its account's cash: transfers and splits do not, nor do crypto
+// swaps and crypto fees settled in crypto.
+func movesCash(tx models.ProcessedTransaction) bool {
+	switch tx.BuySell {
+	case "TRANSFER_IN", "TRANSFER_OUT", "SPLIT":
+		return false
+	}
+	return tx.TransactionType != "CRYPTO" || (tx.TransactionSubType != "SWAP" && tx.TransactionSubType != "FEE")
+}
+
+// chargesCommission reports whether a trade's commission has to be deducted from its cash effect, which is not the
+// case when the broker also books it as a FEE row of its own for the same order (as DeGiro does).
+func chargesCommission(tx models.ProcessedTransaction, feeOrders map[string]bool) bool {
+	return tx.Commission != 0 && tx.TransactionType != "FEE" && !feeOrders[tx.Source+"|"+tx.OrderID]
+}
+
+// feeOrderKeys collects the orders that have FEE rows of their own.
+func feeOrderKeys(transactions []models.ProcessedTransaction) map[string]bool {
+	keys := make(map[string]bool)
+	for _, tx := range transactions {
+		if tx.TransactionType == "FEE" && tx.OrderID != "" {
+			keys[tx.Source+"|"+tx.OrderID] = true
+		}
+	}
+	return keys
+}
+
+// closingPrices serves the stored closing prices in EUR for days asked in ascending order.
+type closingPrices struct {
+	quotes  map[string][]prices.Quote
+	next    map[string]int
+	current map[string]float64
+	rates   map[string]float64 // EUR rate by currency and date, as the rate lookup scans the whole table
+}
+
+func newClosingPrices(quotes map[string][]prices.Quote) *closingPrices {
+	return &closingPrices{
+		quotes:  quotes,
+		next:    make(map[string]int),
+		current: make(map[string]float64),
+		rates:   make(map[string]float64),
+	}
+}
+
+// priceEUR returns the EUR value of the latest quote of an ISIN on or before day. Days must not go backwards.
+func (c *closingPrices) priceEUR(isin string, day time.Time) (float64, bool) {
+	if isin == "" {
+		return 0, false
+	}
+	quotes := c.quotes[isin]
+	i := c.next[isin]
+	for i < len(quotes) && !quotes[i].Date.After(day) {
+		if rate := c.rate(quotes[i].Currency, quotes[i].Date); rate > 0 {
+			c.current[isin] = quotes[i].Close / rate
+		}
+		i++
+	}
+	c.next[isin] = i
+	price, ok := c.current[isin]
+	return price, ok
+}
+
+func (c *closingPrices) rate(currency string, date time.Time) float64 {
+	key := currency + "|" + date.Format("2006-01-02")
+	if rate, ok := c.rates[key]; ok {
+		return rate
+	}
+	rate, err := GetExchangeRate(currency, date)
+	if err != nil {
+		rate = 0
+	}
+	c.rates[key] = rate
+	return rate
+}
+
+func sortTransactionsChronologically(transactions []models.ProcessedTransaction) []models.ProcessedTransaction {
+	sorted := make([]models.ProcessedTransaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		dateI := utils.ParseDate(sorted[i].Date)
+		dateJ := utils.ParseDate(sorted[j].Date)
+		if dateI.Equal(dateJ) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return dateI.Before(dateJ)
+	})
+	return sorted
+}
@@ -0,0 +1,149 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/prices"
+)
+
+// deposit builds an EUR cash deposit.
+func deposit(id int64, date string, amount float64) models.ProcessedTransaction {
+	return models.ProcessedTransaction{ID: id, Date: date, Source: "degiro", TransactionType: "CASH", TransactionSubType: "DEPOSIT",
+		Amount: amount, AmountEUR: amount, Currency: "EUR", ExchangeRate: 1}
+}
+
+func TestPerformanceProcessor(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name             string
+		transactions     []models.ProcessedTransaction
+		from, to         time.Time
+		wantTWR          float64
+		wantMWR          *float64
+		wantGain         float64
+		wantContribution float64
+		wantYearTWRs     []float64
+	}{
+		{
+			name: "deposits do not count as returns",
+			transactions: []models.ProcessedTransaction{
+				deposit(1, "31-12-2023", 1000),
+				stockTrade(2, "degiro", "31-12-2023", "BUY", 10, -1000),
+				deposit(3, "01-01-2024", 1100),
+				stockTrade(4, "degiro", "01-01-2024", "BUY", 10, -1100),
+				stockTrade(5, "degiro", "02-01-2024", "SELL", 20, 2000),
+			},
+			from:             day(2023, 12, 31),
+			to:               day(2024, 1, 2),
+			wantTWR:          -4.76,
+			wantGain:         -100,
+			wantContribution: 2100,
+			wantYearTWRs:     []float64{0, -4.76},
+		},
+		{
+			name: "money-weighted return of a year's holding",
+			transactions: []models.ProcessedTransaction{
+				deposit(1, "01-01-2023", 1000),
+				stockTrade(2, "degiro", "01-01-2023", "BUY", 10, -1000),
+				stockTrade(3, "degiro", "01-01-2024", "SELL", 10, 1100),
+			},
+			from:             day(2023, 1, 1),
+			to:               day(2024, 1, 1),
+			wantTWR:          10,
+			wantMWR:          floatPtr(10),
+			wantGain:         100,
+			wantContribution: 1000,
+			wantYearTWRs:     []float64{0, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewPerformanceProcessor().Process(tt.transactions, nil, tt.from, tt.to)
+			if report.TimeWeightedReturn != tt.wantTWR || report.GainEUR != tt.wantGain || report.NetContributionsEUR != tt.wantContribution {
+				t.Errorf("TWR %v gain %v contributions %v, want %v %v %v", report.TimeWeightedReturn, report.GainEUR, report.NetContributionsEUR, tt.wantTWR, tt.wantGain, tt.wantContribution)
+			}
+			if tt.wantMWR != nil && (report.MoneyWeightedReturn == nil || *report.MoneyWeightedReturn != *tt.wantMWR) {
+				t.Errorf("MoneyWeightedReturn = %v, want %v", report.MoneyWeightedReturn, *tt.wantMWR)
+			}
+			if len(report.Years) != len(tt.wantYearTWRs) {
+				t.Fatalf("got %d years, want %d", len(report.Years), len(tt.wantYearTWRs))
+			}
+			for i, want := range tt.wantYearTWRs {
+				if report.Years[i].TimeWeightedReturn != want {
+					t.Errorf("year %s TWR = %v, want %v", report.Years[i].From, report.Years[i].TimeWeightedReturn, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareBenchmark(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	quote := func(d int, close float64) prices.Quote {
+		return prices.Quote{ISIN: "IE00B4L5Y983", Date: day(d), Close: close, Currency: "EUR"}
+	}
+	// The portfolio only holds cash, so its return is zero and the benchmark's is the excess.
+	transactions := []models.ProcessedTransaction{deposit(1, "01-01-2024", 1000), deposit(2, "03-01-2024", 1100)}
+
+	tests := []struct {
+		name          string
+		quotes        []prices.Quote
+		wantValues    []float64
+		wantTWR       float64
+		wantExcessTWR float64
+		wantExcessEUR float64
+	}{
+		{
+			name:          "contributions buy the benchmark on their day",
+			quotes:        []prices.Quote{quote(1, 100), quote(2, 110), quote(3, 121)},
+			wantValues:    []float64{1000, 1100, 2310},
+			wantTWR:       15.5,
+			wantExcessTWR: -15.5,
+			wantExcessEUR: -210,
+		},
+		{
+			name:          "contributions wait in cash for the first benchmark price",
+			quotes:        []prices.Quote{quote(2, 100), quote(3, 110)},
+			wantValues:    []float64{1000, 1000, 2200},
+			wantTWR:       4.76,
+			wantExcessTWR: -4.76,
+			wantExcessEUR: -100,
+		},
+		{
+			name:       "days without a price keep the last close",
+			quotes:     []prices.Quote{quote(1, 100)},
+			wantValues: []float64{1000, 1000, 2100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := NewPerformanceProcessor().CompareBenchmark(transactions, nil, "IE00B4L5Y983", tt.quotes, day(1), day(3))
+			if len(comparison.Series) != len(tt.wantValues) {
+				t.Fatalf("got %d points, want %d", len(comparison.Series), len(tt.wantValues))
+			}
+			for i, want := range tt.wantValues {
+				point := comparison.Series[i]
+				if point.BenchmarkValueEUR != want {
+					t.Errorf("%s: benchmark value %v, want %v", point.Date, point.BenchmarkValueEUR, want)
+				}
+				if point.PortfolioValueEUR != point.NetContributionsEUR {
+					t.Errorf("%s: portfolio value %v, want its contributions %v", point.Date, point.PortfolioValueEUR, point.NetContributionsEUR)
+				}
+			}
+			if comparison.Benchmark.TimeWeightedReturn != tt.wantTWR || comparison.ExcessTimeWeightedReturn != tt.wantExcessTWR || comparison.ExcessValueEUR != tt.wantExcessEUR {
+				t.Errorf("benchmark TWR %v excess TWR %v excess value %v, want %v %v %v", comparison.Benchmark.TimeWeightedReturn,
+					comparison.ExcessTimeWeightedReturn, comparison.ExcessValueEUR, tt.wantTWR, tt.wantExcessTWR, tt.wantExcessEUR)
+			}
+			if comparison.Benchmark.NetContributionsEUR != comparison.Portfolio.NetContributionsEUR {
+				t.Errorf("benchmark contributions %v, want the portfolio's %v", comparison.Benchmark.NetContributionsEUR, comparison.Portfolio.NetContributionsEUR)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 { return &v }
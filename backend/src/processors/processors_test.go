@@ -0,0 +1,20 @@
+package processors
+
+import (
+	"os"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error")
+	if err := utils.InitCountryData("../../data/country.json"); err != nil {
+		panic(err)
+	}
+	if err := LoadHistoricalRates("../../data/historicalExchangeRate.json"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
@@ -1,34 +1,112 @@
 package processors
 
 import (
+	"math"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/username/taxfolio/backend/src/models"
 	"github.com/username/taxfolio/backend/src/utils"
 )
 
+// quantityEpsilon absorbs floating point residue when fractional share quantities are matched down to zero.
+const quantityEpsilon = 1e-9
+
 type stockProcessorImpl struct{}
 
 func NewStockProcessor() StockProcessor {
 	return &stockProcessorImpl{}
 }
 
-func (p *stockProcessorImpl) Process(transactions []models.ProcessedTransaction) ([]models.SaleDetail, map[string][]models.PurchaseLot) {
+func (p *stockProcessorImpl) Process(transactions []models.ProcessedTransaction) ([]models.SaleDetail, map[string][]models.PurchaseLot, []models.LotMatchingError) {
 	stockTransactions := filterAndSortStockTransactions(transactions)
 	if len(stockTransactions) == 0 {
-		return []models.SaleDetail{}, make(map[string][]models.PurchaseLot)
+		return []models.SaleDetail{}, make(map[string][]models.PurchaseLot), []models.LotMatchingError{}
 	}
 	return calculateSalesAndYearlyHoldings(stockTransactions)
 }
 
-func calculateSalesAndYearlyHoldings(transactions []models.ProcessedTransaction) ([]models.SaleDetail, map[string][]models.PurchaseLot) {
+// HoldingsAt replays the transactions dated up to asOf and returns the final snapshot of open lots.
+func (p *stockProcessorImpl) HoldingsAt(transactions []models.ProcessedTransaction, asOf time.Time) []models.PurchaseLot {
+	stockTransactions := filterAndSortStockTransactions(transactionsUpTo(transactions, asOf))
+	if len(stockTransactions) == 0 {
+		return []models.PurchaseLot{}
+	}
+	_, holdingsByYear, _ := calculateSalesAndYearlyHoldings(stockTransactions)
+	lastYear := utils.ParseDate(stockTransactions[len(stockTransactions)-1].Date).Year()
+	holdings := holdingsByYear[strconv.Itoa(lastYear)]
+	if holdings == nil {
+		holdings = []models.PurchaseLot{}
+	}
+	return holdings
+}
+
+// SimulateSale matches a hypothetical SELL FIFO against the lots open at the end of its date, after that day's
+// own transactions, and estimates the extra tax on the year's gains. The realised gain is net of the sale's own
+// commission. The transactions are not changed.
+func (p *stockProcessorImpl) SimulateSale(transactions []models.ProcessedTransaction, sale models.ProcessedTransaction) models.SaleSimulation {
+	saleDate := utils.ParseDate(sale.Date)
+	upToSale := transactionsUpTo(transactions, saleDate)
+	stockTransactions := filterAndSortStockTransactions(upToSale)
+	baseline, _, _ := calculateSalesAndYearlyHoldings(stockTransactions)
+	// The sale is the last event of its day, so its matches are the sale details added after the baseline's.
+	withSale, _, _ := calculateSalesAndYearlyHoldings(append(stockTransactions, sale))
+
+	simulation := models.SaleSimulation{
+		Date:                sale.Date,
+		ISIN:                sale.ISIN,
+		ProductName:         sale.ProductName,
+		Quantity:            sale.Quantity,
+		Price:               sale.Price,
+		Currency:            sale.Currency,
+		ExchangeRate:        sale.ExchangeRate,
+		SaleAmountEUR:       utils.RoundFloat(sale.AmountEUR, 2),
+		CommissionEUR:       utils.RoundFloat(commissionEUR(sale), 2),
+		Lots:                []models.SimulatedSaleLot{},
+		YearRealisedGainEUR: YearRealisedGainEUR(upToSale, saleDate),
+		TaxRate:             AutonomousTaxRate,
+	}
+	matchedQty := 0.0
+	for _, detail := range withSale[len(baseline):] {
+		simulation.Lots = append(simulation.Lots, models.SimulatedSaleLot{
+			SaleDetail:  detail,
+			HoldingDays: int(saleDate.Sub(utils.ParseDate(detail.BuyDate)).Hours() / 24),
+		})
+		simulation.RealisedGainEUR += detail.Delta
+		matchedQty += detail.Quantity
+	}
+	if unmatched := sale.Quantity - matchedQty; unmatched > quantityEpsilon {
+		simulation.UnmatchedQuantity = unmatched
+	}
+	simulation.RealisedGainEUR = utils.RoundFloat(simulation.RealisedGainEUR-simulation.CommissionEUR, 2)
+	simulation.EstimatedTaxEUR = utils.RoundFloat(capitalGainsTax(simulation.YearRealisedGainEUR+simulation.RealisedGainEUR)-capitalGainsTax(simulation.YearRealisedGainEUR), 2)
+	return simulation
+}
+
+// transactionsUpTo returns the transactions dated on or before asOf.
+func transactionsUpTo(transactions []models.ProcessedTransaction, asOf time.Time) []models.ProcessedTransaction {
+	var upTo []models.ProcessedTransaction
+	for _, tx := range transactions {
+		if !utils.ParseDate(tx.Date).After(asOf) {
+			upTo = append(upTo, tx)
+		}
+	}
+	return upTo
+}
+
+func calculateSalesAndYearlyHoldings(transactions []models.ProcessedTransaction) ([]models.SaleDetail, map[string][]models.PurchaseLot, []models.LotMatchingError) {
 	saleDetails := []models.SaleDetail{}
 	holdingsByYear := make(map[string][]models.PurchaseLot)
-	openPurchasesByISIN := make(map[string][]*models.ProcessedTransaction)
+	matchingErrors := []models.LotMatchingError{}
+	openPurchasesByKey := make(map[string][]*models.ProcessedTransaction)
+	// Shares sold short and not yet bought back, kept as positive quantities of the opening SELL.
+	openShortsByKey := make(map[string][]*models.ProcessedTransaction)
+	// Lots that left a broker with TRANSFER_OUT and have not yet arrived with TRANSFER_IN.
+	inTransitByKey := make(map[string][]*models.ProcessedTransaction)
 
 	if len(transactions) == 0 {
-		return saleDetails, holdingsByYear
+		return saleDetails, holdingsByYear, matchingErrors
 	}
 
 	lastProcessedYear := utils.ParseDate(transactions[0].Date).Year()
@@ -36,9 +114,10 @@ func calculateSalesAndYearlyHoldings(transactions []models.ProcessedTransaction)
 	for _, tx := range transactions {
 		txDate := utils.ParseDate(tx.Date)
 		currentYear := txDate.Year()
+		lotKey := stockLotKey(tx)
 
 		if currentYear > lastProcessedYear {
-			snapshot := collectAndCopyHoldings(openPurchasesByISIN)
+			snapshot := collectAndCopyHoldings(openPurchasesByKey, openShortsByKey)
 			holdingsByYear[strconv.Itoa(lastProcessedYear)] = snapshot
 			for year := lastProcessedYear + 1; year < currentYear; year++ {
 				holdingsByYear[strconv.Itoa(year)] = snapshot
@@ -46,20 +125,58 @@ func calculateSalesAndYearlyHoldings(transactions []models.ProcessedTransaction)
 		}
 
 		if tx.TransactionType == "STOCK" && tx.BuySell == "BUY" {
-			purchaseCopy := tx
-			openPurchasesByISIN[tx.ISIN] = append(openPurchasesByISIN[tx.ISIN], &purchaseCopy)
+			// A buy first covers open short positions; only the rest opens a long lot.
+			remainingQty := coverShorts(tx, openShortsByKey, lotKey, &saleDetails)
+			if remainingQty >= tx.Quantity-quantityEpsilon {
+				purchaseCopy := tx
+				openPurchasesByKey[lotKey] = append(openPurchasesByKey[lotKey], &purchaseCopy)
+			} else if remainingQty > quantityEpsilon {
+				openPurchasesByKey[lotKey] = append(openPurchasesByKey[lotKey], partialLot(tx, remainingQty))
+			}
+		} else if tx.TransactionType == "STOCK" && tx.BuySell == "SPLIT" {
+			applied := applySplit(openPurchasesByKey[lotKey], tx.Quantity)
+			if !applied && len(openPurchasesByKey[lotKey]) == 0 {
+				// A split of a short position reports the change of the (negative) position.
+				applied = applySplit(openShortsByKey[lotKey], -tx.Quantity)
+			}
+			if !applied {
+				matchingErrors = append(matchingErrors, newLotMatchingError(tx, math.Abs(tx.Quantity), "split applies to a product with no open lots"))
+			}
+		} else if tx.TransactionType == "STOCK" && tx.BuySell == "TRANSFER_OUT" {
+			moved, remaining := takeLots(openPurchasesByKey, lotKey, tx.Quantity)
+			inTransitByKey[lotKey] = append(inTransitByKey[lotKey], moved...)
+			if remaining > quantityEpsilon {
+				matchingErrors = append(matchingErrors, newLotMatchingError(tx, remaining, "transfer out exceeds the open lots for this product"))
+			}
+		} else if tx.TransactionType == "STOCK" && tx.BuySell == "TRANSFER_IN" {
+			// Lots keep their original buy date and EUR cost from the sending broker and are held at the receiving one.
+			arrived, remaining := takeLots(inTransitByKey, lotKey, tx.Quantity)
+			for _, lot := range arrived {
+				lot.Source = tx.Source
+			}
+			openPurchasesByKey[lotKey] = append(openPurchasesByKey[lotKey], arrived...)
+			if remaining > quantityEpsilon {
+				if tx.Amount != 0 {
+					// The row itself carries the cost basis (e.g. entered manually from the sending broker's statement).
+					openPurchasesByKey[lotKey] = append(openPurchasesByKey[lotKey], partialLot(tx, remaining))
+				} else if openQuantityFromOtherSources(openPurchasesByKey[lotKey], tx.Source) < remaining {
+					// When the sending broker's history has no TRANSFER_OUT, its lots are still open under the same
+					// product and already stand for the transferred shares. Otherwise there is no cost basis to carry over.
+					matchingErrors = append(matchingErrors, newLotMatchingError(tx, remaining, "transfer in has no cost basis and no matching lots from the sending broker"))
+				}
+			}
 		} else if tx.TransactionType == "STOCK" && tx.BuySell == "SELL" {
 			remainingQty := tx.Quantity
-			purchaseLots := openPurchasesByISIN[tx.ISIN]
+			purchaseLots := openPurchasesByKey[lotKey]
 
-			for remainingQty > 0 && len(purchaseLots) > 0 {
+			for remainingQty > quantityEpsilon && len(purchaseLots) > 0 {
 				currentPurchase := purchaseLots[0]
-				matchedQty := utils.MinInt(remainingQty, currentPurchase.Quantity)
+				matchedQty := math.Min(remainingQty, currentPurchase.Quantity)
 
-				saleRatio := float64(matchedQty) / float64(tx.Quantity)
+				saleRatio := matchedQty / tx.Quantity
 				var purchaseRatio float64
 				if currentPurchase.OriginalQuantity > 0 {
-					purchaseRatio = float64(matchedQty) / float64(currentPurchase.OriginalQuantity)
+					purchaseRatio = matchedQty / currentPurchase.OriginalQuantity
 				}
 				buyCommissionToAdd := 0.0
 				if currentPurchase.Commission > 0 {
@@ -88,48 +205,220 @@ func calculateSalesAndYearlyHoldings(transactions []models.ProcessedTransaction)
 					BuyExchangeRate:  currentPurchase.ExchangeRate,
 					Commission:       utils.RoundFloat(totalDetailCommission, 2),
 					Delta:            utils.RoundFloat(buyAmountEUR+saleAmountEUR, 2),
-					CountryCode:      utils.GetCountryCodeString(tx.ISIN),
+					CountryCode:      transactionCountry(tx),
+					InstrumentClass:  tx.InstrumentClass,
 				})
 
 				remainingQty -= matchedQty
 				currentPurchase.Quantity -= matchedQty
-				if currentPurchase.Quantity == 0 {
+				if currentPurchase.Quantity <= quantityEpsilon {
 					purchaseLots = purchaseLots[1:]
 				}
-				openPurchasesByISIN[tx.ISIN] = purchaseLots
+				openPurchasesByKey[lotKey] = purchaseLots
+			}
+
+			if remainingQty > quantityEpsilon {
+				// An oversell usually means missing history, so it is always reported. At brokers that allow short
+				// selling the rest opens a short position, closed by later buys.
+				reason := "sale exceeds the open lots for this product"
+				if shortSellingSources[tx.Source] {
+					openShortsByKey[lotKey] = append(openShortsByKey[lotKey], partialLot(tx, remainingQty))
+					reason += "; the rest is held as a short position"
+				}
+				matchingErrors = append(matchingErrors, newLotMatchingError(tx, remainingQty, reason))
 			}
 		}
 
 		lastProcessedYear = currentYear
 	}
 
-	finalSnapshot := collectAndCopyHoldings(openPurchasesByISIN)
+	finalSnapshot := collectAndCopyHoldings(openPurchasesByKey, openShortsByKey)
 	holdingsByYear[strconv.Itoa(lastProcessedYear)] = finalSnapshot
 
-	return saleDetails, holdingsByYear
+	return saleDetails, holdingsByYear, matchingErrors
+}
+
+// shortSellingSources are the sources whose sales may exceed the open lots on purpose: IBKR margin accounts, and
+// manual entries for any broker that allows it. Elsewhere an oversell can only be missing history.
+var shortSellingSources = map[string]bool{
+	"ibkr":   true,
+	"manual": true,
+}
+
+// coverShorts matches a BUY FIFO against the key's open short positions, recording a short SaleDetail per match.
+// The gain is realised when the position is closed, so SaleDate is the covering buy and BuyDate the opening sale.
+// It returns the bought quantity left once every short position is covered.
+func coverShorts(tx models.ProcessedTransaction, openShortsByKey map[string][]*models.ProcessedTransaction, lotKey string, saleDetails *[]models.SaleDetail) float64 {
+	remainingQty := tx.Quantity
+	shorts := openShortsByKey[lotKey]
+	for remainingQty > quantityEpsilon && len(shorts) > 0 {
+		short := shorts[0]
+		matchedQty := math.Min(remainingQty, short.Quantity)
+
+		buyRatio := matchedQty / tx.Quantity
+		var shortRatio float64
+		if short.OriginalQuantity > 0 {
+			shortRatio = matchedQty / short.OriginalQuantity
+		}
+		// Like a buy commission on long lots, the opening sale's commission goes to the first match.
+		shortCommissionToAdd := short.Commission
+		short.Commission = 0
+		saleAmountEUR := utils.RoundFloat(short.AmountEUR*shortRatio, 2)
+		buyAmountEUR := utils.RoundFloat(tx.AmountEUR*buyRatio, 2)
+
+		*saleDetails = append(*saleDetails, models.SaleDetail{
+			SaleDate:         tx.Date,
+			BuyDate:          short.Date,
+			ProductName:      short.ProductName,
+			ISIN:             short.ISIN,
+			Quantity:         matchedQty,
+			SaleAmount:       short.Amount * shortRatio,
+			SaleCurrency:     short.Currency,
+			SaleAmountEUR:    saleAmountEUR,
+			SalePrice:        short.Price,
+			SaleExchangeRate: short.ExchangeRate,
+			BuyAmount:        tx.Amount * buyRatio,
+			BuyCurrency:      tx.Currency,
+			BuyAmountEUR:     buyAmountEUR,
+			BuyPrice:         tx.Price,
+			BuyExchangeRate:  tx.ExchangeRate,
+			Commission:       utils.RoundFloat(tx.Commission*buyRatio+shortCommissionToAdd, 2),
+			Delta:            utils.RoundFloat(buyAmountEUR+saleAmountEUR, 2),
+			CountryCode:      transactionCountry(*short),
+			InstrumentClass:  short.InstrumentClass,
+			Short:            true,
+		})
+
+		remainingQty -= matchedQty
+		short.Quantity -= matchedQty
+		if short.Quantity <= quantityEpsilon {
+			shorts = shorts[1:]
+		}
+	}
+	openShortsByKey[lotKey] = shorts
+	return remainingQty
+}
+
+// partialLot returns a lot of quantity shares cut from tx, with its amounts and commission in proportion.
+func partialLot(tx models.ProcessedTransaction, quantity float64) *models.ProcessedTransaction {
+	ratio := quantity / tx.Quantity
+	lot := tx
+	lot.Quantity = quantity
+	lot.OriginalQuantity = quantity
+	lot.Amount = tx.Amount * ratio
+	lot.AmountEUR = tx.AmountEUR * ratio
+	lot.Commission = tx.Commission * ratio
+	return &lot
+}
+
+// stockLotKey identifies the pool a stock lot belongs to. Brokers that do not report ISINs (Revolut) fall back to
+// the product name, which for them is the ticker.
+func stockLotKey(tx models.ProcessedTransaction) string {
+	if tx.ISIN != "" {
+		return tx.ISIN
+	}
+	return "TICKER:" + tx.ProductName
+}
+
+// applySplit spreads a split's share delta over the open lots in proportion to their size.
+// Cost is unchanged; quantity and original quantity scale together so later partial sales keep the same cost per lot.
+func applySplit(lots []*models.ProcessedTransaction, shareDelta float64) bool {
+	openQty := 0.0
+	for _, lot := range lots {
+		openQty += lot.Quantity
+	}
+	if openQty <= quantityEpsilon || openQty+shareDelta <= 0 {
+		return false
+	}
+	factor := (openQty + shareDelta) / openQty
+	for _, lot := range lots {
+		lot.Quantity *= factor
+		lot.OriginalQuantity *= factor
+		lot.Price /= factor
+	}
+	return true
 }
 
-func collectAndCopyHoldings(holdingsMap map[string][]*models.ProcessedTransaction) []models.PurchaseLot {
+// takeLots removes up to quantity shares from the front of the key's lot queue, splitting the last lot if needed.
+// It returns the removed lots and the quantity that could not be covered.
+func takeLots(lotsByKey map[string][]*models.ProcessedTransaction, key string, quantity float64) ([]*models.ProcessedTransaction, float64) {
+	var taken []*models.ProcessedTransaction
+	lots := lotsByKey[key]
+	for quantity > quantityEpsilon && len(lots) > 0 {
+		lot := lots[0]
+		if lot.Quantity <= quantity+quantityEpsilon {
+			taken = append(taken, lot)
+			quantity -= lot.Quantity
+			lots = lots[1:]
+			continue
+		}
+		// Split: the moved part keeps the lot's date and per-share cost; the buy commission stays with the remainder.
+		part := *lot
+		part.Quantity = quantity
+		part.Commission = 0
+		lot.Quantity -= quantity
+		taken = append(taken, &part)
+		quantity = 0
+	}
+	lotsByKey[key] = lots
+	return taken, quantity
+}
+
+func openQuantityFromOtherSources(lots []*models.ProcessedTransaction, source string) float64 {
+	total := 0.0
+	for _, lot := range lots {
+		if lot.Source != source {
+			total += lot.Quantity
+		}
+	}
+	return total
+}
+
+func newLotMatchingError(tx models.ProcessedTransaction, unmatchedQty float64, message string) models.LotMatchingError {
+	return models.LotMatchingError{
+		Date:              tx.Date,
+		Source:            tx.Source,
+		ISIN:              tx.ISIN,
+		ProductName:       tx.ProductName,
+		Event:             tx.BuySell,
+		Quantity:          tx.Quantity,
+		UnmatchedQuantity: unmatchedQty,
+		Message:           message,
+	}
+}
+
+// collectAndCopyHoldings snapshots the open long lots and, with a negative quantity, the open short positions.
+// For a short position the buy fields hold the opening sale.
+func collectAndCopyHoldings(holdingsMap, shortsMap map[string][]*models.ProcessedTransaction) []models.PurchaseLot {
 	var snapshot []models.PurchaseLot
-	for _, lots := range holdingsMap {
-		for _, lot := range lots {
-			if lot.Quantity > 0 {
+	for _, positions := range []struct {
+		lotsByKey map[string][]*models.ProcessedTransaction
+		sign      float64
+	}{{holdingsMap, 1}, {shortsMap, -1}} {
+		for _, lots := range positions.lotsByKey {
+			for _, lot := range lots {
+				if lot.Quantity <= quantityEpsilon {
+					continue
+				}
 				var lotAmount, lotAmountEUR float64
 				if lot.OriginalQuantity > 0 {
-					ratio := float64(lot.Quantity) / float64(lot.OriginalQuantity)
+					ratio := lot.Quantity / lot.OriginalQuantity
 					lotAmount = lot.Amount * ratio
 					lotAmountEUR = lot.AmountEUR * ratio
 				}
 
 				snapshot = append(snapshot, models.PurchaseLot{
-					BuyDate:      lot.Date,
-					ProductName:  lot.ProductName,
-					ISIN:         lot.ISIN,
-					Quantity:     lot.Quantity,
-					BuyAmount:    lotAmount,
-					BuyCurrency:  lot.Currency,
-					BuyAmountEUR: utils.RoundFloat(lotAmountEUR, 2),
-					BuyPrice:     lot.Price,
+					Source:          lot.Source,
+					BuyDate:         lot.Date,
+					ProductName:     lot.ProductName,
+					ISIN:            lot.ISIN,
+					Quantity:        positions.sign * lot.Quantity,
+					BuyAmount:       lotAmount,
+					BuyCurrency:     lot.Currency,
+					BuyAmountEUR:    utils.RoundFloat(lotAmountEUR, 2),
+					BuyPrice:        lot.Price,
+					InstrumentClass: lot.InstrumentClass,
+					CountryCode:     transactionCountry(*lot),
 				})
 			}
 		}
@@ -137,6 +426,15 @@ func collectAndCopyHoldings(holdingsMap map[string][]*models.ProcessedTransactio
 	return snapshot
 }
 
+// sameDayStockEventRank orders same-day events so that shares are acquired or arrive before they leave or are sold.
+var sameDayStockEventRank = map[string]int{
+	"BUY":          0,
+	"SPLIT":        1,
+	"TRANSFER_OUT": 2,
+	"TRANSFER_IN":  3,
+	"SELL":         4,
+}
+
 func filterAndSortStockTransactions(transactions []models.ProcessedTransaction) []models.ProcessedTransaction {
 	var stockTx []models.ProcessedTransaction
 	for _, tx := range transactions {
@@ -148,11 +446,9 @@ func filterAndSortStockTransactions(transactions []models.ProcessedTransaction)
 		dateI := utils.ParseDate(stockTx[i].Date)
 		dateJ := utils.ParseDate(stockTx[j].Date)
 		if dateI.Equal(dateJ) {
-			if stockTx[i].BuySell == "SELL" && stockTx[j].BuySell == "BUY" {
-				return false
-			}
-			if stockTx[i].BuySell == "BUY" && stockTx[j].BuySell == "SELL" {
-				return true
+			rankI, rankJ := sameDayStockEventRank[stockTx[i].BuySell], sameDayStockEventRank[stockTx[j].BuySell]
+			if rankI != rankJ {
+				return rankI < rankJ
 			}
 			return stockTx[i].OrderID < stockTx[j].OrderID
 		}
@@ -0,0 +1,253 @@
+package processors
+
+import (
+	"math"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+// stockTrade builds an EUR stock transaction for the FIFO tests; amount follows the parsers' sign convention.
+func stockTrade(id int64, source, date, buySell string, quantity, amount float64) models.ProcessedTransaction {
+	return models.ProcessedTransaction{
+		ID:               id,
+		Date:             date,
+		Source:           source,
+		ProductName:      "ACME",
+		ISIN:             "NL0000000001",
+		Quantity:         quantity,
+		OriginalQuantity: quantity,
+		TransactionType:  "STOCK",
+		BuySell:          buySell,
+		Amount:           amount,
+		Currency:         "EUR",
+		ExchangeRate:     1,
+		AmountEUR:        amount,
+	}
+}
+
+func TestCalculateSalesAndYearlyHoldingsTransfers(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []models.ProcessedTransaction
+		wantBuyDates []string
+		wantDeltas   []float64
+		wantErrors   []string
+	}{
+		{
+			name: "transfer keeps the original buy date and cost",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "degiro", "10-01-2022", "BUY", 10, -1000),
+				stockTrade(2, "degiro", "01-06-2023", "TRANSFER_OUT", 10, 0),
+				stockTrade(3, "ibkr", "03-06-2023", "TRANSFER_IN", 10, 0),
+				stockTrade(4, "ibkr", "01-02-2024", "SELL", 10, 1500),
+			},
+			wantBuyDates: []string{"10-01-2022"},
+			wantDeltas:   []float64{500},
+		},
+		{
+			name: "partial transfer splits the lot",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "degiro", "10-01-2022", "BUY", 10, -1000),
+				stockTrade(2, "degiro", "01-06-2023", "TRANSFER_OUT", 4, 0),
+				stockTrade(3, "ibkr", "03-06-2023", "TRANSFER_IN", 4, 0),
+				stockTrade(4, "ibkr", "01-02-2024", "SELL", 10, 2000),
+			},
+			wantBuyDates: []string{"10-01-2022", "10-01-2022"},
+			wantDeltas:   []float64{600, 400},
+		},
+		{
+			name: "transfer in with carried cost opens a lot",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "manual", "03-06-2023", "TRANSFER_IN", 5, -400),
+				stockTrade(2, "ibkr", "01-02-2024", "SELL", 5, 600),
+			},
+			wantBuyDates: []string{"03-06-2023"},
+			wantDeltas:   []float64{200},
+		},
+		{
+			name: "transfer in without cost basis is reported",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "ibkr", "03-06-2023", "TRANSFER_IN", 5, 0),
+			},
+			wantErrors: []string{"TRANSFER_IN"},
+		},
+		{
+			name: "transfer in covered by the sending broker's open lots",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "degiro", "10-01-2022", "BUY", 5, -500),
+				stockTrade(2, "ibkr", "03-06-2023", "TRANSFER_IN", 5, 0),
+				stockTrade(3, "ibkr", "01-02-2024", "SELL", 5, 600),
+			},
+			wantBuyDates: []string{"10-01-2022"},
+			wantDeltas:   []float64{100},
+		},
+		{
+			name: "transfer out beyond the open lots is reported",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "degiro", "10-01-2022", "BUY", 5, -500),
+				stockTrade(2, "degiro", "01-06-2023", "TRANSFER_OUT", 8, 0),
+			},
+			wantErrors: []string{"TRANSFER_OUT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sales, _, matchingErrors := calculateSalesAndYearlyHoldings(filterAndSortStockTransactions(tt.transactions))
+			if len(sales) != len(tt.wantDeltas) {
+				t.Fatalf("got %d sale details, want %d: %+v", len(sales), len(tt.wantDeltas), sales)
+			}
+			for i, sale := range sales {
+				if sale.BuyDate != tt.wantBuyDates[i] || sale.Delta != tt.wantDeltas[i] {
+					t.Errorf("sale %d: BuyDate %s Delta %v, want %s %v", i, sale.BuyDate, sale.Delta, tt.wantBuyDates[i], tt.wantDeltas[i])
+				}
+			}
+			if len(matchingErrors) != len(tt.wantErrors) {
+				t.Fatalf("got %d matching errors, want %d: %+v", len(matchingErrors), len(tt.wantErrors), matchingErrors)
+			}
+			for i, matchingError := range matchingErrors {
+				if matchingError.Event != tt.wantErrors[i] {
+					t.Errorf("matching error %d: Event %s, want %s", i, matchingError.Event, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateSalesAndYearlyHoldingsSplits(t *testing.T) {
+	split := func(id int64, date string, shareDelta float64) models.ProcessedTransaction {
+		tx := stockTrade(id, "revolut", date, "SPLIT", shareDelta, 0)
+		tx.OriginalQuantity = 0
+		return tx
+	}
+
+	tests := []struct {
+		name         string
+		transactions []models.ProcessedTransaction
+		wantQuantity float64
+		wantCostEUR  float64
+		wantErrors   int
+	}{
+		{
+			name: "forward split keeps the cost",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "revolut", "10-01-2022", "BUY", 2.5, -500),
+				split(2, "01-06-2022", 7.5),
+			},
+			wantQuantity: 10,
+			wantCostEUR:  -500,
+		},
+		{
+			name: "reverse split after a partial sale",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "revolut", "10-01-2022", "BUY", 10, -1000),
+				stockTrade(2, "revolut", "01-03-2022", "SELL", 4, 500),
+				split(3, "01-06-2022", -3),
+			},
+			wantQuantity: 3,
+			wantCostEUR:  -600,
+		},
+		{
+			name: "split without open lots is reported",
+			transactions: []models.ProcessedTransaction{
+				split(1, "01-06-2022", 5),
+			},
+			wantErrors: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, holdingsByYear, matchingErrors := calculateSalesAndYearlyHoldings(filterAndSortStockTransactions(tt.transactions))
+			if len(matchingErrors) != tt.wantErrors {
+				t.Fatalf("got %d matching errors, want %d: %+v", len(matchingErrors), tt.wantErrors, matchingErrors)
+			}
+			quantity, costEUR := 0.0, 0.0
+			for _, lot := range holdingsByYear["2022"] {
+				quantity += lot.Quantity
+				costEUR += lot.BuyAmountEUR
+			}
+			if math.Abs(quantity-tt.wantQuantity) > quantityEpsilon || costEUR != tt.wantCostEUR {
+				t.Errorf("holdings: quantity %v cost %v, want %v %v", quantity, costEUR, tt.wantQuantity, tt.wantCostEUR)
+			}
+		})
+	}
+}
+
+func TestCalculateSalesAndYearlyHoldingsOversells(t *testing.T) {
+	tests := []struct {
+		name          string
+		transactions  []models.ProcessedTransaction
+		wantSales     []models.SaleDetail
+		wantErrorQty  []float64
+		wantOpenShort float64
+	}{
+		{
+			name: "oversell at a broker without short selling is only reported",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "degiro", "10-01-2024", "BUY", 5, -500),
+				stockTrade(2, "degiro", "01-02-2024", "SELL", 8, 960),
+				stockTrade(3, "degiro", "01-03-2024", "BUY", 3, -330),
+			},
+			wantSales:    []models.SaleDetail{{SaleDate: "01-02-2024", BuyDate: "10-01-2024", Quantity: 5, Delta: 100}},
+			wantErrorQty: []float64{3},
+		},
+		{
+			name: "short sale is covered by a later buy",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "ibkr", "01-02-2024", "SELL", 10, 1000),
+				stockTrade(2, "ibkr", "01-03-2024", "BUY", 4, -360),
+				stockTrade(3, "ibkr", "01-04-2024", "BUY", 6, -660),
+			},
+			wantSales: []models.SaleDetail{
+				{SaleDate: "01-03-2024", BuyDate: "01-02-2024", Quantity: 4, Delta: 40, Short: true},
+				{SaleDate: "01-04-2024", BuyDate: "01-02-2024", Quantity: 6, Delta: -60, Short: true},
+			},
+			wantErrorQty: []float64{10},
+		},
+		{
+			name: "oversell beyond a long lot opens a short for the rest",
+			transactions: []models.ProcessedTransaction{
+				stockTrade(1, "ibkr", "10-01-2024", "BUY", 5, -500),
+				stockTrade(2, "ibkr", "01-02-2024", "SELL", 8, 960),
+			},
+			wantSales:     []models.SaleDetail{{SaleDate: "01-02-2024", BuyDate: "10-01-2024", Quantity: 5, Delta: 100}},
+			wantErrorQty:  []float64{3},
+			wantOpenShort: -3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sales, holdingsByYear, matchingErrors := calculateSalesAndYearlyHoldings(filterAndSortStockTransactions(tt.transactions))
+			if len(sales) != len(tt.wantSales) {
+				t.Fatalf("got %d sale details, want %d: %+v", len(sales), len(tt.wantSales), sales)
+			}
+			for i, want := range tt.wantSales {
+				got := sales[i]
+				if got.SaleDate != want.SaleDate || got.BuyDate != want.BuyDate || got.Quantity != want.Quantity || got.Delta != want.Delta || got.Short != want.Short {
+					t.Errorf("sale %d: %s/%s qty %v delta %v short %v, want %s/%s qty %v delta %v short %v", i,
+						got.SaleDate, got.BuyDate, got.Quantity, got.Delta, got.Short, want.SaleDate, want.BuyDate, want.Quantity, want.Delta, want.Short)
+				}
+			}
+			if len(matchingErrors) != len(tt.wantErrorQty) {
+				t.Fatalf("got %d matching errors, want %d: %+v", len(matchingErrors), len(tt.wantErrorQty), matchingErrors)
+			}
+			for i, matchingError := range matchingErrors {
+				if matchingError.Event != "SELL" || matchingError.UnmatchedQuantity != tt.wantErrorQty[i] {
+					t.Errorf("matching error %d: %s of %v, want SELL of %v", i, matchingError.Event, matchingError.UnmatchedQuantity, tt.wantErrorQty[i])
+				}
+			}
+			openShort := 0.0
+			for _, lot := range holdingsByYear["2024"] {
+				if lot.Quantity < 0 {
+					openShort += lot.Quantity
+				}
+			}
+			if openShort != tt.wantOpenShort {
+				t.Errorf("open short quantity = %v, want %v", openShort, tt.wantOpenShort)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package processors
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// AutonomousTaxRate is the special rate on the yearly balance of capital gains on securities, and on crypto gains
+// from assets held for less than CryptoExemptHoldingDays.
+const AutonomousTaxRate = 0.28
+
+// taxLossProcessorImpl implements the TaxLossProcessor interface.
+type taxLossProcessorImpl struct{}
+
+// NewTaxLossProcessor creates a new instance of TaxLossProcessor.
+func NewTaxLossProcessor() TaxLossProcessor {
+	return &taxLossProcessorImpl{}
+}
+
+// Process lists the open long lots of holdings valued below cost in valuation. For each one, it sells the lots of
+// the same ISIN FIFO up to and including it, and compares the tax on the year's realised gain from the transactions
+// up to asOf with and without that sale. Lots of positions without a price are not assessed.
+func (p *taxLossProcessorImpl) Process(transactions []models.ProcessedTransaction, holdings []models.PurchaseLot, valuation *models.HoldingsValuation, asOf time.Time) models.HarvestReport {
+	report := models.HarvestReport{
+		Date:              asOf.Format("02-01-2006"),
+		Year:              strconv.Itoa(asOf.Year()),
+		TaxRate:           AutonomousTaxRate,
+		RealisedGainEUR:   YearRealisedGainEUR(transactionsUpTo(transactions, asOf), asOf),
+		Candidates:        []models.HarvestCandidate{},
+		UnpricedPositions: []string{},
+	}
+	report.EstimatedTaxEUR = capitalGainsTax(report.RealisedGainEUR)
+
+	positions := make(map[string]models.PositionValuation)
+	var order []string
+	for _, pos := range valuation.Positions {
+		key := pos.ISIN
+		if key == "" {
+			key = pos.ProductName
+		}
+		positions[key] = pos
+		order = append(order, key)
+	}
+	// Holdings list each ISIN's lots in the order sales are matched against them.
+	lotsByKey := make(map[string][]models.PurchaseLot)
+	for _, lot := range holdings {
+		if lot.Quantity <= 0 {
+			continue
+		}
+		key := lot.ISIN
+		if key == "" {
+			key = lot.ProductName
+		}
+		lotsByKey[key] = append(lotsByKey[key], lot)
+	}
+
+	for _, key := range order {
+		pos := positions[key]
+		lots := lotsByKey[key]
+		if len(lots) == 0 || pos.Quantity <= 0 {
+			continue
+		}
+		if pos.PriceMissing {
+			report.UnpricedPositions = append(report.UnpricedPositions, key)
+			continue
+		}
+		valuePerShare := pos.MarketValueEUR / pos.Quantity
+
+		var fifoQuantity, fifoResult float64
+		for _, lot := range lots {
+			costEUR := -lot.BuyAmountEUR
+			marketValueEUR := lot.Quantity * valuePerShare
+			fifoQuantity += lot.Quantity
+			fifoResult += marketValueEUR - costEUR
+			if marketValueEUR >= costEUR {
+				continue
+			}
+			result := utils.RoundFloat(fifoResult, 2)
+			report.Candidates = append(report.Candidates, models.HarvestCandidate{
+				Source:                lot.Source,
+				BuyDate:               lot.BuyDate,
+				ProductName:           lot.ProductName,
+				ISIN:                  lot.ISIN,
+				Quantity:              lot.Quantity,
+				CostEUR:               utils.RoundFloat(costEUR, 2),
+				MarketValueEUR:        utils.RoundFloat(marketValueEUR, 2),
+				UnrealisedLossEUR:     utils.RoundFloat(marketValueEUR-costEUR, 2),
+				HoldingDays:           int(asOf.Sub(utils.ParseDate(lot.BuyDate)).Hours() / 24),
+				FIFOQuantity:          fifoQuantity,
+				FIFOResultEUR:         result,
+				EstimatedTaxEffectEUR: utils.RoundFloat(capitalGainsTax(report.RealisedGainEUR+result)-report.EstimatedTaxEUR, 2),
+			})
+		}
+	}
+
+	sort.SliceStable(report.Candidates, func(i, j int) bool {
+		return report.Candidates[i].UnrealisedLossEUR < report.Candidates[j].UnrealisedLossEUR
+	})
+	return report
+}
+
+// YearRealisedGainEUR is the balance of the capital gains of asOf's year taxed together at AutonomousTaxRate: stock
+// sales, dated by the sale or, for short sales, by the cover, option positions by their close, and bond sales and
+// redemptions. Options are expired as of asOf, so a contract expiring later in the year is still open.
+func YearRealisedGainEUR(transactions []models.ProcessedTransaction, asOf time.Time) float64 {
+	year := asOf.Year()
+	inYear := func(date string) bool {
+		return utils.ParseDate(date).Year() == year
+	}
+	gain := 0.0
+	stockSales, _, _ := calculateSalesAndYearlyHoldings(filterAndSortStockTransactions(transactions))
+	for _, sale := range stockSales {
+		if inYear(sale.SaleDate) {
+			gain += sale.Delta
+		}
+	}
+	optionSales, _ := (&optionProcessorImpl{now: func() time.Time { return asOf }}).Process(transactions)
+	for _, sale := range optionSales {
+		if inYear(sale.CloseDate) {
+			gain += sale.Delta
+		}
+	}
+	for _, sale := range NewBondProcessor().Process(transactions).Sales {
+		if inYear(sale.SaleDate) {
+			gain += sale.Delta
+		}
+	}
+	return utils.RoundFloat(gain, 2)
+}
+
+// capitalGainsTax is the tax on a year's capital gains balance; a net loss pays none.
+func capitalGainsTax(balanceEUR float64) float64 {
+	return utils.RoundFloat(math.Max(balanceEUR, 0)*AutonomousTaxRate, 2)
+}
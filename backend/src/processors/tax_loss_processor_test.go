@@ -0,0 +1,88 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+func bondTrade(id int64, date, buySell string, nominal, amount float64) models.ProcessedTransaction {
+	return models.ProcessedTransaction{ID: id, Date: date, Source: "ibkr", ProductName: "BUND 2030", ISIN: "DE0001102507", TransactionType: "BOND",
+		BuySell: buySell, Quantity: nominal, OriginalQuantity: nominal, Amount: amount, AmountEUR: amount, Currency: "EUR", ExchangeRate: 1}
+}
+
+func TestYearRealisedGainEUR(t *testing.T) {
+	transactions := []models.ProcessedTransaction{
+		stockTrade(1, "degiro", "10-01-2023", "BUY", 10, -1000),
+		stockTrade(2, "degiro", "01-06-2023", "SELL", 5, 600),
+		stockTrade(3, "degiro", "01-02-2024", "SELL", 5, 470),
+		optionTrade("4", "degiro", "01-02-2024", "FLW P31.00 18DEC26", "BUY", 1, 1, -100),
+		optionTrade("5", "degiro", "01-03-2024", "FLW P31.00 18DEC26", "SELL", 1, 1.5, 150),
+		bondTrade(6, "01-12-2023", "BUY", 1000, -980),
+		bondTrade(7, "01-04-2024", "SELL", 1000, 1000),
+		stockTrade(8, "ibkr", "01-11-2024", "SELL", 2, 200),
+		stockTrade(9, "ibkr", "05-01-2025", "BUY", 2, -150),
+	}
+
+	tests := []struct {
+		asOf string
+		want float64
+	}{
+		{asOf: "31-12-2023", want: 100},
+		// Stock -30, option 50 and bond 20; the short sold in 2024 counts when it is covered in 2025.
+		{asOf: "31-12-2024", want: 40},
+		{asOf: "31-12-2025", want: 50},
+	}
+	for _, tt := range tests {
+		if got := YearRealisedGainEUR(transactions, utils.ParseDate(tt.asOf)); got != tt.want {
+			t.Errorf("YearRealisedGainEUR(%s) = %v, want %v", tt.asOf, got, tt.want)
+		}
+	}
+}
+
+func TestYearRealisedGainEUROptionExpiry(t *testing.T) {
+	transactions := []models.ProcessedTransaction{
+		optionTrade("1", "degiro", "01-02-2024", "FLW P31.00 15MAR24", "BUY", 1, 1, -100),
+	}
+
+	tests := []struct {
+		asOf string
+		want float64
+	}{
+		// Still open on asOf, whatever the wall clock says.
+		{asOf: "01-03-2024", want: 0},
+		{asOf: "31-12-2024", want: -100},
+	}
+	for _, tt := range tests {
+		if got := YearRealisedGainEUR(transactions, utils.ParseDate(tt.asOf)); got != tt.want {
+			t.Errorf("YearRealisedGainEUR(%s) = %v, want %v", tt.asOf, got, tt.want)
+		}
+	}
+}
+
+func TestTaxLossProcessor(t *testing.T) {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []models.ProcessedTransaction{
+		stockTrade(1, "degiro", "10-01-2023", "BUY", 10, -1000),
+		stockTrade(2, "degiro", "10-01-2024", "BUY", 10, -1500),
+		optionTrade("3", "degiro", "01-02-2024", "FLW P31.00 18DEC26", "SELL", 1, 3, 300),
+		optionTrade("4", "degiro", "01-03-2024", "FLW P31.00 18DEC26", "BUY", 1, 1, -100),
+	}
+	holdings := NewStockProcessor().HoldingsAt(transactions, asOf)
+	valuation := &models.HoldingsValuation{Positions: []models.PositionValuation{{ISIN: "NL0000000001", ProductName: "ACME", Quantity: 20, MarketValueEUR: 2400}}}
+
+	report := NewTaxLossProcessor().Process(transactions, holdings, valuation, asOf)
+	if report.RealisedGainEUR != 200 || report.EstimatedTaxEUR != 56 || report.TaxRate != AutonomousTaxRate {
+		t.Errorf("realised %v tax %v at %v, want the option gain 200 taxed 56 at %v", report.RealisedGainEUR, report.EstimatedTaxEUR, report.TaxRate, AutonomousTaxRate)
+	}
+	if len(report.Candidates) != 1 {
+		t.Fatalf("got %d candidates, want the 2024 lot: %+v", len(report.Candidates), report.Candidates)
+	}
+	// Selling the 2024 lot sells the 2023 lot first: +200 and -300.
+	candidate := report.Candidates[0]
+	if candidate.BuyDate != "10-01-2024" || candidate.UnrealisedLossEUR != -300 || candidate.FIFOQuantity != 20 || candidate.FIFOResultEUR != -100 || candidate.EstimatedTaxEffectEUR != -28 {
+		t.Errorf("unexpected candidate: %+v", candidate)
+	}
+}
@@ -24,26 +24,43 @@ func (p *TransactionProcessor) Process(txs []models.CanonicalTransaction) []mode
 		// --- Enrichment Stage ---
 
 		// 1. Enrich with Exchange Rate.
-		rate, err := GetExchangeRate(tx.Currency, tx.TransactionDate)
-		if err != nil {
+		// Crypto swap and fee legs are denominated in a crypto asset, which has no ECB rate;
+		// the crypto processor values them, so they keep a zero rate and EUR amount.
+		isCryptoLeg := tx.TransactionType == "CRYPTO" && (tx.TransactionSubType == "SWAP" || tx.TransactionSubType == "FEE")
+		if isCryptoLeg {
+			tx.ExchangeRate = 0
+		} else if rate, err := GetExchangeRate(tx.Currency, tx.TransactionDate); err != nil {
 			logger.L.Warn("Could not find exchange rate, defaulting to 1.0", "currency", tx.Currency, "date", tx.TransactionDate, "orderID", tx.OrderID, "error", err)
 			tx.ExchangeRate = 1.0
+			tx.RateFallback = true
 		} else {
 			tx.ExchangeRate = rate
 		}
 
 		// 2. Enrich with Amount in EUR.
 		// This now uses the pre-calculated, signed `Amount` from the canonical transaction.
-		if tx.ExchangeRate > 0 {
+		if isCryptoLeg {
+			tx.AmountEUR = 0
+		} else if tx.ExchangeRate > 0 {
 			tx.AmountEUR = tx.Amount / tx.ExchangeRate
 		} else {
 			tx.AmountEUR = tx.Amount // Fallback if exchange rate is somehow zero
 		}
 
-		// 3. Enrich with Country Code from ISIN.
-		tx.CountryCode = utils.GetCountryCodeString(tx.ISIN)
+		// 3. Enrich with Country Code from ISIN. Parsers for brokers without ISINs may have set it already.
+		if tx.ISIN != "" || tx.CountryCode == "" {
+			tx.CountryCode = utils.GetCountryCodeString(tx.ISIN)
+		}
+
+		// 4. Classify the instrument from the reference data, using the parser's hint where the ISIN is not listed.
+		tx.InstrumentClass = ClassifyInstrument(tx.TransactionType, tx.ISIN, tx.ProductName, tx.InstrumentClass)
+
+		// 5. Complete the option contract identity. Parsers without structured fields leave it to the product name.
+		if tx.TransactionType == "OPTION" {
+			enrichOptionContract(&tx)
+		}
 
-		// 4. Enrich with a unique Hash ID.
+		// 6. Enrich with a unique Hash ID.
 		tx.HashId = generateHash(tx)
 
 		// --- Final Mapping ---
@@ -53,8 +70,8 @@ func (p *TransactionProcessor) Process(txs []models.CanonicalTransaction) []mode
 			Source:             tx.Source,
 			ProductName:        tx.ProductName,
 			ISIN:               tx.ISIN,
-			Quantity:           int(tx.Quantity),
-			OriginalQuantity:   int(tx.Quantity),
+			Quantity:           tx.Quantity,
+			OriginalQuantity:   tx.Quantity,
 			Price:              tx.Price,
 			TransactionType:    tx.TransactionType,
 			TransactionSubType: tx.TransactionSubType,
@@ -63,18 +80,52 @@ func (p *TransactionProcessor) Process(txs []models.CanonicalTransaction) []mode
 			Amount:             tx.Amount, // This is now the correct signed amount from the parser
 			Currency:           tx.Currency,
 			Commission:         tx.Commission,
+			CommissionCurrency: tx.CommissionCurrency,
 			OrderID:            tx.OrderID,
 			ExchangeRate:       tx.ExchangeRate,
 			AmountEUR:          tx.AmountEUR, // This is the correctly converted EUR amount
+			RateFallback:       tx.RateFallback,
 			CountryCode:        tx.CountryCode,
+			InstrumentClass:    tx.InstrumentClass,
+			Underlying:         tx.Underlying,
+			Strike:             tx.Strike,
+			OptionRight:        tx.OptionRight,
+			Multiplier:         tx.Multiplier,
 			InputString:        tx.RawText,
 			HashId:             tx.HashId,
 		}
+		if !tx.Expiry.IsZero() {
+			processed.Expiry = tx.Expiry.Format("02-01-2006")
+		}
 		processedTxs = append(processedTxs, processed)
 	}
 	return processedTxs
 }
 
+// enrichOptionContract fills the option fields a parser left empty: the contract from a product name such as
+// "FLW P31.00 18MAR22", the right from the CALL/PUT sub type, and the standard multiplier of 100.
+func enrichOptionContract(tx *models.CanonicalTransaction) {
+	if tx.Underlying == "" {
+		if contract, ok := utils.ParseOptionProductName(tx.ProductName); ok {
+			tx.Underlying = contract.Underlying
+			tx.Strike = contract.Strike
+			tx.Expiry = contract.Expiry
+			if tx.OptionRight == "" {
+				tx.OptionRight = contract.Right
+			}
+		}
+	}
+	if tx.OptionRight == "" && (tx.TransactionSubType == "CALL" || tx.TransactionSubType == "PUT") {
+		tx.OptionRight = tx.TransactionSubType
+	}
+	if tx.TransactionSubType == "" {
+		tx.TransactionSubType = tx.OptionRight
+	}
+	if tx.Multiplier <= 0 {
+		tx.Multiplier = utils.DefaultOptionMultiplier
+	}
+}
+
 // generateHash creates a unique hash for the transaction based on key source data.
 func generateHash(tx models.CanonicalTransaction) string {
 	input := fmt.Sprintf(tx.RawText)
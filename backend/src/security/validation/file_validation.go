@@ -1,9 +1,12 @@
 package validation
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/username/taxfolio/backend/src/logger"
@@ -17,14 +20,25 @@ var AllowedClientContentTypes = map[string]bool{
 	"application/vnd.ms-excel": true, // Often used for CSV by older Excel
 	"text/plain":               true, // CSVs are often plain text
 	"application/octet-stream": true, // Fallback, but be more cautious
-	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": false, // .xlsx, explicitly disallow for CSV endpoint
+	XLSXContentType:            true, // .xlsx, accepted only after ValidateXLSXArchive
 }
 
+// XLSXContentType is the MIME type of Office Open XML spreadsheets.
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Limits applied to XLSX (zip) uploads before any entry is decompressed, to reject zip bombs and odd archives.
+const (
+	MaxXLSXEntries           = 500
+	MaxXLSXEntryBytes        = 50 * 1024 * 1024  // Per decompressed entry
+	MaxXLSXUncompressedBytes = 100 * 1024 * 1024 // All entries together
+	MaxXLSXCompressionRatio  = 100
+)
+
 // ValidateClientContentType checks the Content-Type header provided by the client.
 func ValidateClientContentType(contentType string) error {
 	if allowed, exists := AllowedClientContentTypes[strings.ToLower(contentType)]; !exists || !allowed {
 		logger.L.Warn("Disallowed client-declared Content-Type", "contentType", contentType)
-		return fmt.Errorf("client-declared file type '%s' is not allowed for CSV upload", contentType)
+		return fmt.Errorf("client-declared file type '%s' is not allowed for upload", contentType)
 	}
 	return nil
 }
@@ -51,6 +65,16 @@ func ValidateFileContentByMagicBytes(file io.ReadSeeker) (string, error) {
 	detectedContentType := http.DetectContentType(buffer[:n])
 	detectedContentType = strings.ToLower(strings.Split(detectedContentType, ";")[0]) // Normalize (e.g. "text/plain; charset=utf-8")
 
+	// Zip containers are only accepted when they look like a well-formed, reasonably sized spreadsheet.
+	if detectedContentType == "application/zip" {
+		if err := validateXLSXUpload(file); err != nil {
+			logger.L.Warn("Rejected zip upload", "error", err)
+			return detectedContentType, err
+		}
+		logger.L.Debug("File content type (magic bytes) validated as XLSX")
+		return XLSXContentType, nil
+	}
+
 	// For CSV, we are primarily concerned it's text-based and not something malicious like an executable.
 	// "text/plain" is a very common and acceptable detected type for CSV.
 	// "application/csv" might be detected by some systems.
@@ -71,3 +95,70 @@ func ValidateFileContentByMagicBytes(file io.ReadSeeker) (string, error) {
 	logger.L.Debug("File content type (magic bytes) validated", "detectedContentType", detectedContentType)
 	return detectedContentType, nil
 }
+
+// validateXLSXUpload runs ValidateXLSXArchive on an uploaded file and rewinds it for the parser.
+func validateXLSXUpload(file io.ReadSeeker) error {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to determine file size: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to reset file read pointer: %w", err)
+	}
+
+	readerAt, ok := file.(io.ReaderAt)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return fmt.Errorf("failed to read file for XLSX validation: %w", err)
+		}
+		readerAt = bytes.NewReader(data)
+	}
+	validationErr := ValidateXLSXArchive(readerAt, size)
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to reset file read pointer: %w", err)
+	}
+	return validationErr
+}
+
+// ValidateXLSXArchive checks a zip container against the XLSX limits using only the central directory,
+// so nothing is decompressed. It also requires the entries every spreadsheet has.
+func ValidateXLSXArchive(r io.ReaderAt, size int64) error {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%w: file is not a valid XLSX archive: %v", ErrValidationFailed, err)
+	}
+	if len(archive.File) > MaxXLSXEntries {
+		return fmt.Errorf("%w: XLSX archive has too many entries (%d, max %d)", ErrValidationFailed, len(archive.File), MaxXLSXEntries)
+	}
+
+	var total uint64
+	hasContentTypes, hasWorkbook := false, false
+	for _, f := range archive.File {
+		name := f.Name
+		if strings.HasPrefix(name, "/") || strings.Contains(name, "\\") || path.Clean(name) != name || strings.HasPrefix(name, "..") {
+			return fmt.Errorf("%w: XLSX archive contains an invalid entry name", ErrValidationFailed)
+		}
+		if f.UncompressedSize64 > MaxXLSXEntryBytes {
+			return fmt.Errorf("%w: XLSX entry '%s' is too large", ErrValidationFailed, name)
+		}
+		if f.CompressedSize64 > 0 && f.UncompressedSize64/f.CompressedSize64 > MaxXLSXCompressionRatio {
+			return fmt.Errorf("%w: XLSX entry '%s' has a suspicious compression ratio", ErrValidationFailed, name)
+		}
+		total += f.UncompressedSize64
+		if total > MaxXLSXUncompressedBytes {
+			return fmt.Errorf("%w: XLSX archive is too large when uncompressed", ErrValidationFailed)
+		}
+		switch name {
+		case "[Content_Types].xml":
+			hasContentTypes = true
+		case "xl/workbook.xml":
+			hasWorkbook = true
+		}
+	}
+	if !hasContentTypes || !hasWorkbook {
+		return fmt.Errorf("%w: zip archive is not an XLSX spreadsheet", ErrValidationFailed)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// allocationUnknownKey groups lots whose country, currency, class or broker is not known.
+const allocationUnknownKey = "UNKNOWN"
+
+type allocationServiceImpl struct {
+	uploadService UploadService
+	priceService  PriceService
+}
+
+func NewAllocationService(uploadService UploadService, priceService PriceService) AllocationService {
+	return &allocationServiceImpl{
+		uploadService: uploadService,
+		priceService:  priceService,
+	}
+}
+
+// allocationLot is an open lot reduced to the attributes it is grouped by.
+type allocationLot struct {
+	country, currency, assetClass, broker string
+	costEUR, marketValueEUR               float64
+	atCost                                bool // Valued at cost for lack of a price
+}
+
+// GetAllocation groups today's open stock, bond and crypto lots by country, currency, asset class and broker.
+// Bonds and crypto have no price source and are valued at cost; they are counted in LotsAtCost with unpriced stocks.
+func (s *allocationServiceImpl) GetAllocation(userID int64) (*models.AllocationReport, error) {
+	today := time.Now()
+	holdings, err := s.uploadService.GetStockHoldingsAt(userID, today)
+	if err != nil {
+		return nil, err
+	}
+	valuation, err := s.priceService.GetHoldingsValuation(userID, today)
+	if err != nil {
+		return nil, err
+	}
+	bondReport, err := s.uploadService.GetBondReport(userID)
+	if err != nil {
+		return nil, err
+	}
+	cryptoReport, err := s.uploadService.GetCryptoGainReport(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]models.PositionValuation, len(valuation.Positions))
+	for _, pos := range valuation.Positions {
+		key := pos.ISIN
+		if key == "" {
+			key = pos.ProductName
+		}
+		positions[key] = pos
+	}
+
+	var lots []allocationLot
+	for _, lot := range holdings {
+		key := lot.ISIN
+		if key == "" {
+			key = lot.ProductName
+		}
+		// Buy amounts are negative for long lots and positive (sale proceeds) for short ones.
+		costEUR := -lot.BuyAmountEUR
+		marketValueEUR := costEUR
+		atCost := true
+		// The position's market value is shared between its lots by quantity.
+		if pos, ok := positions[key]; ok && !pos.PriceMissing && pos.Quantity != 0 {
+			marketValueEUR = pos.MarketValueEUR * lot.Quantity / pos.Quantity
+			atCost = false
+		}
+		lots = append(lots, allocationLot{
+			country:        lot.CountryCode,
+			currency:       lot.BuyCurrency,
+			assetClass:     lot.InstrumentClass,
+			broker:         lot.Source,
+			costEUR:        costEUR,
+			marketValueEUR: marketValueEUR,
+			atCost:         atCost,
+		})
+	}
+	for _, lot := range bondReport.Holdings {
+		lots = append(lots, allocationLot{
+			country:        lot.CountryCode,
+			currency:       lot.Currency,
+			assetClass:     models.InstrumentClassBond,
+			broker:         lot.Source,
+			costEUR:        -lot.BuyAmountEUR,
+			marketValueEUR: -lot.BuyAmountEUR,
+			atCost:         true,
+		})
+	}
+	for _, lot := range cryptoReport.Holdings {
+		lots = append(lots, allocationLot{
+			currency:       lot.Asset,
+			assetClass:     models.InstrumentClassCrypto,
+			broker:         lot.Source,
+			costEUR:        lot.CostEUR,
+			marketValueEUR: lot.CostEUR,
+			atCost:         true,
+		})
+	}
+
+	report := &models.AllocationReport{
+		Date:          today.Format("02-01-2006"),
+		MissingPrices: valuation.MissingPrices,
+	}
+	for _, lot := range lots {
+		report.TotalCostEUR += lot.costEUR
+		report.TotalMarketValueEUR += lot.marketValueEUR
+		if lot.atCost {
+			report.LotsAtCost++
+		}
+	}
+	report.ByCountry = allocationBuckets(lots, report, func(l allocationLot) string { return l.country })
+	report.ByCurrency = allocationBuckets(lots, report, func(l allocationLot) string { return l.currency })
+	report.ByAssetClass = allocationBuckets(lots, report, func(l allocationLot) string { return l.assetClass })
+	report.ByBroker = allocationBuckets(lots, report, func(l allocationLot) string { return l.broker })
+	report.TotalCostEUR = utils.RoundFloat(report.TotalCostEUR, 2)
+	report.TotalMarketValueEUR = utils.RoundFloat(report.TotalMarketValueEUR, 2)
+	return report, nil
+}
+
+// allocationBuckets sums the lots per key, largest market value first, with percentages of the report's totals.
+func allocationBuckets(lots []allocationLot, report *models.AllocationReport, keyOf func(allocationLot) string) []models.AllocationBucket {
+	bucketsByKey := make(map[string]*models.AllocationBucket)
+	var order []string
+	for _, lot := range lots {
+		key := keyOf(lot)
+		if key == "" {
+			key = allocationUnknownKey
+		}
+		bucket, ok := bucketsByKey[key]
+		if !ok {
+			bucket = &models.AllocationBucket{Key: key}
+			bucketsByKey[key] = bucket
+			order = append(order, key)
+		}
+		bucket.CostEUR += lot.costEUR
+		bucket.MarketValueEUR += lot.marketValueEUR
+		bucket.Lots++
+		if lot.atCost {
+			bucket.LotsAtCost++
+		}
+	}
+
+	buckets := make([]models.AllocationBucket, 0, len(order))
+	for _, key := range order {
+		bucket := bucketsByKey[key]
+		if report.TotalCostEUR != 0 {
+			bucket.CostPct = utils.RoundFloat(bucket.CostEUR/report.TotalCostEUR*100, 2)
+		}
+		if report.TotalMarketValueEUR != 0 {
+			bucket.MarketValuePct = utils.RoundFloat(bucket.MarketValueEUR/report.TotalMarketValueEUR*100, 2)
+		}
+		bucket.CostEUR = utils.RoundFloat(bucket.CostEUR, 2)
+		bucket.MarketValueEUR = utils.RoundFloat(bucket.MarketValueEUR, 2)
+		buckets = append(buckets, *bucket)
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].MarketValueEUR > buckets[j].MarketValueEUR
+	})
+	return buckets
+}
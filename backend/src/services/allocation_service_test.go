@@ -0,0 +1,118 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+)
+
+// allocationUploadService returns fixed stock, bond and crypto holdings.
+type allocationUploadService struct {
+	holdingsUploadService
+	bonds  []models.BondHolding
+	crypto []models.CryptoLot
+}
+
+func (s allocationUploadService) GetBondReport(userID int64) (*models.BondReport, error) {
+	return &models.BondReport{Holdings: s.bonds}, nil
+}
+
+func (s allocationUploadService) GetCryptoGainReport(userID int64) (*models.CryptoGainReport, error) {
+	return &models.CryptoGainReport{Holdings: s.crypto}, nil
+}
+
+// valuationPriceService returns a fixed valuation.
+type valuationPriceService struct {
+	PriceService
+	valuation models.HoldingsValuation
+}
+
+func (s valuationPriceService) GetHoldingsValuation(userID int64, date time.Time) (*models.HoldingsValuation, error) {
+	return &s.valuation, nil
+}
+
+func TestGetAllocation(t *testing.T) {
+	uploadService := allocationUploadService{
+		holdingsUploadService: holdingsUploadService{lots: []models.PurchaseLot{
+			// Two lots of one priced position: its 1200 market value is shared 1:3 by quantity.
+			{ISIN: "IE00B4L5Y983", Quantity: 5, BuyAmountEUR: -400, BuyCurrency: "EUR", CountryCode: "IE", InstrumentClass: models.InstrumentClassETF, Source: "degiro"},
+			{ISIN: "IE00B4L5Y983", Quantity: 15, BuyAmountEUR: -1400, BuyCurrency: "EUR", CountryCode: "IE", InstrumentClass: models.InstrumentClassETF, Source: "ibkr"},
+			// No price stored: valued at cost.
+			{ISIN: "US0378331005", Quantity: 2, BuyAmountEUR: -300, BuyCurrency: "USD", CountryCode: "US", InstrumentClass: models.InstrumentClassShare, Source: "ibkr"},
+			// No ISIN country, class or currency.
+			{ProductName: "MYSTERY", Quantity: 1, BuyAmountEUR: -100, Source: "degiro"},
+		}},
+		bonds:  []models.BondHolding{{Source: "ibkr", ISIN: "DE0001102507", Quantity: 1000, BuyAmountEUR: -700, Currency: "EUR", CountryCode: "DE"}},
+		crypto: []models.CryptoLot{{Source: "kraken", Asset: "BTC", Quantity: 0.01, CostEUR: 500}},
+	}
+	priceService := valuationPriceService{valuation: models.HoldingsValuation{
+		Positions: []models.PositionValuation{
+			{ISIN: "IE00B4L5Y983", Quantity: 20, CostEUR: 1800, MarketValueEUR: 2000},
+			{ISIN: "US0378331005", Quantity: 2, CostEUR: 300, MarketValueEUR: 300, PriceMissing: true},
+			{ProductName: "MYSTERY", Quantity: 1, CostEUR: 100, MarketValueEUR: 150},
+		},
+		MissingPrices: 1,
+	}}
+
+	report, err := NewAllocationService(uploadService, priceService).GetAllocation(1)
+	if err != nil {
+		t.Fatalf("GetAllocation: %v", err)
+	}
+
+	if report.TotalCostEUR != 3400 || report.TotalMarketValueEUR != 3650 {
+		t.Errorf("totals: cost %v value %v, want 3400 3650", report.TotalCostEUR, report.TotalMarketValueEUR)
+	}
+	if report.MissingPrices != 1 || report.LotsAtCost != 3 {
+		t.Errorf("missing prices %d lots at cost %d, want 1 and 3 (the unpriced stock, the bond and the crypto lot)", report.MissingPrices, report.LotsAtCost)
+	}
+
+	tests := []struct {
+		name    string
+		buckets []models.AllocationBucket
+		want    []models.AllocationBucket
+	}{
+		{
+			name:    "by country",
+			buckets: report.ByCountry,
+			want: []models.AllocationBucket{
+				{Key: "IE", CostEUR: 1800, MarketValueEUR: 2000, CostPct: 52.94, MarketValuePct: 54.79, Lots: 2},
+				{Key: "DE", CostEUR: 700, MarketValueEUR: 700, CostPct: 20.59, MarketValuePct: 19.18, Lots: 1, LotsAtCost: 1},
+				{Key: "UNKNOWN", CostEUR: 600, MarketValueEUR: 650, CostPct: 17.65, MarketValuePct: 17.81, Lots: 2, LotsAtCost: 1},
+				{Key: "US", CostEUR: 300, MarketValueEUR: 300, CostPct: 8.82, MarketValuePct: 8.22, Lots: 1, LotsAtCost: 1},
+			},
+		},
+		{
+			name:    "by broker",
+			buckets: report.ByBroker,
+			want: []models.AllocationBucket{
+				{Key: "ibkr", CostEUR: 2400, MarketValueEUR: 2500, CostPct: 70.59, MarketValuePct: 68.49, Lots: 3, LotsAtCost: 2},
+				{Key: "degiro", CostEUR: 500, MarketValueEUR: 650, CostPct: 14.71, MarketValuePct: 17.81, Lots: 2},
+				{Key: "kraken", CostEUR: 500, MarketValueEUR: 500, CostPct: 14.71, MarketValuePct: 13.7, Lots: 1, LotsAtCost: 1},
+			},
+		},
+		{
+			name:    "by asset class",
+			buckets: report.ByAssetClass,
+			want: []models.AllocationBucket{
+				{Key: models.InstrumentClassETF, CostEUR: 1800, MarketValueEUR: 2000, CostPct: 52.94, MarketValuePct: 54.79, Lots: 2},
+				{Key: models.InstrumentClassBond, CostEUR: 700, MarketValueEUR: 700, CostPct: 20.59, MarketValuePct: 19.18, Lots: 1, LotsAtCost: 1},
+				{Key: models.InstrumentClassCrypto, CostEUR: 500, MarketValueEUR: 500, CostPct: 14.71, MarketValuePct: 13.7, Lots: 1, LotsAtCost: 1},
+				{Key: models.InstrumentClassShare, CostEUR: 300, MarketValueEUR: 300, CostPct: 8.82, MarketValuePct: 8.22, Lots: 1, LotsAtCost: 1},
+				{Key: "UNKNOWN", CostEUR: 100, MarketValueEUR: 150, CostPct: 2.94, MarketValuePct: 4.11, Lots: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.buckets) != len(tt.want) {
+				t.Fatalf("got %d buckets, want %d: %+v", len(tt.buckets), len(tt.want), tt.buckets)
+			}
+			for i, want := range tt.want {
+				if tt.buckets[i] != want {
+					t.Errorf("bucket %d = %+v, want %+v", i, tt.buckets[i], want)
+				}
+			}
+		})
+	}
+}
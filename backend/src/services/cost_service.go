@@ -0,0 +1,37 @@
+package services
+
+import (
+	"time"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/processors"
+)
+
+type costServiceImpl struct {
+	costProcessor      processors.CostProcessor
+	performanceService PerformanceService
+}
+
+func NewCostService(costProcessor processors.CostProcessor, performanceService PerformanceService) CostService {
+	return &costServiceImpl{
+		costProcessor:      costProcessor,
+		performanceService: performanceService,
+	}
+}
+
+// GetCostReport totals the user's costs, comparing them with the traded volume and with the average daily
+// portfolio value from the performance replay.
+func (s *costServiceImpl) GetCostReport(userID int64) (*models.CostReport, error) {
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	performance, err := s.performanceService.GetPerformance(userID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	report := s.costProcessor.Process(userTransactions, performance.Series)
+	logger.L.Info("Computed cost report", "userID", userID, "items", len(report.Items), "totalEUR", report.Total.TotalEUR)
+	return &report, nil
+}
@@ -4,8 +4,10 @@ package services
 import (
 	"errors"
 	"io"
+	"time"
 
 	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers"
 )
 
 // UploadResult is primarily for the result of a single ProcessUpload call.
@@ -17,6 +19,7 @@ type UploadResult struct {
 	OptionHoldings           []models.OptionHolding          `json:"OptionHoldings"`
 	CashMovements            []models.CashMovement           `json:"CashMovements"`
 	DividendTransactionsList []models.ProcessedTransaction   `json:"DividendTransactionsList"`
+	LotMatchingErrors        []models.LotMatchingError       `json:"LotMatchingErrors"`
 }
 
 // Define common service errors
@@ -27,13 +30,83 @@ var (
 
 // UploadService defines the interface for the core upload processing logic.
 type UploadService interface {
-	ProcessUpload(fileReader io.Reader, userID int64, source string) (*UploadResult, error)
+	ProcessUpload(fileReader io.Reader, userID int64, source string, opts parsers.Options) (*UploadResult, error)
 	GetLatestUploadResult(userID int64) (*UploadResult, error)
 	GetDividendTaxSummary(userID int64) (models.DividendTaxResult, error)
 	GetDividendTransactions(userID int64) ([]models.ProcessedTransaction, error)
+	GetDividendAnalytics(userID int64, asOf time.Time) (*models.DividendAnalytics, error)
+	GetInterestTaxSummary(userID int64) (models.InterestTaxResult, error)
 	GetStockHoldings(userID int64) ([]models.PurchaseLot, error)
+	GetStockHoldingsAt(userID int64, asOf time.Time) ([]models.PurchaseLot, error)
 	GetOptionHoldings(userID int64) ([]models.OptionHolding, error)
+	GetOptionHoldingsAt(userID int64, asOf time.Time) ([]models.OptionHolding, error)
 	GetStockSaleDetails(userID int64) ([]models.SaleDetail, error)
 	GetOptionSaleDetails(userID int64) ([]models.OptionSaleDetail, error)
+	GetStockSalesByClass(userID int64) (map[string]map[string]models.InstrumentClassSaleSummary, error)
+	GetLotMatchingErrors(userID int64) ([]models.LotMatchingError, error)
+	GetFXGainReport(userID int64) (*models.FXGainReport, error)
+	GetCryptoGainReport(userID int64) (*models.CryptoGainReport, error)
+	GetBondReport(userID int64) (*models.BondReport, error)
+	GetBondIncomeSummary(userID int64) (map[string]models.BondYearSummary, error)
+	GetCashLedger(userID int64) (*models.CashLedgerReport, error)
+	GetFallbackRateTransactions(userID int64) ([]models.ProcessedTransaction, error)
+	ReenrichFallbackRates() (int, error)
+	BackfillInstrumentClasses() (int, error)
 	InvalidateUserCache(userID int64)
 }
+
+// ReconciliationService compares broker-reported open positions with the computed holdings.
+type ReconciliationService interface {
+	StorePositions(fileReader io.Reader, userID int64, source string) (int, error)
+	Reconcile(userID int64) (*models.ReconciliationReport, error)
+}
+
+// PriceService stores closing prices and values the open holdings with them.
+type PriceService interface {
+	ImportPrices(fileReader io.Reader, userID int64, isin, currency string) (int, error)
+	SyncPrices(userID int64) (int, error)
+	GetHoldingsValuation(userID int64, date time.Time) (*models.HoldingsValuation, error)
+	ValueHoldings(userID int64, holdings []models.PurchaseLot, date time.Time) (*models.HoldingsValuation, error)
+}
+
+// PerformanceService computes the portfolio's returns over a period.
+type PerformanceService interface {
+	GetPerformance(userID int64, from, to time.Time) (*models.PerformanceReport, error)
+	GetBenchmarkComparison(userID int64, benchmarkISIN string, from, to time.Time) (*models.BenchmarkComparison, error)
+}
+
+// CostService analyses the fees and commissions paid.
+type CostService interface {
+	GetCostReport(userID int64) (*models.CostReport, error)
+}
+
+// SimulationService evaluates hypothetical transactions without storing them.
+type SimulationService interface {
+	SimulateSale(userID int64, input SaleSimulationInput) (*models.SaleSimulation, error)
+}
+
+// TaxLossService finds open positions whose losses could offset the year's realised gains.
+type TaxLossService interface {
+	GetHarvestReport(userID int64) (*models.HarvestReport, error)
+}
+
+// AllocationService breaks the open positions down by country, currency, asset class and broker.
+type AllocationService interface {
+	GetAllocation(userID int64) (*models.AllocationReport, error)
+}
+
+// TransactionService manages individual transactions outside the file upload path.
+type TransactionService interface {
+	CreateManualTransaction(userID int64, input ManualTransactionInput) (*models.ProcessedTransaction, error)
+	UpdateManualTransaction(userID, transactionID int64, input ManualTransactionInput) (*models.ProcessedTransaction, error)
+	DeleteTransaction(userID, transactionID int64) error
+}
+
+// ParserProfileService stores the user-defined column mappings used by the "custom" upload source.
+type ParserProfileService interface {
+	ListProfiles(userID int64) ([]models.ParserProfile, error)
+	GetProfile(userID, profileID int64) (*models.ParserProfile, error)
+	CreateProfile(userID int64, profile models.ParserProfile) (*models.ParserProfile, error)
+	UpdateProfile(userID, profileID int64, profile models.ParserProfile) (*models.ParserProfile, error)
+	DeleteProfile(userID, profileID int64) error
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers/custom"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+// MaxParserProfilesPerUser caps how many custom mappings a user can store.
+const MaxParserProfilesPerUser = 50
+
+var (
+	ErrProfileNotFound  = errors.New("parser profile not found")
+	ErrDuplicateProfile = errors.New("a parser profile with this name already exists")
+	ErrTooManyProfiles  = errors.New("maximum number of parser profiles reached")
+)
+
+type parserProfileServiceImpl struct{}
+
+func NewParserProfileService() ParserProfileService {
+	return &parserProfileServiceImpl{}
+}
+
+func (s *parserProfileServiceImpl) ListProfiles(userID int64) ([]models.ParserProfile, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, definition, created_at, updated_at
+		FROM parser_profiles WHERE user_id = ? ORDER BY name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying parser profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []models.ParserProfile
+	for rows.Next() {
+		profile, err := scanParserProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating parser profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+func (s *parserProfileServiceImpl) GetProfile(userID, profileID int64) (*models.ParserProfile, error) {
+	row := database.DB.QueryRow(`
+		SELECT id, definition, created_at, updated_at
+		FROM parser_profiles WHERE id = ? AND user_id = ?`, profileID, userID)
+	profile, err := scanParserProfile(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *parserProfileServiceImpl) CreateProfile(userID int64, profile models.ParserProfile) (*models.ParserProfile, error) {
+	definition, err := prepareParserProfile(&profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM parser_profiles WHERE user_id = ?", userID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("error counting parser profiles: %w", err)
+	}
+	if count >= MaxParserProfilesPerUser {
+		return nil, ErrTooManyProfiles
+	}
+
+	result, err := database.DB.Exec("INSERT INTO parser_profiles (user_id, name, definition) VALUES (?, ?, ?)", userID, profile.Name, definition)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrDuplicateProfile
+		}
+		return nil, fmt.Errorf("error inserting parser profile: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error reading id of parser profile: %w", err)
+	}
+	logger.L.Info("Parser profile created", "userID", userID, "profileID", id, "name", profile.Name)
+	return s.GetProfile(userID, id)
+}
+
+func (s *parserProfileServiceImpl) UpdateProfile(userID, profileID int64, profile models.ParserProfile) (*models.ParserProfile, error) {
+	definition, err := prepareParserProfile(&profile)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE parser_profiles SET name = ?, definition = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ?`, profile.Name, definition, profileID, userID)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrDuplicateProfile
+		}
+		return nil, fmt.Errorf("error updating parser profile %d: %w", profileID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error checking update of parser profile %d: %w", profileID, err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrProfileNotFound
+	}
+	logger.L.Info("Parser profile updated", "userID", userID, "profileID", profileID)
+	return s.GetProfile(userID, profileID)
+}
+
+// DeleteProfile removes a stored mapping. Transactions already imported with it are kept.
+func (s *parserProfileServiceImpl) DeleteProfile(userID, profileID int64) error {
+	result, err := database.DB.Exec("DELETE FROM parser_profiles WHERE id = ? AND user_id = ?", profileID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting parser profile %d: %w", profileID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking deletion of parser profile %d: %w", profileID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrProfileNotFound
+	}
+	logger.L.Info("Parser profile deleted", "userID", userID, "profileID", profileID)
+	return nil
+}
+
+// prepareParserProfile normalises and validates a profile and returns its JSON definition for storage.
+func prepareParserProfile(profile *models.ParserProfile) (string, error) {
+	profile.ID = 0
+	profile.CreatedAt, profile.UpdatedAt = "", ""
+	profile.Name = strings.TrimSpace(profile.Name)
+	profile.DefaultCurrency = strings.ToUpper(strings.TrimSpace(profile.DefaultCurrency))
+	if err := validation.CheckXSSPatterns(profile.Name, "Profile name", "parser_profile"); err != nil {
+		return "", err
+	}
+	if err := custom.ValidateProfile(*profile); err != nil {
+		return "", err
+	}
+	definition, err := json.Marshal(profile)
+	if err != nil {
+		return "", fmt.Errorf("error encoding parser profile: %w", err)
+	}
+	return string(definition), nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanParserProfile(row rowScanner) (*models.ParserProfile, error) {
+	var (
+		id                   int64
+		definition           string
+		createdAt, updatedAt sql.NullString
+	)
+	if err := row.Scan(&id, &definition, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error scanning parser profile: %w", err)
+	}
+	var profile models.ParserProfile
+	if err := json.Unmarshal([]byte(definition), &profile); err != nil {
+		return nil, fmt.Errorf("error decoding parser profile %d: %w", id, err)
+	}
+	profile.ID = id
+	profile.CreatedAt = createdAt.String
+	profile.UpdatedAt = updatedAt.String
+	return &profile, nil
+}
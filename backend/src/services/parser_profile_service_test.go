@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+func testParserProfile(name string) models.ParserProfile {
+	return models.ParserProfile{
+		Name:            "  " + name + " ",
+		HasHeader:       true,
+		DateFormat:      "DD-MM-YYYY",
+		DefaultCurrency: "eur",
+		Columns:         map[string]string{"date": "Date", "type": "Type", "amount": "Amount"},
+		Patterns:        map[string]string{"DIVIDEND": "dividend"},
+	}
+}
+
+func TestParserProfileService(t *testing.T) {
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+	service := NewParserProfileService()
+	const userID, otherUserID int64 = 1, 2
+
+	created, err := service.CreateProfile(userID, testParserProfile("Broker 0"))
+	if err != nil {
+		t.Fatalf("CreateProfile: %v", err)
+	}
+	if created.ID == 0 || created.Name != "Broker 0" || created.DefaultCurrency != "EUR" || created.CreatedAt == "" {
+		t.Errorf("created profile not normalised: %+v", created)
+	}
+
+	tests := []struct {
+		name    string
+		userID  int64
+		profile models.ParserProfile
+		wantErr error
+	}{
+		{name: "duplicate name", userID: userID, profile: testParserProfile("Broker 0"), wantErr: ErrDuplicateProfile},
+		{name: "same name for another user", userID: otherUserID, profile: testParserProfile("Broker 0")},
+		{name: "invalid profile", userID: userID, profile: models.ParserProfile{Name: "Empty"}, wantErr: validation.ErrValidationFailed},
+		{name: "script in name", userID: userID, profile: testParserProfile("<script>alert(1)</script>"), wantErr: validation.ErrValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateProfile(tt.userID, tt.profile)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("cap per user", func(t *testing.T) {
+		for i := 1; i < MaxParserProfilesPerUser; i++ {
+			if _, err := service.CreateProfile(userID, testParserProfile(fmt.Sprintf("Broker %d", i))); err != nil {
+				t.Fatalf("CreateProfile %d: %v", i, err)
+			}
+		}
+		if _, err := service.CreateProfile(userID, testParserProfile("One too many")); !errors.Is(err, ErrTooManyProfiles) {
+			t.Fatalf("got %v, want ErrTooManyProfiles", err)
+		}
+		if _, err := service.CreateProfile(otherUserID, testParserProfile("Another")); err != nil {
+			t.Fatalf("cap must be per user: %v", err)
+		}
+		profiles, err := service.ListProfiles(userID)
+		if err != nil || len(profiles) != MaxParserProfilesPerUser {
+			t.Fatalf("ListProfiles: %d profiles, err %v", len(profiles), err)
+		}
+	})
+
+	t.Run("update and delete are scoped to the owner", func(t *testing.T) {
+		if _, err := service.UpdateProfile(otherUserID, created.ID, testParserProfile("Stolen")); !errors.Is(err, ErrProfileNotFound) {
+			t.Fatalf("update by another user: got %v, want ErrProfileNotFound", err)
+		}
+		updated, err := service.UpdateProfile(userID, created.ID, testParserProfile("Renamed"))
+		if err != nil || updated.Name != "Renamed" {
+			t.Fatalf("UpdateProfile: %+v, %v", updated, err)
+		}
+		if err := service.DeleteProfile(otherUserID, created.ID); !errors.Is(err, ErrProfileNotFound) {
+			t.Fatalf("delete by another user: got %v, want ErrProfileNotFound", err)
+		}
+		if err := service.DeleteProfile(userID, created.ID); err != nil {
+			t.Fatalf("DeleteProfile: %v", err)
+		}
+		if _, err := service.GetProfile(userID, created.ID); !errors.Is(err, ErrProfileNotFound) {
+			t.Fatalf("GetProfile after delete: got %v, want ErrProfileNotFound", err)
+		}
+	})
+}
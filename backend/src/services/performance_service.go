@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/prices"
+	"github.com/username/taxfolio/backend/src/processors"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// ErrBenchmarkPricesMissing is returned when no closing prices are stored for the requested benchmark.
+var ErrBenchmarkPricesMissing = errors.New("no closing prices stored for the benchmark; import them with /api/prices/import")
+
+type performanceServiceImpl struct {
+	performanceProcessor processors.PerformanceProcessor
+}
+
+func NewPerformanceService(performanceProcessor processors.PerformanceProcessor) PerformanceService {
+	return &performanceServiceImpl{
+		performanceProcessor: performanceProcessor,
+	}
+}
+
+// GetPerformance computes the returns between from and to. A zero from starts at the user's first transaction;
+// a zero to ends today.
+func (s *performanceServiceImpl) GetPerformance(userID int64, from, to time.Time) (*models.PerformanceReport, error) {
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	from, to = performancePeriod(userTransactions, from, to)
+
+	priceHistory, err := loadPriceHistory(userID, userTransactions, to)
+	if err != nil {
+		return nil, err
+	}
+	report := s.performanceProcessor.Process(userTransactions, priceHistory, from, to)
+	logger.L.Info("Computed portfolio performance", "userID", userID, "from", report.From, "to", report.To, "days", len(report.Series))
+	return &report, nil
+}
+
+// GetBenchmarkComparison compares the portfolio between from and to with the same flows invested in the benchmark,
+// priced from the closing prices stored for benchmarkISIN. Zero dates default as in GetPerformance.
+func (s *performanceServiceImpl) GetBenchmarkComparison(userID int64, benchmarkISIN string, from, to time.Time) (*models.BenchmarkComparison, error) {
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	from, to = performancePeriod(userTransactions, from, to)
+
+	benchmarkQuotes, err := prices.History(userID, benchmarkISIN, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(benchmarkQuotes) == 0 {
+		return nil, ErrBenchmarkPricesMissing
+	}
+	priceHistory, err := loadPriceHistory(userID, userTransactions, to)
+	if err != nil {
+		return nil, err
+	}
+	comparison := s.performanceProcessor.CompareBenchmark(userTransactions, priceHistory, benchmarkISIN, benchmarkQuotes, from, to)
+	logger.L.Info("Computed benchmark comparison", "userID", userID, "benchmark", benchmarkISIN, "days", len(comparison.Series))
+	return &comparison, nil
+}
+
+// performancePeriod fills in a zero to with today and a zero from with the first transaction date.
+func performancePeriod(transactions []models.ProcessedTransaction, from, to time.Time) (time.Time, time.Time) {
+	if to.IsZero() {
+		now := time.Now()
+		to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if from.IsZero() {
+		from = to
+		for _, tx := range transactions {
+			if date := utils.ParseDate(tx.Date); !date.IsZero() && date.Before(from) {
+				from = date
+			}
+		}
+	}
+	return from, to
+}
+
+// loadPriceHistory reads the stored closing prices of every stock ISIN in the transactions.
+func loadPriceHistory(userID int64, transactions []models.ProcessedTransaction, to time.Time) (map[string][]prices.Quote, error) {
+	priceHistory := make(map[string][]prices.Quote)
+	for _, tx := range transactions {
+		if tx.TransactionType != "STOCK" || tx.ISIN == "" {
+			continue
+		}
+		if _, loaded := priceHistory[tx.ISIN]; loaded {
+			continue
+		}
+		quotes, err := prices.History(userID, tx.ISIN, to)
+		if err != nil {
+			return nil, err
+		}
+		priceHistory[tx.ISIN] = quotes
+	}
+	return priceHistory, nil
+}
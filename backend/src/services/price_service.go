@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/prices"
+	"github.com/username/taxfolio/backend/src/processors"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+type priceServiceImpl struct {
+	uploadService UploadService
+	provider      prices.Provider
+}
+
+func NewPriceService(uploadService UploadService, provider prices.Provider) PriceService {
+	return &priceServiceImpl{
+		uploadService: uploadService,
+		provider:      provider,
+	}
+}
+
+// ImportPrices stores the closing prices of a CSV file. isin and currency apply to rows without their own columns.
+func (s *priceServiceImpl) ImportPrices(fileReader io.Reader, userID int64, isin, currency string) (int, error) {
+	quotes, err := prices.ParseCSV(fileReader, isin, currency)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrParsingFailed, err)
+	}
+	count, err := prices.SaveQuotes(userID, quotes, "import")
+	if err != nil {
+		return 0, err
+	}
+	logger.L.Info("Imported closing prices", "userID", userID, "prices", count)
+	return count, nil
+}
+
+// SyncPrices asks the provider for the prices of every ISIN the user has traded, from the first trade until today.
+func (s *priceServiceImpl) SyncPrices(userID int64) (int, error) {
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return 0, err
+	}
+	firstDate := make(map[string]time.Time)
+	for _, tx := range userTransactions {
+		if tx.TransactionType != "STOCK" || tx.ISIN == "" {
+			continue
+		}
+		date := utils.ParseDate(tx.Date)
+		if first, ok := firstDate[tx.ISIN]; !ok || date.Before(first) {
+			firstDate[tx.ISIN] = date
+		}
+	}
+
+	total := 0
+	today := time.Now()
+	for isin, from := range firstDate {
+		quotes, err := s.provider.Fetch(isin, from, today)
+		if err != nil {
+			logger.L.Warn("Price provider failed for ISIN", "provider", s.provider.Name(), "isin", isin, "error", err)
+			continue
+		}
+		if len(quotes) == 0 {
+			continue
+		}
+		count, err := prices.SaveQuotes(userID, quotes, s.provider.Name())
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+	logger.L.Info("Synchronised closing prices", "userID", userID, "provider", s.provider.Name(), "isins", len(firstDate), "prices", total)
+	return total, nil
+}
+
+// GetHoldingsValuation values the stock positions open at the end of date at the latest stored price on or before
+// that date, converted to EUR at the price's date. Positions without a price are valued at cost and flagged.
+func (s *priceServiceImpl) GetHoldingsValuation(userID int64, date time.Time) (*models.HoldingsValuation, error) {
+	holdings, err := s.uploadService.GetStockHoldingsAt(userID, date)
+	if err != nil {
+		return nil, err
+	}
+	return s.ValueHoldings(userID, holdings, date)
+}
+
+// ValueHoldings values stock lots the caller has already replayed, as GetHoldingsValuation does.
+func (s *priceServiceImpl) ValueHoldings(userID int64, holdings []models.PurchaseLot, date time.Time) (*models.HoldingsValuation, error) {
+	positions := make(map[string]*models.PositionValuation)
+	currencies := make(map[string]string)
+	var order []string
+	for _, lot := range holdings {
+		key := lot.ISIN
+		if key == "" {
+			key = lot.ProductName
+		}
+		pos, ok := positions[key]
+		if !ok {
+			pos = &models.PositionValuation{ISIN: lot.ISIN, ProductName: lot.ProductName, InstrumentClass: lot.InstrumentClass}
+			positions[key] = pos
+			currencies[key] = lot.BuyCurrency
+			order = append(order, key)
+		}
+		pos.Quantity += lot.Quantity
+		// Buy amounts are negative for long lots and positive (sale proceeds) for short ones.
+		pos.CostEUR -= lot.BuyAmountEUR
+	}
+
+	valuation := &models.HoldingsValuation{
+		Date:      date.Format("02-01-2006"),
+		Positions: []models.PositionValuation{},
+	}
+	for _, key := range order {
+		pos := positions[key]
+		pos.MarketValueEUR = pos.CostEUR
+		pos.PriceMissing = true
+		if pos.ISIN != "" {
+			quote, found, err := prices.LatestOnOrBefore(userID, pos.ISIN, date, currencies[key])
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				rate, err := processors.GetExchangeRate(quote.Currency, quote.Date)
+				if err != nil || rate <= 0 {
+					logger.L.Warn("No exchange rate for price, valuing position at cost", "isin", pos.ISIN, "currency", quote.Currency, "error", err)
+				} else {
+					pos.Price = quote.Close
+					pos.PriceCurrency = quote.Currency
+					pos.PriceDate = quote.Date.Format("02-01-2006")
+					pos.MarketValueEUR = pos.Quantity * quote.Close / rate
+					pos.PriceMissing = false
+				}
+			}
+		}
+		if pos.PriceMissing {
+			valuation.MissingPrices++
+		}
+
+		pos.CostEUR = utils.RoundFloat(pos.CostEUR, 2)
+		pos.MarketValueEUR = utils.RoundFloat(pos.MarketValueEUR, 2)
+		pos.UnrealisedGainEUR = utils.RoundFloat(pos.MarketValueEUR-pos.CostEUR, 2)
+		if pos.CostEUR != 0 {
+			pos.UnrealisedGainPct = utils.RoundFloat(pos.UnrealisedGainEUR/math.Abs(pos.CostEUR)*100, 2)
+		}
+		valuation.TotalCostEUR += pos.CostEUR
+		valuation.TotalMarketValueEUR += pos.MarketValueEUR
+		valuation.TotalUnrealisedGainEUR += pos.UnrealisedGainEUR
+		valuation.Positions = append(valuation.Positions, *pos)
+	}
+
+	for i := range valuation.Positions {
+		if valuation.TotalMarketValueEUR != 0 {
+			valuation.Positions[i].Weight = utils.RoundFloat(valuation.Positions[i].MarketValueEUR/valuation.TotalMarketValueEUR*100, 2)
+		}
+	}
+	sort.SliceStable(valuation.Positions, func(i, j int) bool {
+		return valuation.Positions[i].MarketValueEUR > valuation.Positions[j].MarketValueEUR
+	})
+	valuation.TotalCostEUR = utils.RoundFloat(valuation.TotalCostEUR, 2)
+	valuation.TotalMarketValueEUR = utils.RoundFloat(valuation.TotalMarketValueEUR, 2)
+	valuation.TotalUnrealisedGainEUR = utils.RoundFloat(valuation.TotalUnrealisedGainEUR, 2)
+	return valuation, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/prices"
+	"github.com/username/taxfolio/backend/src/processors"
+)
+
+// holdingsUploadService returns fixed lots as the holdings at any date.
+type holdingsUploadService struct {
+	UploadService
+	lots []models.PurchaseLot
+}
+
+func (s holdingsUploadService) GetStockHoldingsAt(userID int64, asOf time.Time) ([]models.PurchaseLot, error) {
+	return s.lots, nil
+}
+
+func TestGetHoldingsValuation(t *testing.T) {
+	if err := processors.LoadHistoricalRates("../../data/historicalExchangeRate.json"); err != nil {
+		t.Fatalf("LoadHistoricalRates: %v", err)
+	}
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+
+	date := time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)
+	if _, err := prices.SaveQuotes(1, []prices.Quote{
+		{ISIN: "IE00B4L5Y983", Date: date.AddDate(0, 0, -1), Close: 90, Currency: "EUR"},
+		{ISIN: "NL0000000002", Date: date, Close: 20, Currency: "EUR"},
+	}, "import"); err != nil {
+		t.Fatalf("SaveQuotes: %v", err)
+	}
+
+	lots := []models.PurchaseLot{
+		{ISIN: "IE00B4L5Y983", ProductName: "WORLD ETF", Quantity: 5, BuyAmountEUR: -400, BuyCurrency: "EUR"},
+		{ISIN: "IE00B4L5Y983", ProductName: "WORLD ETF", Quantity: 5, BuyAmountEUR: -450, BuyCurrency: "EUR"},
+		{ISIN: "NL0000000002", ProductName: "SHORTED", Quantity: -10, BuyAmountEUR: 250, BuyCurrency: "EUR"},
+		{ISIN: "NL0000000003", ProductName: "NO PRICE", Quantity: 2, BuyAmountEUR: -100, BuyCurrency: "EUR"},
+	}
+	service := NewPriceService(holdingsUploadService{lots: lots}, nil)
+	valuation, err := service.GetHoldingsValuation(1, date)
+	if err != nil {
+		t.Fatalf("GetHoldingsValuation: %v", err)
+	}
+
+	tests := []struct {
+		isin         string
+		wantQuantity float64
+		wantCost     float64
+		wantValue    float64
+		wantGain     float64
+		wantMissing  bool
+	}{
+		{isin: "IE00B4L5Y983", wantQuantity: 10, wantCost: 850, wantValue: 900, wantGain: 50},
+		{isin: "NL0000000003", wantQuantity: 2, wantCost: 100, wantValue: 100, wantMissing: true},
+		{isin: "NL0000000002", wantQuantity: -10, wantCost: -250, wantValue: -200, wantGain: 50},
+	}
+	if len(valuation.Positions) != len(tests) {
+		t.Fatalf("got %d positions, want %d: %+v", len(valuation.Positions), len(tests), valuation.Positions)
+	}
+	for i, tt := range tests {
+		t.Run(tt.isin, func(t *testing.T) {
+			pos := valuation.Positions[i]
+			if pos.ISIN != tt.isin || pos.Quantity != tt.wantQuantity || pos.CostEUR != tt.wantCost || pos.MarketValueEUR != tt.wantValue ||
+				pos.UnrealisedGainEUR != tt.wantGain || pos.PriceMissing != tt.wantMissing {
+				t.Errorf("position = %+v, want %+v", pos, tt)
+			}
+		})
+	}
+	if valuation.MissingPrices != 1 || valuation.TotalMarketValueEUR != 800 || valuation.TotalUnrealisedGainEUR != 100 {
+		t.Errorf("totals: missing %d value %v gain %v, want 1 800 100", valuation.MissingPrices, valuation.TotalMarketValueEUR, valuation.TotalUnrealisedGainEUR)
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/parsers"
+	"github.com/username/taxfolio/backend/src/processors"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+const (
+	reconcileQuantityTolerance = 1e-6
+	reconcileCostAbsTolerance  = 1.0  // currency units
+	reconcileCostRelTolerance  = 0.01 // 1% of the broker cost
+)
+
+type reconciliationServiceImpl struct {
+	stockProcessor  processors.StockProcessor
+	optionProcessor processors.OptionProcessor
+}
+
+func NewReconciliationService(stockProcessor processors.StockProcessor, optionProcessor processors.OptionProcessor) ReconciliationService {
+	return &reconciliationServiceImpl{
+		stockProcessor:  stockProcessor,
+		optionProcessor: optionProcessor,
+	}
+}
+
+// StorePositions parses a broker positions export and replaces the user's previous snapshot for that source.
+func (s *reconciliationServiceImpl) StorePositions(fileReader io.Reader, userID int64, source string) (int, error) {
+	parser, err := parsers.GetPositionParser(source)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrParsingFailed, err)
+	}
+	positions, err := parser.ParsePositions(fileReader)
+	if err != nil {
+		logger.L.Error("Error parsing positions file", "userID", userID, "source", source, "error", err)
+		return 0, fmt.Errorf("%w: %v", ErrParsingFailed, err)
+	}
+
+	dbTx, err := database.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error beginning database transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			dbTx.Rollback()
+		}
+	}()
+
+	if _, err := dbTx.Exec("DELETE FROM broker_positions WHERE user_id = ? AND source = ?", userID, source); err != nil {
+		return 0, fmt.Errorf("error clearing previous positions snapshot: %w", err)
+	}
+
+	stmt, err := dbTx.Prepare(`
+		INSERT INTO broker_positions
+		(user_id, source, report_date, asset_type, isin, symbol, product_name, quantity, cost_basis, currency,
+		 underlying, strike, expiry, option_right, multiplier)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, pos := range positions {
+		if _, err := stmt.Exec(userID, source, pos.ReportDate, pos.AssetType, pos.ISIN, pos.Symbol,
+			pos.ProductName, pos.Quantity, pos.CostBasis, pos.Currency,
+			pos.Underlying, pos.Strike, pos.Expiry, pos.OptionRight, pos.Multiplier); err != nil {
+			return 0, fmt.Errorf("error inserting broker position (%s): %w", pos.ProductName, err)
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing broker positions: %w", err)
+	}
+	committed = true
+	logger.L.Info("Stored broker positions snapshot", "userID", userID, "source", source, "positions", len(positions))
+	return len(positions), nil
+}
+
+// Reconcile compares every stored broker snapshot with the holdings computed from the transaction history up to
+// the snapshot's report date, per ISIN for stocks and per contract for options. Stock lots are replayed over all
+// brokers, so shares transferred in are held at the receiving broker with their original cost.
+func (s *reconciliationServiceImpl) Reconcile(userID int64) (*models.ReconciliationReport, error) {
+	positionsBySource, err := fetchBrokerPositions(userID)
+	if err != nil {
+		return nil, err
+	}
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReconciliationReport{
+		ReportDates: make(map[string]string),
+		Items:       []models.ReconciliationItem{},
+	}
+
+	sources := make([]string, 0, len(positionsBySource))
+	for source := range positionsBySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		positions := positionsBySource[source]
+		// Snapshots without a report date are compared with the full history.
+		reportDate := time.Now()
+		if len(positions) > 0 && positions[0].ReportDate != "" {
+			report.ReportDates[source] = positions[0].ReportDate
+			reportDate = utils.ParseDate(positions[0].ReportDate)
+		}
+
+		var sourceTxs []models.ProcessedTransaction
+		for _, tx := range userTransactions {
+			if tx.Source == source {
+				sourceTxs = append(sourceTxs, tx)
+			}
+		}
+
+		computed := make(map[string]*models.ReconciliationItem)
+		for _, lot := range s.stockProcessor.HoldingsAt(userTransactions, reportDate) {
+			if lot.Source != source {
+				continue
+			}
+			item := reconciliationEntry(computed, source, "STOCK", lot.ISIN, lot.ProductName, lot.BuyCurrency)
+			item.ComputedQuantity += lot.Quantity
+			item.ComputedCost += math.Abs(lot.BuyAmount)
+		}
+		for _, holding := range s.optionProcessor.HoldingsAt(sourceTxs, reportDate) {
+			key := processors.OptionContractKey(models.ProcessedTransaction{ProductName: holding.ProductName, Underlying: holding.Underlying,
+				OptionRight: holding.OptionRight, Strike: holding.Strike, Expiry: holding.Expiry, Multiplier: holding.Multiplier})
+			item := reconciliationEntry(computed, source, "OPTION", key, holding.ProductName, holding.OpenCurrency)
+			item.ComputedQuantity += holding.Quantity
+			item.ComputedCost += math.Abs(holding.OpenAmount)
+		}
+
+		brokerHasCost := make(map[string]bool)
+		for _, pos := range positions {
+			key := pos.ISIN
+			if pos.AssetType == "OPTION" {
+				key = processors.OptionContractKey(models.ProcessedTransaction{ProductName: pos.ProductName, Underlying: pos.Underlying,
+					OptionRight: pos.OptionRight, Strike: pos.Strike, Expiry: pos.Expiry, Multiplier: pos.Multiplier})
+			}
+			item := reconciliationEntry(computed, source, pos.AssetType, key, pos.ProductName, pos.Currency)
+			item.BrokerQuantity += pos.Quantity
+			item.BrokerCost += math.Abs(pos.CostBasis)
+			if pos.CostBasis != 0 {
+				brokerHasCost[pos.AssetType+"|"+key] = true
+			}
+		}
+
+		keys := make([]string, 0, len(computed))
+		for key := range computed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			item := computed[key]
+			item.ComputedQuantity = utils.RoundFloat(item.ComputedQuantity, 6)
+			item.ComputedCost = utils.RoundFloat(item.ComputedCost, 2)
+			item.BrokerCost = utils.RoundFloat(item.BrokerCost, 2)
+			item.QuantityDiff = utils.RoundFloat(item.ComputedQuantity-item.BrokerQuantity, 6)
+			if brokerHasCost[key] {
+				item.CostDiff = utils.RoundFloat(item.ComputedCost-item.BrokerCost, 2)
+			}
+
+			switch {
+			case item.BrokerQuantity == 0 && item.ComputedQuantity != 0:
+				item.Status = "MISSING_IN_BROKER"
+			case item.ComputedQuantity == 0 && item.BrokerQuantity != 0:
+				item.Status = "MISSING_IN_HISTORY"
+			case math.Abs(item.QuantityDiff) > reconcileQuantityTolerance:
+				item.Status = "QUANTITY_MISMATCH"
+			case brokerHasCost[key] && math.Abs(item.CostDiff) > math.Max(reconcileCostAbsTolerance, item.BrokerCost*reconcileCostRelTolerance):
+				item.Status = "COST_MISMATCH"
+			default:
+				item.Status = "OK"
+			}
+			if item.Status != "OK" {
+				report.Mismatches++
+			}
+			report.Items = append(report.Items, *item)
+		}
+	}
+
+	logger.L.Info("Reconciliation complete", "userID", userID, "sources", len(sources), "items", len(report.Items), "mismatches", report.Mismatches)
+	return report, nil
+}
+
+func reconciliationEntry(entries map[string]*models.ReconciliationItem, source, assetType, key, productName, currency string) *models.ReconciliationItem {
+	mapKey := assetType + "|" + key
+	item, ok := entries[mapKey]
+	if !ok {
+		item = &models.ReconciliationItem{
+			Source:      source,
+			AssetType:   assetType,
+			Key:         key,
+			ProductName: productName,
+			Currency:    currency,
+		}
+		entries[mapKey] = item
+	}
+	return item
+}
+
+func fetchBrokerPositions(userID int64) (map[string][]models.BrokerPosition, error) {
+	rows, err := database.DB.Query(`
+		SELECT source, report_date, asset_type, isin, symbol, product_name, quantity, cost_basis, currency,
+		       underlying, strike, expiry, option_right, multiplier
+		FROM broker_positions
+		WHERE user_id = ?
+		ORDER BY source ASC, id ASC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying broker positions for userID %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	positionsBySource := make(map[string][]models.BrokerPosition)
+	for rows.Next() {
+		var pos models.BrokerPosition
+		if err := rows.Scan(&pos.Source, &pos.ReportDate, &pos.AssetType, &pos.ISIN, &pos.Symbol,
+			&pos.ProductName, &pos.Quantity, &pos.CostBasis, &pos.Currency,
+			&pos.Underlying, &pos.Strike, &pos.Expiry, &pos.OptionRight, &pos.Multiplier); err != nil {
+			return nil, fmt.Errorf("error scanning broker position for userID %d: %w", userID, err)
+		}
+		positionsBySource[pos.Source] = append(positionsBySource[pos.Source], pos)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating broker positions for userID %d: %w", userID, err)
+	}
+	return positionsBySource, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/processors"
+)
+
+func TestReconcile(t *testing.T) {
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+
+	stock := func(source, date, isin, buySell string, quantity, amount float64) models.ProcessedTransaction {
+		return models.ProcessedTransaction{Date: date, Source: source, ProductName: isin, ISIN: isin, TransactionType: "STOCK", BuySell: buySell,
+			Quantity: quantity, OriginalQuantity: quantity, Amount: amount, AmountEUR: amount, Currency: "EUR", ExchangeRate: 1}
+	}
+	option := models.ProcessedTransaction{Date: "01-02-2024", Source: "ibkr", ProductName: "FLW  DEC26 31 P", TransactionType: "OPTION", BuySell: "SELL",
+		Quantity: 2, OriginalQuantity: 2, Amount: 240, AmountEUR: 240, Currency: "EUR", ExchangeRate: 1,
+		Underlying: "FLW", OptionRight: "PUT", Strike: 31, Expiry: "18-12-2026", Multiplier: 100}
+	transactions := []models.ProcessedTransaction{
+		stock("ibkr", "01-02-2024", "MATCHED", "BUY", 10, -1000),
+		stock("ibkr", "15-04-2024", "MATCHED", "BUY", 5, -600), // After the snapshot
+		stock("degiro", "10-01-2023", "MOVED", "BUY", 10, -800),
+		stock("degiro", "01-06-2023", "MOVED", "TRANSFER_OUT", 10, 0),
+		stock("ibkr", "03-06-2023", "MOVED", "TRANSFER_IN", 10, 0),
+		stock("ibkr", "01-03-2024", "MOVED", "SELL", 4, 400),
+		stock("ibkr", "01-02-2024", "SHORTFALL", "BUY", 5, -500),
+		stock("ibkr", "01-02-2024", "UNREPORTED", "BUY", 3, -300),
+		stock("degiro", "01-02-2024", "DEGIRO", "BUY", 2, -200),
+		option,
+	}
+	for i, tx := range transactions {
+		tx.HashId = string(rune('a' + i))
+		if _, err := database.DB.Exec(insertProcessedTransactionQuery, processedTransactionInsertArgs(1, tx)...); err != nil {
+			t.Fatalf("inserting transaction: %v", err)
+		}
+	}
+
+	positions := []models.BrokerPosition{
+		{Source: "ibkr", ReportDate: "28-03-2024", AssetType: "STOCK", ISIN: "MATCHED", ProductName: "MATCHED", Quantity: 10, CostBasis: 1000, Currency: "EUR"},
+		{Source: "ibkr", ReportDate: "28-03-2024", AssetType: "STOCK", ISIN: "MOVED", ProductName: "MOVED", Quantity: 6, Currency: "EUR"},
+		{Source: "ibkr", ReportDate: "28-03-2024", AssetType: "STOCK", ISIN: "SHORTFALL", ProductName: "SHORTFALL", Quantity: 7, Currency: "EUR"},
+		{Source: "ibkr", ReportDate: "28-03-2024", AssetType: "STOCK", ISIN: "UNTRACKED", ProductName: "UNTRACKED", Quantity: 4, Currency: "EUR"},
+		{Source: "ibkr", ReportDate: "28-03-2024", AssetType: "OPTION", ProductName: "FLW 18DEC26 31 P", Quantity: -2, Currency: "EUR",
+			Underlying: "FLW", OptionRight: "PUT", Strike: 31, Expiry: "18-12-2026", Multiplier: 100},
+		{Source: "degiro", AssetType: "STOCK", ISIN: "DEGIRO", ProductName: "DEGIRO", Quantity: 2, Currency: "EUR"},
+	}
+	for _, pos := range positions {
+		if _, err := database.DB.Exec(`INSERT INTO broker_positions
+			(user_id, source, report_date, asset_type, isin, symbol, product_name, quantity, cost_basis, currency, underlying, strike, expiry, option_right, multiplier)
+			VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, pos.Source, pos.ReportDate, pos.AssetType, pos.ISIN, pos.Symbol, pos.ProductName,
+			pos.Quantity, pos.CostBasis, pos.Currency, pos.Underlying, pos.Strike, pos.Expiry, pos.OptionRight, pos.Multiplier); err != nil {
+			t.Fatalf("inserting broker position: %v", err)
+		}
+	}
+
+	report, err := NewReconciliationService(processors.NewStockProcessor(), processors.NewOptionProcessor()).Reconcile(1)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, item := range report.Items {
+		statuses[item.Source+"|"+item.AssetType+"|"+item.ProductName] = item.Status
+	}
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "holding at the report date matches, later trades ignored", key: "ibkr|STOCK|MATCHED", want: "OK"},
+		{name: "shares transferred in are held at the receiving broker", key: "ibkr|STOCK|MOVED", want: "OK"},
+		{name: "quantity mismatch", key: "ibkr|STOCK|SHORTFALL", want: "QUANTITY_MISMATCH"},
+		{name: "missing in the snapshot", key: "ibkr|STOCK|UNREPORTED", want: "MISSING_IN_BROKER"},
+		{name: "missing in the history", key: "ibkr|STOCK|UNTRACKED", want: "MISSING_IN_HISTORY"},
+		{name: "option matched by contract despite its name", key: "ibkr|OPTION|FLW  DEC26 31 P", want: "OK"},
+		{name: "snapshot without report date uses the full history", key: "degiro|STOCK|DEGIRO", want: "OK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statuses[tt.key]; got != tt.want {
+				t.Errorf("status = %q, want %q (items: %+v)", got, tt.want, report.Items)
+			}
+		})
+	}
+	if len(report.Items) != len(tests) || report.Mismatches != 3 {
+		t.Errorf("got %d items and %d mismatches, want %d and 3: %+v", len(report.Items), report.Mismatches, len(tests), report.Items)
+	}
+	if report.ReportDates["ibkr"] != "28-03-2024" {
+		t.Errorf("ibkr report date = %q, want 28-03-2024", report.ReportDates["ibkr"])
+	}
+}
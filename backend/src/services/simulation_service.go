@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/processors"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+// simulationSource marks the hypothetical transactions of the simulator.
+const simulationSource = "simulation"
+
+// SaleSimulationInput is the client payload for simulating a stock sale.
+type SaleSimulationInput struct {
+	ISIN       string  `json:"isin"`
+	Quantity   float64 `json:"quantity"`
+	Price      float64 `json:"price"`
+	Currency   string  `json:"currency"`
+	Commission float64 `json:"commission"` // Optional, in Currency
+	Date       string  `json:"date"`       // DD-MM-YYYY; today when empty
+}
+
+type simulationServiceImpl struct {
+	transactionProcessor *processors.TransactionProcessor
+	stockProcessor       processors.StockProcessor
+}
+
+func NewSimulationService(transactionProcessor *processors.TransactionProcessor, stockProcessor processors.StockProcessor) SimulationService {
+	return &simulationServiceImpl{
+		transactionProcessor: transactionProcessor,
+		stockProcessor:       stockProcessor,
+	}
+}
+
+// SimulateSale runs a hypothetical SELL, enriched like an imported row, through the FIFO matching of the
+// user's stock transactions. Nothing is stored.
+func (s *simulationServiceImpl) SimulateSale(userID int64, input SaleSimulationInput) (*models.SaleSimulation, error) {
+	isin := strings.ToUpper(strings.TrimSpace(input.ISIN))
+	if err := validation.ValidateISIN(isin); err != nil {
+		return nil, err
+	}
+	currency := strings.ToUpper(strings.TrimSpace(input.Currency))
+	if err := validation.ValidateStringNotEmpty(currency, "Currency"); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateCurrencyCode(currency); err != nil {
+		return nil, err
+	}
+	if input.Quantity <= 0 || input.Price <= 0 {
+		return nil, fmt.Errorf("%w: quantity and price must be positive", validation.ErrValidationFailed)
+	}
+	if input.Commission < 0 {
+		return nil, fmt.Errorf("%w: commission cannot be negative", validation.ErrValidationFailed)
+	}
+	date := time.Now()
+	if strings.TrimSpace(input.Date) != "" {
+		var err error
+		if date, err = validation.ValidateDateString(input.Date, "Date"); err != nil {
+			return nil, err
+		}
+	}
+
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	productName := isin
+	for _, tx := range userTransactions {
+		if tx.ISIN == isin && tx.TransactionType == "STOCK" {
+			productName = tx.ProductName
+		}
+	}
+
+	processed := s.transactionProcessor.Process([]models.CanonicalTransaction{{
+		Source:          simulationSource,
+		TransactionDate: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		ProductName:     productName,
+		ISIN:            isin,
+		Quantity:        input.Quantity,
+		Price:           input.Price,
+		Currency:        currency,
+		Amount:          input.Quantity * input.Price,
+		SourceAmount:    input.Quantity * input.Price,
+		Commission:      input.Commission,
+		TransactionType: "STOCK",
+		BuySell:         "SELL",
+		RawText:         fmt.Sprintf("simulation|%s|%f|%f|%f|%s", isin, input.Quantity, input.Price, input.Commission, currency),
+	}})
+	if len(processed) != 1 {
+		return nil, fmt.Errorf("%w: simulated sale could not be processed", ErrProcessingFailed)
+	}
+
+	simulation := s.stockProcessor.SimulateSale(userTransactions, processed[0])
+	return &simulation, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/processors"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+func TestSimulateSale(t *testing.T) {
+	if err := processors.LoadHistoricalRates("../../data/historicalExchangeRate.json"); err != nil {
+		t.Fatalf("loading exchange rates: %v", err)
+	}
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+
+	stored := []models.ProcessedTransaction{
+		{Date: "10-01-2024", Source: "degiro", ProductName: "ACME", ISIN: "NL0000000001", TransactionType: "STOCK", BuySell: "BUY",
+			Quantity: 10, OriginalQuantity: 10, Price: 100, Amount: -1000, AmountEUR: -1000, Currency: "EUR", ExchangeRate: 1, HashId: "buy"},
+		{Date: "01-02-2024", Source: "degiro", ProductName: "FLW P31.00 18DEC26", TransactionType: "OPTION", BuySell: "SELL",
+			Quantity: 1, OriginalQuantity: 1, Amount: 300, AmountEUR: 300, Currency: "EUR", ExchangeRate: 1, HashId: "option-open"},
+		{Date: "01-03-2024", Source: "degiro", ProductName: "FLW P31.00 18DEC26", TransactionType: "OPTION", BuySell: "BUY",
+			Quantity: 1, OriginalQuantity: 1, Amount: -100, AmountEUR: -100, Currency: "EUR", ExchangeRate: 1, HashId: "option-close"},
+	}
+	for _, tx := range stored {
+		if _, err := database.DB.Exec(insertProcessedTransactionQuery, processedTransactionInsertArgs(1, tx)...); err != nil {
+			t.Fatalf("inserting transaction: %v", err)
+		}
+	}
+
+	valid := SaleSimulationInput{ISIN: "NL0000000001", Quantity: 4, Price: 90, Currency: "eur", Commission: 2, Date: "01-06-2024"}
+	tests := []struct {
+		name             string
+		mutate           func(*SaleSimulationInput)
+		wantErr          bool
+		wantCommission   float64
+		wantGain         float64
+		wantYearGain     float64
+		wantEstimatedTax float64
+	}{
+		{name: "commission is deducted from the gain", mutate: func(*SaleSimulationInput) {}, wantCommission: 2, wantGain: -42, wantYearGain: 200, wantEstimatedTax: -11.76},
+		{name: "commission is optional", mutate: func(in *SaleSimulationInput) { in.Commission = 0 }, wantGain: -40, wantYearGain: 200, wantEstimatedTax: -11.2},
+		{name: "sale before the option gain", mutate: func(in *SaleSimulationInput) { in.Date = "15-01-2024" }, wantCommission: 2, wantGain: -42},
+		{name: "negative commission", mutate: func(in *SaleSimulationInput) { in.Commission = -2 }, wantErr: true},
+		{name: "invalid isin", mutate: func(in *SaleSimulationInput) { in.ISIN = "NL000000000X" }, wantErr: true},
+	}
+
+	service := NewSimulationService(processors.NewTransactionProcessor(), processors.NewStockProcessor())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.mutate(&input)
+			simulation, err := service.SimulateSale(1, input)
+			if tt.wantErr {
+				if !errors.Is(err, validation.ErrValidationFailed) {
+					t.Fatalf("error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SimulateSale: %v", err)
+			}
+			if simulation.ProductName != "ACME" || len(simulation.Lots) != 1 {
+				t.Fatalf("unexpected simulation: %+v", simulation)
+			}
+			if simulation.CommissionEUR != tt.wantCommission || simulation.RealisedGainEUR != tt.wantGain || simulation.YearRealisedGainEUR != tt.wantYearGain || simulation.EstimatedTaxEUR != tt.wantEstimatedTax {
+				t.Errorf("commission %v gain %v year gain %v tax %v, want %v %v %v %v", simulation.CommissionEUR, simulation.RealisedGainEUR,
+					simulation.YearRealisedGainEUR, simulation.EstimatedTaxEUR, tt.wantCommission, tt.wantGain, tt.wantYearGain, tt.wantEstimatedTax)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"time"
+
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/processors"
+)
+
+type taxLossServiceImpl struct {
+	taxLossProcessor processors.TaxLossProcessor
+	stockProcessor   processors.StockProcessor
+	priceService     PriceService
+}
+
+func NewTaxLossService(taxLossProcessor processors.TaxLossProcessor, stockProcessor processors.StockProcessor, priceService PriceService) TaxLossService {
+	return &taxLossServiceImpl{
+		taxLossProcessor: taxLossProcessor,
+		stockProcessor:   stockProcessor,
+		priceService:     priceService,
+	}
+}
+
+// GetHarvestReport lists today's open stock lots at a loss against this year's realised gains.
+func (s *taxLossServiceImpl) GetHarvestReport(userID int64) (*models.HarvestReport, error) {
+	today := time.Now()
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	holdings := s.stockProcessor.HoldingsAt(userTransactions, today)
+	valuation, err := s.priceService.ValueHoldings(userID, holdings, today)
+	if err != nil {
+		return nil, err
+	}
+	report := s.taxLossProcessor.Process(userTransactions, holdings, valuation, today)
+	return &report, nil
+}
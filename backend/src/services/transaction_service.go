@@ -0,0 +1,303 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/processors"
+	"github.com/username/taxfolio/backend/src/security/validation"
+	"github.com/username/taxfolio/backend/src/utils"
+)
+
+// ManualTransactionSource marks rows entered by the user rather than imported from a broker file.
+const ManualTransactionSource = "manual"
+
+var (
+	ErrTransactionNotFound  = errors.New("transaction not found")
+	ErrDuplicateTransaction = errors.New("an identical transaction already exists")
+	ErrNotManualTransaction = errors.New("only manually entered transactions can be edited")
+)
+
+// allowedManualBuySell lists the BuySell values accepted per manual transaction type.
+var allowedManualBuySell = map[string]map[string]bool{
+	"STOCK":    {"BUY": true, "SELL": true, "TRANSFER_IN": true, "TRANSFER_OUT": true},
+	"OPTION":   {"BUY": true, "SELL": true},
+	"DIVIDEND": {"": true},
+	"FEE":      {"": true},
+	"CASH":     {"": true},
+}
+
+// ManualTransactionInput is the client payload for creating or updating a manual transaction.
+type ManualTransactionInput struct {
+	Date               string  `json:"date"` // DD-MM-YYYY
+	ProductName        string  `json:"product_name"`
+	ISIN               string  `json:"isin"`
+	Quantity           float64 `json:"quantity"`
+	Price              float64 `json:"price"`
+	TransactionType    string  `json:"transaction_type"`
+	TransactionSubType string  `json:"transaction_subtype"`
+	BuySell            string  `json:"buy_sell"`
+	Amount             float64 `json:"amount"` // Signed amount in Currency; derived from quantity*price for trades when zero
+	Currency           string  `json:"currency"`
+	Commission         float64 `json:"commission"`
+	OrderID            string  `json:"order_id"`
+	Description        string  `json:"description"`
+	InstrumentClass    string  `json:"instrument_class"` // Optional, STOCK and DIVIDEND only; overrides the classification from the reference data
+	Multiplier         float64 `json:"multiplier"`       // Optional, OPTION only; units of the underlying per contract, 100 when zero
+}
+
+type transactionServiceImpl struct {
+	transactionProcessor *processors.TransactionProcessor
+	uploadService        UploadService
+}
+
+func NewTransactionService(transactionProcessor *processors.TransactionProcessor, uploadService UploadService) TransactionService {
+	return &transactionServiceImpl{
+		transactionProcessor: transactionProcessor,
+		uploadService:        uploadService,
+	}
+}
+
+func (s *transactionServiceImpl) CreateManualTransaction(userID int64, input ManualTransactionInput) (*models.ProcessedTransaction, error) {
+	processed, err := s.enrichManualTransaction(input)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := database.DB.Exec(insertProcessedTransactionQuery, processedTransactionInsertArgs(userID, *processed)...)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrDuplicateTransaction
+		}
+		return nil, fmt.Errorf("error inserting manual transaction: %w", err)
+	}
+	if processed.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("error reading id of manual transaction: %w", err)
+	}
+
+	s.uploadService.InvalidateUserCache(userID)
+	logger.L.Info("Manual transaction created", "userID", userID, "transactionID", processed.ID, "type", processed.TransactionType)
+	return processed, nil
+}
+
+func (s *transactionServiceImpl) UpdateManualTransaction(userID, transactionID int64, input ManualTransactionInput) (*models.ProcessedTransaction, error) {
+	if err := requireManualTransaction(userID, transactionID); err != nil {
+		return nil, err
+	}
+
+	processed, err := s.enrichManualTransaction(input)
+	if err != nil {
+		return nil, err
+	}
+	processed.ID = transactionID
+
+	_, err = database.DB.Exec(`
+		UPDATE processed_transactions
+		SET date = ?, source = ?, product_name = ?, isin = ?, quantity = ?, original_quantity = ?, price = ?,
+		    transaction_type = ?, transaction_subtype = ?, buy_sell = ?, description = ?, amount = ?, currency = ?,
+		    commission = ?, commission_currency = ?, order_id = ?, exchange_rate = ?, amount_eur = ?, exchange_rate_fallback = ?,
+		    country_code = ?, instrument_class = ?, underlying = ?, strike = ?, expiry = ?, option_right = ?, multiplier = ?,
+		    input_string = ?, hash_id = ?
+		WHERE id = ? AND user_id = ?`,
+		processed.Date, processed.Source, processed.ProductName, processed.ISIN, processed.Quantity, processed.OriginalQuantity, processed.Price,
+		processed.TransactionType, processed.TransactionSubType, processed.BuySell, processed.Description, processed.Amount, processed.Currency,
+		processed.Commission, processed.CommissionCurrency, processed.OrderID, processed.ExchangeRate, processed.AmountEUR, processed.RateFallback,
+		processed.CountryCode, processed.InstrumentClass, processed.Underlying, processed.Strike, processed.Expiry, processed.OptionRight, processed.Multiplier,
+		processed.InputString, processed.HashId,
+		transactionID, userID)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrDuplicateTransaction
+		}
+		return nil, fmt.Errorf("error updating manual transaction %d: %w", transactionID, err)
+	}
+
+	s.uploadService.InvalidateUserCache(userID)
+	logger.L.Info("Manual transaction updated", "userID", userID, "transactionID", transactionID)
+	return processed, nil
+}
+
+// DeleteTransaction removes a single transaction owned by the user, whether imported or manual.
+// A deleted imported row comes back if the same file is uploaded again.
+func (s *transactionServiceImpl) DeleteTransaction(userID, transactionID int64) error {
+	result, err := database.DB.Exec("DELETE FROM processed_transactions WHERE id = ? AND user_id = ?", transactionID, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting transaction %d: %w", transactionID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking deletion of transaction %d: %w", transactionID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrTransactionNotFound
+	}
+
+	s.uploadService.InvalidateUserCache(userID)
+	logger.L.Info("Transaction deleted", "userID", userID, "transactionID", transactionID)
+	return nil
+}
+
+// requireManualTransaction checks that the transaction exists for the user and was entered manually.
+func requireManualTransaction(userID, transactionID int64) error {
+	var source string
+	err := database.DB.QueryRow("SELECT source FROM processed_transactions WHERE id = ? AND user_id = ?", transactionID, userID).Scan(&source)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTransactionNotFound
+		}
+		return fmt.Errorf("error loading transaction %d: %w", transactionID, err)
+	}
+	if source != ManualTransactionSource {
+		return ErrNotManualTransaction
+	}
+	return nil
+}
+
+// enrichManualTransaction validates the input and runs it through the TransactionProcessor so that
+// exchange rate, EUR amount, country code and hash are filled exactly as for imported rows.
+func (s *transactionServiceImpl) enrichManualTransaction(input ManualTransactionInput) (*models.ProcessedTransaction, error) {
+	canonical, err := buildManualCanonicalTransaction(input)
+	if err != nil {
+		return nil, err
+	}
+	processed := s.transactionProcessor.Process([]models.CanonicalTransaction{canonical})
+	if len(processed) != 1 {
+		return nil, fmt.Errorf("%w: manual transaction could not be processed", ErrProcessingFailed)
+	}
+	// A class chosen by the user wins over the reference data, unlike a broker's hint on imported rows.
+	if canonical.InstrumentClass != "" {
+		processed[0].InstrumentClass = canonical.InstrumentClass
+	}
+	// Imported rows keep the raw source line as description; manual rows prefer the user's own note.
+	if description := strings.TrimSpace(input.Description); description != "" {
+		processed[0].Description = description
+	}
+	return &processed[0], nil
+}
+
+func buildManualCanonicalTransaction(input ManualTransactionInput) (models.CanonicalTransaction, error) {
+	date, err := validation.ValidateDateString(input.Date, "Date")
+	if err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+
+	isin := strings.ToUpper(strings.TrimSpace(input.ISIN))
+	if err := validation.ValidateISIN(isin); err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(input.Currency))
+	if err := validation.ValidateStringNotEmpty(currency, "Currency"); err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+	if err := validation.ValidateCurrencyCode(currency); err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+
+	orderID := strings.TrimSpace(input.OrderID)
+	if err := validation.ValidateOrderID(orderID); err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+
+	productName := strings.TrimSpace(input.ProductName)
+	if err := validation.ValidateStringNotEmpty(productName, "Product Name"); err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+	if err := validation.ValidateStringMaxLength(productName, validation.MaxProductNameLength, "Product Name"); err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+	description := strings.TrimSpace(input.Description)
+	if err := validation.ValidateStringMaxLength(description, validation.MaxDescriptionLength, "Description"); err != nil {
+		return models.CanonicalTransaction{}, err
+	}
+	for field, value := range map[string]string{"Product Name": productName, "Description": description} {
+		if err := validation.CheckXSSPatterns(value, field, "manual-transaction"); err != nil {
+			return models.CanonicalTransaction{}, err
+		}
+		if err := validation.CheckFormulaInjection(value, field, "manual-transaction"); err != nil {
+			return models.CanonicalTransaction{}, err
+		}
+	}
+
+	txType := strings.ToUpper(strings.TrimSpace(input.TransactionType))
+	subType := strings.ToUpper(strings.TrimSpace(input.TransactionSubType))
+	buySell := strings.ToUpper(strings.TrimSpace(input.BuySell))
+	allowedBuySell, ok := allowedManualBuySell[txType]
+	if !ok {
+		return models.CanonicalTransaction{}, fmt.Errorf("%w: Transaction Type ('%s') is not supported", validation.ErrValidationFailed, input.TransactionType)
+	}
+	if !allowedBuySell[buySell] {
+		return models.CanonicalTransaction{}, fmt.Errorf("%w: Buy/Sell ('%s') is not valid for transaction type %s", validation.ErrValidationFailed, input.BuySell, txType)
+	}
+
+	instrumentClass := strings.ToUpper(strings.TrimSpace(input.InstrumentClass))
+	if instrumentClass != "" && !models.IsInstrumentClass(instrumentClass) {
+		return models.CanonicalTransaction{}, fmt.Errorf("%w: Instrument Class ('%s') is not supported", validation.ErrValidationFailed, input.InstrumentClass)
+	}
+	if instrumentClass != "" && txType != "STOCK" && txType != "DIVIDEND" {
+		return models.CanonicalTransaction{}, fmt.Errorf("%w: Instrument Class can only be set for STOCK and DIVIDEND transactions", validation.ErrValidationFailed)
+	}
+
+	if input.Quantity < 0 || input.Price < 0 || input.Commission < 0 || input.Multiplier < 0 {
+		return models.CanonicalTransaction{}, fmt.Errorf("%w: quantity, price, commission and multiplier cannot be negative", validation.ErrValidationFailed)
+	}
+	multiplier := 0.0
+	if txType == "OPTION" {
+		multiplier = input.Multiplier
+		if multiplier == 0 {
+			multiplier = utils.DefaultOptionMultiplier
+		}
+	}
+	if (txType == "STOCK" || txType == "OPTION") && input.Quantity == 0 {
+		return models.CanonicalTransaction{}, fmt.Errorf("%w: Quantity is required for %s transactions", validation.ErrValidationFailed, txType)
+	}
+
+	// Trades follow the parsers' sign convention: money leaves the account on BUY and comes in on SELL.
+	amount := input.Amount
+	if amount == 0 && (buySell == "BUY" || buySell == "SELL") {
+		amount = input.Quantity * input.Price
+		if multiplier > 0 {
+			// Option prices are quoted per unit of the underlying.
+			amount *= multiplier
+		}
+	}
+	// A transfer-in amount is the cost basis carried over from the sending broker, so it is signed like a buy.
+	switch buySell {
+	case "BUY", "TRANSFER_IN":
+		amount = -math.Abs(amount)
+	case "SELL":
+		amount = math.Abs(amount)
+	case "TRANSFER_OUT":
+		amount = 0
+	}
+
+	rawText := fmt.Sprintf("Manual|%s|%s|%s|%s|%s|%s|%f|%f|%f|%s|%f|%s|%s",
+		date.Format("02-01-2006"), txType, subType, buySell, isin, productName,
+		input.Quantity, input.Price, amount, currency, input.Commission, orderID, description)
+
+	return models.CanonicalTransaction{
+		Source:             ManualTransactionSource,
+		TransactionDate:    date,
+		ProductName:        productName,
+		ISIN:               isin,
+		Quantity:           input.Quantity,
+		Price:              input.Price,
+		Commission:         input.Commission,
+		Currency:           currency,
+		OrderID:            orderID,
+		RawText:            rawText,
+		SourceAmount:       input.Amount,
+		Amount:             amount,
+		TransactionType:    txType,
+		TransactionSubType: subType,
+		BuySell:            buySell,
+		InstrumentClass:    instrumentClass,
+		Multiplier:         multiplier,
+	}, nil
+}
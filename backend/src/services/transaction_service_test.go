@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/logger"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/security/validation"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error")
+	os.Exit(m.Run())
+}
+
+func TestBuildManualCanonicalTransaction(t *testing.T) {
+	valid := ManualTransactionInput{
+		Date:            "15-03-2024",
+		ProductName:     "Apple Inc",
+		ISIN:            "US0378331005",
+		Quantity:        10,
+		Price:           150,
+		TransactionType: "stock",
+		BuySell:         "buy",
+		Currency:        "usd",
+		Commission:      1,
+	}
+
+	tests := []struct {
+		name       string
+		mutate     func(*ManualTransactionInput)
+		wantErr    bool
+		wantAmount float64
+	}{
+		{name: "buy amount derived and negative", mutate: func(*ManualTransactionInput) {}, wantAmount: -1500},
+		{name: "sell amount positive", mutate: func(in *ManualTransactionInput) { in.BuySell = "SELL"; in.Amount = -1400 }, wantAmount: 1400},
+		{name: "transfer in keeps carried cost as a buy", mutate: func(in *ManualTransactionInput) { in.BuySell = "TRANSFER_IN"; in.Amount = 1200 }, wantAmount: -1200},
+		{name: "transfer out moves no cash", mutate: func(in *ManualTransactionInput) { in.BuySell = "TRANSFER_OUT"; in.Amount = 1200 }, wantAmount: 0},
+		{name: "option amount uses multiplier", mutate: func(in *ManualTransactionInput) { in.TransactionType = "OPTION"; in.Quantity = 2; in.Price = 1.5 }, wantAmount: -300},
+		{name: "invalid date", mutate: func(in *ManualTransactionInput) { in.Date = "2024-03-15" }, wantErr: true},
+		{name: "invalid isin", mutate: func(in *ManualTransactionInput) { in.ISIN = "US037833100X" }, wantErr: true},
+		{name: "missing currency", mutate: func(in *ManualTransactionInput) { in.Currency = "" }, wantErr: true},
+		{name: "unsupported type", mutate: func(in *ManualTransactionInput) { in.TransactionType = "SWAP" }, wantErr: true},
+		{name: "buy/sell not allowed for type", mutate: func(in *ManualTransactionInput) { in.TransactionType = "DIVIDEND" }, wantErr: true},
+		{name: "explicit instrument class on a stock", mutate: func(in *ManualTransactionInput) { in.InstrumentClass = "etf" }, wantAmount: -1500},
+		{name: "unknown instrument class", mutate: func(in *ManualTransactionInput) { in.InstrumentClass = "CFD" }, wantErr: true},
+		{name: "instrument class on an option", mutate: func(in *ManualTransactionInput) { in.TransactionType = "OPTION"; in.InstrumentClass = "ETF" }, wantErr: true},
+		{name: "negative quantity", mutate: func(in *ManualTransactionInput) { in.Quantity = -1 }, wantErr: true},
+		{name: "trade without quantity", mutate: func(in *ManualTransactionInput) { in.Quantity = 0 }, wantErr: true},
+		{name: "formula in product name", mutate: func(in *ManualTransactionInput) { in.ProductName = "=HYPERLINK(\"x\")" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.mutate(&input)
+			canonical, err := buildManualCanonicalTransaction(input)
+			if tt.wantErr {
+				if !errors.Is(err, validation.ErrValidationFailed) {
+					t.Fatalf("error = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if canonical.Amount != tt.wantAmount {
+				t.Errorf("Amount = %v, want %v", canonical.Amount, tt.wantAmount)
+			}
+			if canonical.Source != ManualTransactionSource || canonical.Currency != "USD" {
+				t.Errorf("unexpected canonical transaction: %+v", canonical)
+			}
+		})
+	}
+}
+
+// stubUploadService satisfies UploadService for services that only need cache invalidation.
+type stubUploadService struct {
+	UploadService
+}
+
+func (stubUploadService) InvalidateUserCache(userID int64) {}
+
+func TestDeleteTransaction(t *testing.T) {
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+
+	insert := func(userID int64, source, hash string) int64 {
+		tx := models.ProcessedTransaction{Date: "15-03-2024", Source: source, TransactionType: "CASH", Currency: "EUR", HashId: hash}
+		result, err := database.DB.Exec(insertProcessedTransactionQuery, processedTransactionInsertArgs(userID, tx)...)
+		if err != nil {
+			t.Fatalf("inserting %s transaction: %v", source, err)
+		}
+		id, _ := result.LastInsertId()
+		return id
+	}
+	manualID := insert(1, ManualTransactionSource, "manual")
+	importedID := insert(1, "degiro", "imported")
+	otherUsersID := insert(2, ManualTransactionSource, "other")
+
+	tests := []struct {
+		name          string
+		transactionID int64
+		wantErr       error
+	}{
+		{name: "manual row is deleted", transactionID: manualID},
+		{name: "imported row is deleted", transactionID: importedID},
+		{name: "another user's row is not found", transactionID: otherUsersID, wantErr: ErrTransactionNotFound},
+		{name: "deleted row is not found", transactionID: manualID, wantErr: ErrTransactionNotFound},
+	}
+
+	service := NewTransactionService(nil, stubUploadService{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.DeleteTransaction(1, tt.transactionID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteTransaction error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
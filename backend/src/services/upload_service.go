@@ -2,6 +2,7 @@
 package services
 
 import (
+	"database/sql"
 	"fmt"
 	"io"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/username/taxfolio/backend/src/models"
 	"github.com/username/taxfolio/backend/src/parsers"
 	"github.com/username/taxfolio/backend/src/processors"
+	"github.com/username/taxfolio/backend/src/utils"
 )
 
 const (
@@ -23,16 +25,50 @@ const (
 	ckStockHoldings        = "stock_holdings_user_%d"
 	ckOptionHoldings       = "option_holdings_user_%d"
 	ckDividendTxns         = "dividend_txns_user_%d"
+	ckFallbackRateTxns     = "fallback_rate_txns_user_%d"
+	ckFXGains              = "fx_gains_user_%d"
+	ckLotMatchingErrors    = "lot_matching_errors_user_%d"
+	ckCryptoGains          = "crypto_gains_user_%d"
+	ckBondReport           = "bond_report_user_%d"
+	ckInterestSummary      = "interest_summary_user_%d"
+	ckCashLedger           = "cash_ledger_user_%d"
 	DefaultCacheExpiration = 15 * time.Minute
 	CacheCleanupInterval   = 30 * time.Minute
 )
 
+const insertProcessedTransactionQuery = `
+        INSERT INTO processed_transactions
+        (user_id, date, source, product_name, isin, quantity, original_quantity, price,
+         transaction_type, transaction_subtype, buy_sell, description, amount, currency, commission, commission_currency,
+         order_id, exchange_rate, amount_eur, exchange_rate_fallback, country_code, instrument_class,
+         underlying, strike, expiry, option_right, multiplier, input_string, hash_id)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// processedTransactionInsertArgs returns the arguments for insertProcessedTransactionQuery, in column order.
+func processedTransactionInsertArgs(userID int64, tx models.ProcessedTransaction) []interface{} {
+	return []interface{}{
+		userID, tx.Date, tx.Source, tx.ProductName, tx.ISIN, tx.Quantity, tx.OriginalQuantity, tx.Price,
+		tx.TransactionType, tx.TransactionSubType, tx.BuySell, tx.Description, tx.Amount, tx.Currency,
+		tx.Commission, tx.CommissionCurrency, tx.OrderID, tx.ExchangeRate, tx.AmountEUR, tx.RateFallback, tx.CountryCode, tx.InstrumentClass,
+		tx.Underlying, tx.Strike, tx.Expiry, tx.OptionRight, tx.Multiplier, tx.InputString, tx.HashId,
+	}
+}
+
+func isUniqueConstraintError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "unique constraint failed")
+}
+
 type uploadServiceImpl struct {
 	transactionProcessor  *processors.TransactionProcessor
 	dividendProcessor     processors.DividendProcessor
 	stockProcessor        processors.StockProcessor
 	optionProcessor       processors.OptionProcessor
 	cashMovementProcessor processors.CashMovementProcessor
+	fxProcessor           processors.FXProcessor
+	cryptoProcessor       processors.CryptoProcessor
+	bondProcessor         processors.BondProcessor
+	interestProcessor     processors.InterestProcessor
+	cashLedgerProcessor   processors.CashLedgerProcessor
 	reportCache           *cache.Cache
 }
 
@@ -42,6 +78,11 @@ func NewUploadService(
 	stockProcessor processors.StockProcessor,
 	optionProcessor processors.OptionProcessor,
 	cashMovementProcessor processors.CashMovementProcessor,
+	fxProcessor processors.FXProcessor,
+	cryptoProcessor processors.CryptoProcessor,
+	bondProcessor processors.BondProcessor,
+	interestProcessor processors.InterestProcessor,
+	cashLedgerProcessor processors.CashLedgerProcessor,
 	reportCache *cache.Cache,
 ) UploadService {
 	return &uploadServiceImpl{
@@ -50,16 +91,21 @@ func NewUploadService(
 		stockProcessor:        stockProcessor,
 		optionProcessor:       optionProcessor,
 		cashMovementProcessor: cashMovementProcessor,
+		fxProcessor:           fxProcessor,
+		cryptoProcessor:       cryptoProcessor,
+		bondProcessor:         bondProcessor,
+		interestProcessor:     interestProcessor,
+		cashLedgerProcessor:   cashLedgerProcessor,
 		reportCache:           reportCache,
 	}
 }
 
-func (s *uploadServiceImpl) ProcessUpload(fileReader io.Reader, userID int64, source string) (*UploadResult, error) {
+func (s *uploadServiceImpl) ProcessUpload(fileReader io.Reader, userID int64, source string, opts parsers.Options) (*UploadResult, error) {
 	overallStartTime := time.Now()
-	logger.L.Info("ProcessUpload START", "userID", userID, "source", source)
+	logger.L.Info("ProcessUpload START", "userID", userID, "source", source, "includeFX", opts.IncludeFXConversions)
 
 	// Step 1: Get the appropriate parser from the factory
-	parser, err := parsers.GetParser(source)
+	parser, err := parsers.GetParser(source, opts)
 	if err != nil {
 		logger.L.Error("Failed to get parser for source", "source", source, "error", err)
 		return nil, fmt.Errorf("%w: %v", ErrParsingFailed, err)
@@ -90,12 +136,7 @@ func (s *uploadServiceImpl) ProcessUpload(fileReader io.Reader, userID int64, so
 		}
 	}()
 
-	stmt, err := dbTx.Prepare(`
-        INSERT INTO processed_transactions
-        (user_id, date, source, product_name, isin, quantity, original_quantity, price,
-         transaction_type, transaction_subtype, buy_sell, description, amount, currency, commission, order_id,
-         exchange_rate, amount_eur, country_code, input_string, hash_id)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	stmt, err := dbTx.Prepare(insertProcessedTransactionQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing insert statement: %w", err)
 	}
@@ -103,13 +144,10 @@ func (s *uploadServiceImpl) ProcessUpload(fileReader io.Reader, userID int64, so
 
 	var duplicatesSkipped int
 	for _, tx := range processedTransactions {
-		_, err := stmt.Exec(
-			userID, tx.Date, tx.Source, tx.ProductName, tx.ISIN, tx.Quantity, tx.OriginalQuantity, tx.Price,
-			tx.TransactionType, tx.TransactionSubType, tx.BuySell, tx.Description, tx.Amount, tx.Currency,
-			tx.Commission, tx.OrderID, tx.ExchangeRate, tx.AmountEUR, tx.CountryCode, tx.InputString, tx.HashId)
+		_, err := stmt.Exec(processedTransactionInsertArgs(userID, tx)...)
 		if err != nil {
 			// Check if the error is a UNIQUE constraint violation
-			if strings.Contains(strings.ToLower(err.Error()), "unique constraint failed") {
+			if isUniqueConstraintError(err) {
 				duplicatesSkipped++
 				logger.L.Debug("Skipping duplicate transaction", "userID", userID, "hash_id", tx.HashId, "orderID", tx.OrderID)
 				continue // Ignore error and continue to the next transaction
@@ -132,7 +170,7 @@ func (s *uploadServiceImpl) ProcessUpload(fileReader io.Reader, userID int64, so
 		return nil, err
 	}
 
-	stockSaleDetails, stockHoldingsByYear := s.stockProcessor.Process(allUserTransactions)
+	stockSaleDetails, stockHoldingsByYear, lotMatchingErrors := s.stockProcessor.Process(allUserTransactions)
 	optionSaleDetails, optionHoldings := s.optionProcessor.Process(allUserTransactions)
 	cashMovements := s.cashMovementProcessor.Process(allUserTransactions)
 
@@ -150,6 +188,10 @@ func (s *uploadServiceImpl) ProcessUpload(fileReader io.Reader, userID int64, so
 		OptionHoldings:           optionHoldings,
 		CashMovements:            cashMovements,
 		DividendTransactionsList: dividendTransactionsList,
+		LotMatchingErrors:        lotMatchingErrors,
+	}
+	if len(lotMatchingErrors) > 0 {
+		logger.L.Warn("Stock lot matching left unmatched quantities", "userID", userID, "errorCount", len(lotMatchingErrors))
 	}
 
 	logger.L.Info("ProcessUpload END", "userID", userID, "duration", time.Since(overallStartTime))
@@ -165,6 +207,13 @@ func (s *uploadServiceImpl) InvalidateUserCache(userID int64) {
 		fmt.Sprintf(ckStockHoldings, userID),
 		fmt.Sprintf(ckOptionHoldings, userID),
 		fmt.Sprintf(ckDividendTxns, userID),
+		fmt.Sprintf(ckFallbackRateTxns, userID),
+		fmt.Sprintf(ckFXGains, userID),
+		fmt.Sprintf(ckLotMatchingErrors, userID),
+		fmt.Sprintf(ckCryptoGains, userID),
+		fmt.Sprintf(ckBondReport, userID),
+		fmt.Sprintf(ckInterestSummary, userID),
+		fmt.Sprintf(ckCashLedger, userID),
 	}
 	for _, key := range keysToDelete {
 		s.reportCache.Delete(key)
@@ -199,6 +248,7 @@ func (s *uploadServiceImpl) GetLatestUploadResult(userID int64) (*UploadResult,
 			OptionHoldings:           []models.OptionHolding{},
 			CashMovements:            []models.CashMovement{},
 			DividendTransactionsList: []models.ProcessedTransaction{},
+			LotMatchingErrors:        []models.LotMatchingError{},
 		}
 		s.reportCache.Set(cacheKey, emptyResult, DefaultCacheExpiration)
 		return emptyResult, nil
@@ -206,7 +256,7 @@ func (s *uploadServiceImpl) GetLatestUploadResult(userID int64) (*UploadResult,
 
 	logger.L.Info("Processing all fetched transactions for GetLatestUploadResult", "userID", userID, "transactionCount", len(userTransactions))
 	processingStartTime := time.Now()
-	stockSaleDetails, stockHoldings := s.stockProcessor.Process(userTransactions)
+	stockSaleDetails, stockHoldings, lotMatchingErrors := s.stockProcessor.Process(userTransactions)
 	optionSaleDetails, optionHoldings := s.optionProcessor.Process(userTransactions)
 	cashMovements := s.cashMovementProcessor.Process(userTransactions)
 
@@ -228,6 +278,7 @@ func (s *uploadServiceImpl) GetLatestUploadResult(userID int64) (*UploadResult,
 		OptionHoldings:           optionHoldings,
 		CashMovements:            cashMovements,
 		DividendTransactionsList: dividendTransactionsList,
+		LotMatchingErrors:        lotMatchingErrors,
 	}
 
 	s.reportCache.Set(cacheKey, uploadResult, DefaultCacheExpiration)
@@ -253,6 +304,24 @@ func (s *uploadServiceImpl) GetDividendTaxSummary(userID int64) (models.Dividend
 	return summary, nil
 }
 
+func (s *uploadServiceImpl) GetInterestTaxSummary(userID int64) (models.InterestTaxResult, error) {
+	cacheKey := fmt.Sprintf(ckInterestSummary, userID)
+	if data, found := s.reportCache.Get(cacheKey); found {
+		if summary, ok := data.(models.InterestTaxResult); ok {
+			logger.L.Info("Cache hit for GetInterestTaxSummary", "userID", userID)
+			return summary, nil
+		}
+	}
+	logger.L.Info("Cache miss for GetInterestTaxSummary, computing...", "userID", userID)
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	summary := s.interestProcessor.CalculateTaxSummary(userTransactions)
+	s.reportCache.Set(cacheKey, summary, DefaultCacheExpiration)
+	return summary, nil
+}
+
 func (s *uploadServiceImpl) GetStockSaleDetails(userID int64) ([]models.SaleDetail, error) {
 	cacheKey := fmt.Sprintf(ckStockSales, userID)
 	if cachedData, found := s.reportCache.Get(cacheKey); found {
@@ -266,11 +335,38 @@ func (s *uploadServiceImpl) GetStockSaleDetails(userID int64) ([]models.SaleDeta
 	if err != nil {
 		return nil, err
 	}
-	stockSaleDetails, _ := s.stockProcessor.Process(userTransactions)
+	stockSaleDetails, _, _ := s.stockProcessor.Process(userTransactions)
 	s.reportCache.Set(cacheKey, stockSaleDetails, DefaultCacheExpiration)
 	return stockSaleDetails, nil
 }
 
+// GetStockSalesByClass totals the user's stock sales by year and instrument class.
+func (s *uploadServiceImpl) GetStockSalesByClass(userID int64) (map[string]map[string]models.InstrumentClassSaleSummary, error) {
+	stockSaleDetails, err := s.GetStockSaleDetails(userID)
+	if err != nil {
+		return nil, err
+	}
+	return processors.SummarizeSalesByInstrumentClass(stockSaleDetails), nil
+}
+
+func (s *uploadServiceImpl) GetLotMatchingErrors(userID int64) ([]models.LotMatchingError, error) {
+	cacheKey := fmt.Sprintf(ckLotMatchingErrors, userID)
+	if cachedData, found := s.reportCache.Get(cacheKey); found {
+		if matchingErrors, ok := cachedData.([]models.LotMatchingError); ok {
+			logger.L.Info("Cache hit for GetLotMatchingErrors", "userID", userID)
+			return matchingErrors, nil
+		}
+	}
+	logger.L.Info("Cache miss for GetLotMatchingErrors, computing...", "userID", userID)
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	_, _, matchingErrors := s.stockProcessor.Process(userTransactions)
+	s.reportCache.Set(cacheKey, matchingErrors, DefaultCacheExpiration)
+	return matchingErrors, nil
+}
+
 func (s *uploadServiceImpl) GetDividendTransactions(userID int64) ([]models.ProcessedTransaction, error) {
 	cacheKey := fmt.Sprintf(ckDividendTxns, userID)
 	if data, found := s.reportCache.Get(cacheKey); found {
@@ -294,6 +390,18 @@ func (s *uploadServiceImpl) GetDividendTransactions(userID int64) ([]models.Proc
 	return dividends, nil
 }
 
+// GetDividendAnalytics returns the dividend analytics and projected income calendar as of asOf, measuring yield
+// on cost against the lots open at that date.
+func (s *uploadServiceImpl) GetDividendAnalytics(userID int64, asOf time.Time) (*models.DividendAnalytics, error) {
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	holdings := s.stockProcessor.HoldingsAt(userTransactions, asOf)
+	analytics := s.dividendProcessor.Analyze(userTransactions, holdings, asOf)
+	return &analytics, nil
+}
+
 func (s *uploadServiceImpl) GetStockHoldings(userID int64) ([]models.PurchaseLot, error) {
 	cacheKey := fmt.Sprintf(ckStockHoldings, userID)
 	if data, found := s.reportCache.Get(cacheKey); found {
@@ -307,19 +415,24 @@ func (s *uploadServiceImpl) GetStockHoldings(userID int64) ([]models.PurchaseLot
 	if err != nil {
 		return nil, err
 	}
-	_, stockHoldingsByYear := s.stockProcessor.Process(userTransactions)
+	_, stockHoldingsByYear, _ := s.stockProcessor.Process(userTransactions)
+	currentHoldings := latestHoldings(stockHoldingsByYear)
+	if currentHoldings == nil {
+		currentHoldings = []models.PurchaseLot{}
+	}
+	s.reportCache.Set(cacheKey, currentHoldings, DefaultCacheExpiration)
+	return currentHoldings, nil
+}
+
+// latestHoldings picks the most recent yearly snapshot produced by the stock processor.
+func latestHoldings(holdingsByYear map[string][]models.PurchaseLot) []models.PurchaseLot {
 	latestYear := ""
-	for year := range stockHoldingsByYear {
+	for year := range holdingsByYear {
 		if latestYear == "" || year > latestYear {
 			latestYear = year
 		}
 	}
-	latestHoldings := stockHoldingsByYear[latestYear]
-	if latestHoldings == nil {
-		latestHoldings = []models.PurchaseLot{}
-	}
-	s.reportCache.Set(cacheKey, latestHoldings, DefaultCacheExpiration)
-	return latestHoldings, nil
+	return holdingsByYear[latestYear]
 }
 
 func (s *uploadServiceImpl) GetOptionHoldings(userID int64) ([]models.OptionHolding, error) {
@@ -340,6 +453,24 @@ func (s *uploadServiceImpl) GetOptionHoldings(userID int64) ([]models.OptionHold
 	return optionHoldings, nil
 }
 
+// GetStockHoldingsAt returns the lots open at the end of asOf. Snapshots for arbitrary dates are not cached.
+func (s *uploadServiceImpl) GetStockHoldingsAt(userID int64, asOf time.Time) ([]models.PurchaseLot, error) {
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.stockProcessor.HoldingsAt(userTransactions, asOf), nil
+}
+
+// GetOptionHoldingsAt returns the option positions open at the end of asOf.
+func (s *uploadServiceImpl) GetOptionHoldingsAt(userID int64, asOf time.Time) ([]models.OptionHolding, error) {
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.optionProcessor.HoldingsAt(userTransactions, asOf), nil
+}
+
 func (s *uploadServiceImpl) GetOptionSaleDetails(userID int64) ([]models.OptionSaleDetail, error) {
 	cacheKey := fmt.Sprintf(ckOptionSales, userID)
 	if data, found := s.reportCache.Get(cacheKey); found {
@@ -358,12 +489,272 @@ func (s *uploadServiceImpl) GetOptionSaleDetails(userID int64) ([]models.OptionS
 	return optionSaleDetails, nil
 }
 
+func (s *uploadServiceImpl) GetFXGainReport(userID int64) (*models.FXGainReport, error) {
+	cacheKey := fmt.Sprintf(ckFXGains, userID)
+	if data, found := s.reportCache.Get(cacheKey); found {
+		if report, ok := data.(*models.FXGainReport); ok {
+			logger.L.Info("Cache hit for GetFXGainReport", "userID", userID)
+			return report, nil
+		}
+	}
+	logger.L.Info("Cache miss for GetFXGainReport, computing...", "userID", userID)
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	report := s.fxProcessor.Process(userTransactions)
+	s.reportCache.Set(cacheKey, &report, DefaultCacheExpiration)
+	return &report, nil
+}
+
+func (s *uploadServiceImpl) GetCryptoGainReport(userID int64) (*models.CryptoGainReport, error) {
+	cacheKey := fmt.Sprintf(ckCryptoGains, userID)
+	if data, found := s.reportCache.Get(cacheKey); found {
+		if report, ok := data.(*models.CryptoGainReport); ok {
+			logger.L.Info("Cache hit for GetCryptoGainReport", "userID", userID)
+			return report, nil
+		}
+	}
+	logger.L.Info("Cache miss for GetCryptoGainReport, computing...", "userID", userID)
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	report := s.cryptoProcessor.Process(userTransactions)
+	s.reportCache.Set(cacheKey, &report, DefaultCacheExpiration)
+	return &report, nil
+}
+
+func (s *uploadServiceImpl) GetBondReport(userID int64) (*models.BondReport, error) {
+	cacheKey := fmt.Sprintf(ckBondReport, userID)
+	if data, found := s.reportCache.Get(cacheKey); found {
+		if report, ok := data.(*models.BondReport); ok {
+			logger.L.Info("Cache hit for GetBondReport", "userID", userID)
+			return report, nil
+		}
+	}
+	logger.L.Info("Cache miss for GetBondReport, computing...", "userID", userID)
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	report := s.bondProcessor.Process(userTransactions)
+	s.reportCache.Set(cacheKey, &report, DefaultCacheExpiration)
+	return &report, nil
+}
+
+// GetCashLedger returns the running cash balance of each broker account per currency.
+func (s *uploadServiceImpl) GetCashLedger(userID int64) (*models.CashLedgerReport, error) {
+	cacheKey := fmt.Sprintf(ckCashLedger, userID)
+	if data, found := s.reportCache.Get(cacheKey); found {
+		if report, ok := data.(*models.CashLedgerReport); ok {
+			logger.L.Info("Cache hit for GetCashLedger", "userID", userID)
+			return report, nil
+		}
+	}
+	logger.L.Info("Cache miss for GetCashLedger, computing...", "userID", userID)
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	report := s.cashLedgerProcessor.Process(userTransactions)
+	s.reportCache.Set(cacheKey, &report, DefaultCacheExpiration)
+	return &report, nil
+}
+
+// GetBondIncomeSummary returns the per-year bond interest income and capital gain, taken from the bond report.
+func (s *uploadServiceImpl) GetBondIncomeSummary(userID int64) (map[string]models.BondYearSummary, error) {
+	report, err := s.GetBondReport(userID)
+	if err != nil {
+		return nil, err
+	}
+	return report.Summary, nil
+}
+
+func (s *uploadServiceImpl) GetFallbackRateTransactions(userID int64) ([]models.ProcessedTransaction, error) {
+	cacheKey := fmt.Sprintf(ckFallbackRateTxns, userID)
+	if data, found := s.reportCache.Get(cacheKey); found {
+		if txns, ok := data.([]models.ProcessedTransaction); ok {
+			logger.L.Info("Cache hit for GetFallbackRateTransactions", "userID", userID)
+			return txns, nil
+		}
+	}
+	logger.L.Info("Cache miss for GetFallbackRateTransactions, computing...", "userID", userID)
+	userTransactions, err := fetchUserProcessedTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	var fallbackTxns []models.ProcessedTransaction
+	for _, tx := range userTransactions {
+		if tx.RateFallback {
+			fallbackTxns = append(fallbackTxns, tx)
+		}
+	}
+	s.reportCache.Set(cacheKey, fallbackTxns, DefaultCacheExpiration)
+	return fallbackTxns, nil
+}
+
+// BackfillInstrumentClasses classifies the stored transactions that predate instrument classification, whose
+// instrument_class is NULL, from the loaded reference data. Caches of affected users are invalidated.
+func (s *uploadServiceImpl) BackfillInstrumentClasses() (int, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, transaction_type, isin, product_name
+		FROM processed_transactions
+		WHERE instrument_class IS NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("error querying unclassified transactions: %w", err)
+	}
+
+	type unclassifiedRow struct {
+		id, userID                int64
+		txType, isin, productName string
+	}
+	var candidates []unclassifiedRow
+	for rows.Next() {
+		var row unclassifiedRow
+		var isin sql.NullString
+		if err := rows.Scan(&row.id, &row.userID, &row.txType, &isin, &row.productName); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning unclassified transaction: %w", err)
+		}
+		row.isin = isin.String
+		candidates = append(candidates, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating unclassified transactions: %w", err)
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	dbTx, err := database.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error beginning database transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			dbTx.Rollback()
+		}
+	}()
+
+	stmt, err := dbTx.Prepare("UPDATE processed_transactions SET instrument_class = ? WHERE id = ?")
+	if err != nil {
+		return 0, fmt.Errorf("error preparing update statement: %w", err)
+	}
+	defer stmt.Close()
+
+	affectedUsers := make(map[int64]bool)
+	for _, row := range candidates {
+		// Cash rows get "" rather than NULL, so they are not picked up again.
+		class := processors.ClassifyInstrument(row.txType, row.isin, row.productName, "")
+		if _, err := stmt.Exec(class, row.id); err != nil {
+			return 0, fmt.Errorf("error classifying transaction %d: %w", row.id, err)
+		}
+		affectedUsers[row.userID] = true
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing instrument classes: %w", err)
+	}
+	committed = true
+
+	for userID := range affectedUsers {
+		s.InvalidateUserCache(userID)
+	}
+	return len(candidates), nil
+}
+
+// ReenrichFallbackRates recomputes exchange_rate and amount_eur for every stored transaction
+// that was saved with the 1.0 fallback rate, using the currently loaded rate data.
+// Rows for which a rate is still unavailable keep their flag. Caches of affected users are invalidated.
+func (s *uploadServiceImpl) ReenrichFallbackRates() (int, error) {
+	startTime := time.Now()
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, date, currency, amount
+		FROM processed_transactions
+		WHERE exchange_rate_fallback = TRUE`)
+	if err != nil {
+		return 0, fmt.Errorf("error querying fallback-rate transactions: %w", err)
+	}
+
+	type fallbackRow struct {
+		id, userID int64
+		date       string
+		currency   string
+		amount     float64
+	}
+	var candidates []fallbackRow
+	for rows.Next() {
+		var row fallbackRow
+		if err := rows.Scan(&row.id, &row.userID, &row.date, &row.currency, &row.amount); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning fallback-rate transaction: %w", err)
+		}
+		candidates = append(candidates, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating fallback-rate transactions: %w", err)
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	dbTx, err := database.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error beginning database transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			dbTx.Rollback()
+		}
+	}()
+
+	stmt, err := dbTx.Prepare(`
+		UPDATE processed_transactions
+		SET exchange_rate = ?, amount_eur = ?, exchange_rate_fallback = FALSE
+		WHERE id = ?`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing update statement: %w", err)
+	}
+	defer stmt.Close()
+
+	affectedUsers := make(map[int64]bool)
+	var updated int
+	for _, row := range candidates {
+		rate, err := processors.GetExchangeRate(row.currency, utils.ParseDate(row.date))
+		if err != nil || rate <= 0 {
+			continue // Still no rate available; leave the row flagged.
+		}
+		if _, err := stmt.Exec(rate, row.amount/rate, row.id); err != nil {
+			return 0, fmt.Errorf("error updating exchange rate for transaction %d: %w", row.id, err)
+		}
+		affectedUsers[row.userID] = true
+		updated++
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing re-enriched transactions: %w", err)
+	}
+	committed = true
+
+	for userID := range affectedUsers {
+		s.InvalidateUserCache(userID)
+	}
+	logger.L.Info("Re-enriched fallback exchange rates", "candidates", len(candidates), "updated", updated, "users", len(affectedUsers), "duration", time.Since(startTime))
+	return updated, nil
+}
+
 func fetchUserProcessedTransactions(userID int64) ([]models.ProcessedTransaction, error) {
 	logger.L.Debug("Fetching processed transactions from DB", "userID", userID)
 	rows, err := database.DB.Query(`
 		SELECT id, date, source, product_name, isin, quantity, original_quantity, price, 
-		       transaction_type, transaction_subtype, buy_sell, description, amount, currency, commission, 
-		       order_id, exchange_rate, amount_eur, country_code, input_string, hash_id
+		       transaction_type, transaction_subtype, buy_sell, description, amount, currency, commission, commission_currency,
+		       order_id, exchange_rate, amount_eur, exchange_rate_fallback, country_code, instrument_class,
+		       underlying, strike, expiry, option_right, multiplier, input_string, hash_id
 		FROM processed_transactions
 		WHERE user_id = ?
 		ORDER BY date ASC, id ASC`, userID)
@@ -377,14 +768,17 @@ func fetchUserProcessedTransactions(userID int64) ([]models.ProcessedTransaction
 	var transactions []models.ProcessedTransaction
 	for rows.Next() {
 		var tx models.ProcessedTransaction
+		var instrumentClass sql.NullString
 		scanErr := rows.Scan(
 			&tx.ID, &tx.Date, &tx.Source, &tx.ProductName, &tx.ISIN, &tx.Quantity, &tx.OriginalQuantity, &tx.Price,
 			&tx.TransactionType, &tx.TransactionSubType, &tx.BuySell, &tx.Description, &tx.Amount, &tx.Currency,
-			&tx.Commission, &tx.OrderID, &tx.ExchangeRate, &tx.AmountEUR, &tx.CountryCode, &tx.InputString, &tx.HashId)
+			&tx.Commission, &tx.CommissionCurrency, &tx.OrderID, &tx.ExchangeRate, &tx.AmountEUR, &tx.RateFallback, &tx.CountryCode, &instrumentClass,
+			&tx.Underlying, &tx.Strike, &tx.Expiry, &tx.OptionRight, &tx.Multiplier, &tx.InputString, &tx.HashId)
 		if scanErr != nil {
 			logger.L.Error("Error scanning transaction row from DB", "userID", userID, "error", scanErr)
 			return nil, fmt.Errorf("error scanning transaction row for userID %d: %w", userID, scanErr)
 		}
+		tx.InstrumentClass = instrumentClass.String
 		transactions = append(transactions, tx)
 	}
 	if err = rows.Err(); err != nil {
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/username/taxfolio/backend/src/database"
+	"github.com/username/taxfolio/backend/src/models"
+	"github.com/username/taxfolio/backend/src/processors"
+)
+
+func TestBackfillInstrumentClasses(t *testing.T) {
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+
+	tests := []struct {
+		name      string
+		tx        models.ProcessedTransaction
+		stored    sql.NullString
+		wantClass string
+	}{
+		{
+			name:      "unclassified stock gets a class",
+			tx:        models.ProcessedTransaction{TransactionType: "STOCK", ProductName: "ACME CORP", HashId: "stock"},
+			wantClass: models.InstrumentClassShare,
+		},
+		{
+			name:      "unclassified option is classed by type",
+			tx:        models.ProcessedTransaction{TransactionType: "OPTION", ProductName: "ACME 100C", HashId: "option"},
+			wantClass: models.InstrumentClassOption,
+		},
+		{
+			name: "unclassified cash row is marked as having no instrument",
+			tx:   models.ProcessedTransaction{TransactionType: "CASH", HashId: "cash"},
+		},
+		{
+			name:      "existing class is kept",
+			tx:        models.ProcessedTransaction{TransactionType: "STOCK", ProductName: "ACME CORP", HashId: "manual"},
+			stored:    sql.NullString{String: models.InstrumentClassETF, Valid: true},
+			wantClass: models.InstrumentClassETF,
+		},
+	}
+
+	ids := make([]int64, len(tests))
+	for i, tt := range tests {
+		tt.tx.Date, tt.tx.Source, tt.tx.Currency = "15-03-2024", "degiro", "EUR"
+		result, err := database.DB.Exec(insertProcessedTransactionQuery, processedTransactionInsertArgs(1, tt.tx)...)
+		if err != nil {
+			t.Fatalf("inserting %s: %v", tt.name, err)
+		}
+		ids[i], _ = result.LastInsertId()
+		if _, err := database.DB.Exec("UPDATE processed_transactions SET instrument_class = ? WHERE id = ?", tt.stored, ids[i]); err != nil {
+			t.Fatalf("resetting class of %s: %v", tt.name, err)
+		}
+	}
+
+	service := &uploadServiceImpl{reportCache: cache.New(DefaultCacheExpiration, CacheCleanupInterval)}
+	classified, err := service.BackfillInstrumentClasses()
+	if err != nil {
+		t.Fatalf("BackfillInstrumentClasses: %v", err)
+	}
+	if classified != 3 {
+		t.Errorf("classified %d transactions, want 3", classified)
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var class sql.NullString
+			if err := database.DB.QueryRow("SELECT instrument_class FROM processed_transactions WHERE id = ?", ids[i]).Scan(&class); err != nil {
+				t.Fatalf("reading class: %v", err)
+			}
+			if !class.Valid || class.String != tt.wantClass {
+				t.Errorf("instrument_class = %+v, want %q", class, tt.wantClass)
+			}
+		})
+	}
+
+	if classified, err := service.BackfillInstrumentClasses(); err != nil || classified != 0 {
+		t.Errorf("second backfill classified %d (error %v), want 0", classified, err)
+	}
+}
+
+func TestReenrichFallbackRates(t *testing.T) {
+	if err := processors.LoadHistoricalRates("../../data/historicalExchangeRate.json"); err != nil {
+		t.Fatalf("loading exchange rates: %v", err)
+	}
+	database.InitDB(t.TempDir() + "/taxfolio.db")
+	t.Cleanup(func() { database.DB.Close() })
+
+	usdRate, err := processors.GetExchangeRate("USD", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("USD rate: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		tx           models.ProcessedTransaction
+		wantRate     float64
+		wantEUR      float64
+		wantFallback bool
+	}{
+		{
+			name:     "fallback row gets the loaded rate",
+			tx:       models.ProcessedTransaction{Currency: "USD", Amount: 100, ExchangeRate: 1, AmountEUR: 100, RateFallback: true, HashId: "usd"},
+			wantRate: usdRate,
+			wantEUR:  100 / usdRate,
+		},
+		{
+			name:         "currency without rate data stays flagged",
+			tx:           models.ProcessedTransaction{Currency: "XYZ", Amount: 100, ExchangeRate: 1, AmountEUR: 100, RateFallback: true, HashId: "xyz"},
+			wantRate:     1,
+			wantEUR:      100,
+			wantFallback: true,
+		},
+		{
+			name:     "row with a real rate is left alone",
+			tx:       models.ProcessedTransaction{Currency: "USD", Amount: 110, ExchangeRate: 1.1, AmountEUR: 100, HashId: "rated"},
+			wantRate: 1.1,
+			wantEUR:  100,
+		},
+	}
+
+	ids := make([]int64, len(tests))
+	for i, tt := range tests {
+		tt.tx.Date, tt.tx.Source, tt.tx.TransactionType = "15-03-2024", "ibkr", "DIVIDEND"
+		result, err := database.DB.Exec(insertProcessedTransactionQuery, processedTransactionInsertArgs(1, tt.tx)...)
+		if err != nil {
+			t.Fatalf("inserting %s: %v", tt.name, err)
+		}
+		ids[i], _ = result.LastInsertId()
+	}
+
+	service := &uploadServiceImpl{reportCache: cache.New(DefaultCacheExpiration, CacheCleanupInterval)}
+	updated, err := service.ReenrichFallbackRates()
+	if err != nil {
+		t.Fatalf("ReenrichFallbackRates: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("updated %d transactions, want 1", updated)
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rate, amountEUR float64
+			var fallback bool
+			if err := database.DB.QueryRow("SELECT exchange_rate, amount_eur, exchange_rate_fallback FROM processed_transactions WHERE id = ?", ids[i]).Scan(&rate, &amountEUR, &fallback); err != nil {
+				t.Fatalf("reading transaction: %v", err)
+			}
+			if rate != tt.wantRate || math.Abs(amountEUR-tt.wantEUR) > 1e-9 || fallback != tt.wantFallback {
+				t.Errorf("rate %v amount_eur %v fallback %v, want %v %v %v", rate, amountEUR, fallback, tt.wantRate, tt.wantEUR, tt.wantFallback)
+			}
+		})
+	}
+}
@@ -10,6 +10,10 @@ import (
 	"github.com/username/taxfolio/backend/src/logger" // Use new logger
 )
 
+// UnknownCountry is the country code parsers assign to rows of brokers that report no ISIN, so reports list them
+// apart for the user to check instead of attributing them to a guessed country or dropping them.
+const UnknownCountry = "UNKNOWN"
+
 type CountryInfo struct {
 	Country string `json:"country"`
 	Alpha2  string `json:"alpha2"`
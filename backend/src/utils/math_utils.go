@@ -1,6 +1,9 @@
 package utils
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
 // MinInt returns the smaller of two integers.
 func MinInt(a, b int) int {
@@ -23,3 +26,52 @@ func RoundFloat(val float64, precision uint) float64 {
 	ratio := math.Pow(10, float64(precision))
 	return math.Round(val*ratio) / ratio
 }
+
+// XIRR returns the annualised internal rate of return of irregular cash flows (negative for money paid in,
+// positive for money received), on a 365-day year. It reports false when the flows have no sign change
+// or no rate within range zeroes their net present value.
+func XIRR(amounts []float64, dates []time.Time) (float64, bool) {
+	if len(amounts) < 2 || len(amounts) != len(dates) {
+		return 0, false
+	}
+	hasIn, hasOut := false, false
+	for _, amount := range amounts {
+		hasIn = hasIn || amount < 0
+		hasOut = hasOut || amount > 0
+	}
+	if !hasIn || !hasOut {
+		return 0, false
+	}
+
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for i, amount := range amounts {
+			years := dates[i].Sub(dates[0]).Hours() / 24 / 365
+			total += amount / math.Pow(1+rate, years)
+		}
+		return total
+	}
+
+	// Bisection between a near-total loss and a rate high enough to change the sign of the NPV.
+	low, high := -0.9999, 1.0
+	npvLow := npv(low)
+	for npvLow*npv(high) > 0 {
+		high *= 10
+		if high > 1e6 {
+			return 0, false
+		}
+	}
+	for i := 0; i < 200 && high-low > 1e-10; i++ {
+		mid := (low + high) / 2
+		npvMid := npv(mid)
+		if npvMid == 0 {
+			return mid, true
+		}
+		if npvLow*npvMid < 0 {
+			high = mid
+		} else {
+			low, npvLow = mid, npvMid
+		}
+	}
+	return (low + high) / 2, true
+}
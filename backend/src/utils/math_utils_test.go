@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestXIRR(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		amounts []float64
+		dates   []time.Time
+		want    float64
+		wantOK  bool
+	}{
+		{name: "ten percent over a year", amounts: []float64{-1000, 1100}, dates: []time.Time{day(2023, 1, 1), day(2024, 1, 1)}, want: 0.1, wantOK: true},
+		{name: "half lost over a year", amounts: []float64{-1000, 500}, dates: []time.Time{day(2023, 1, 1), day(2024, 1, 1)}, want: -0.5, wantOK: true},
+		{
+			name:    "second deposit half way",
+			amounts: []float64{-1000, -1000, 2100},
+			dates:   []time.Time{day(2023, 1, 1), day(2023, 7, 2), day(2024, 1, 1)},
+			want:    0.067,
+			wantOK:  true,
+		},
+		{name: "no money received", amounts: []float64{-1000, -100}, dates: []time.Time{day(2023, 1, 1), day(2024, 1, 1)}},
+		{name: "single flow", amounts: []float64{-1000}, dates: []time.Time{day(2023, 1, 1)}},
+		{name: "mismatched dates", amounts: []float64{-1000, 1100}, dates: []time.Time{day(2023, 1, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := XIRR(tt.amounts, tt.dates)
+			if ok != tt.wantOK {
+				t.Fatalf("XIRR ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && math.Abs(got-tt.want) > 5e-4 {
+				t.Errorf("XIRR = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
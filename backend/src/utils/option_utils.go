@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOptionMultiplier is the number of shares per contract of standard equity options.
+const DefaultOptionMultiplier = 100
+
+// OptionContract is the structured identity of an option contract.
+type OptionContract struct {
+	Underlying string
+	Right      string // "CALL" or "PUT"
+	Strike     float64
+	Expiry     time.Time
+}
+
+// optionNameRe matches DeGiro-style option product names such as "FLW P31.00 18MAR22".
+var optionNameRe = regexp.MustCompile(`^\s*(\S+)\s+([CP])(\d+(?:[.,]\d+)?)\s+(\d{2}[A-Za-z]{3}\d{2})\s*$`)
+
+// ParseOptionProductName reads the underlying, right, strike and expiry from a product name in the
+// "<underlying> <C|P><strike> <DDMONYY>" form. It reports false for any other name.
+func ParseOptionProductName(name string) (OptionContract, bool) {
+	matches := optionNameRe.FindStringSubmatch(name)
+	if matches == nil {
+		return OptionContract{}, false
+	}
+	strike, err := strconv.ParseFloat(strings.ReplaceAll(matches[3], ",", "."), 64)
+	if err != nil {
+		return OptionContract{}, false
+	}
+	expiry, err := time.Parse("02Jan06", matches[4])
+	if err != nil {
+		return OptionContract{}, false
+	}
+	right := "CALL"
+	if matches[2] == "P" {
+		right = "PUT"
+	}
+	return OptionContract{
+		Underlying: strings.ToUpper(matches[1]),
+		Right:      right,
+		Strike:     strike,
+		Expiry:     expiry,
+	}, true
+}